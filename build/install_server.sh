@@ -8,7 +8,8 @@ while ! docker exec -it gfg_mysql mysql -uuser -ppassword -e "SELECT 1" &> /dev/
     sleep 1
 done
 
-docker exec -it gfg_mysql mysql -uuser -ppassword -e "$(cat $PWD/init_database.sql)"
+docker exec -it gfg_go go run ../gfgctl migrate up
+docker exec -it gfg_go go run ../gfgctl migrate seed
 
 docker-compose -f $PWD/docker-compose.yml stop
 
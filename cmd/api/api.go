@@ -1,13 +1,22 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"coding-challenge-go/pkg/api"
 	"coding-challenge-go/pkg/config"
+	"coding-challenge-go/pkg/migrate"
+	"coding-challenge-go/pkg/redact"
+	"coding-challenge-go/pkg/storage"
+	"coding-challenge-go/pkg/tracing"
 
-	_ "github.com/go-sql-driver/mysql"
 	"github.com/kelseyhightower/envconfig"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -15,6 +24,8 @@ import (
 
 func main() {
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnixMs
+	// logs of the code without request scoped logger in context go to the global logger.
+	zerolog.DefaultContextLogger = &log.Logger
 
 	var cfg config.ENVConfig
 	if err := envconfig.Process("", &cfg); err != nil {
@@ -22,22 +33,123 @@ func main() {
 		return
 	}
 
-	db, err := sql.Open("mysql", "user:password@tcp(db:3306)/product")
+	redactor, err := redact.New(redact.Mode(cfg.LogPIIRedaction), cfg.LogPIIHashKey, cfg.LogPIIDebug)
+	if err != nil {
+		log.Error().Err(err).Msg("Fail to create PII redactor")
+		return
+	}
+
+	// all the log lines, including the errors, are masked before written.
+	log.Logger = zerolog.New(redactor.Writer(os.Stderr)).With().Timestamp().Logger()
+
+	if cfg.LogPIIDebug {
+		log.Warn().Msg("PII redaction of logs is disabled by LOG_PII_DEBUG")
+	}
 
+	tp, err := tracing.NewProvider(context.Background(), cfg)
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to create server")
+		log.Error().Err(err).Msg("Fail to create tracer provider")
 		return
 	}
 
-	defer db.Close()
+	defer func() {
+		if err := tp.Shutdown(context.Background()); err != nil {
+			log.Error().Err(err).Msg("Fail to shutdown tracer provider")
+		}
+	}()
+
+	var db *sql.DB
+
+	// NOTE - the memory backend does not connect to a DB, see config.DBBackendMemory.
+	if cfg.DBBackend == config.DBBackendMemory {
+		log.Warn().Msg("The products and the sellers are stored in memory by DB_BACKEND, they are lost on exit")
+	} else {
+		db, err = openDB(cfg)
+
+		if err != nil {
+			log.Error().Err(err).Msg("Fail to create server")
+			return
+		}
 
-	engine, err := api.CreateAPIEngine(db, cfg)
+		defer db.Close()
+
+		if cfg.DBAutoMigrate {
+			if err := migrateDB(db); err != nil {
+				log.Error().Err(err).Msg("Fail to migrate DB")
+				return
+			}
+		}
+	}
+
+	engine, shutdown, err := api.CreateAPIEngine(db, cfg)
 
 	if err != nil {
 		log.Error().Err(err).Msg("Fail to create server")
 		return
 	}
 
-	log.Info().Msg("Start server")
-	log.Fatal().Err(engine.Run(os.Getenv("LISTEN"))).Msg("Fail to listen and serve")
+	serve(&http.Server{Addr: os.Getenv("LISTEN"), Handler: engine}, shutdown, cfg.ShutdownTimeout)
+}
+
+// serve serves the requests until SIGINT or SIGTERM, then it waits for the requests in flight
+// and the work of the engine in the background, e.g. the imports, for the timeout at most.
+func serve(srv *http.Server, shutdown func(ctx context.Context) error, timeout time.Duration) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := make(chan error, 1)
+
+	go func() {
+		log.Info().Msg("Start server")
+
+		if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			failed <- err
+		}
+	}()
+
+	select {
+	case err := <-failed:
+		log.Error().Err(err).Msg("Fail to listen and serve")
+		return
+	case <-ctx.Done():
+	}
+
+	log.Info().Msg("Shutdown server")
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("Fail to shutdown server")
+	}
+
+	// NOTE - the imports interrupted by the timeout are stored as failed.
+	if err := shutdown(ctx); err != nil {
+		log.Error().Err(err).Msg("Fail to wait for background imports")
+	}
+}
+
+// openDB opens the SQL DB of DB_BACKEND, the drivers of the dialects are registered by storage.
+func openDB(cfg config.ENVConfig) (*sql.DB, error) {
+	dialect, err := storage.DialectByName(cfg.DBBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	return storage.Open(dialect, cfg.DBDSN)
+}
+
+// migrateDB applies the pending migrations, the instances starting at once wait for each other.
+func migrateDB(db *sql.DB) error {
+	migrator, err := migrate.New(db)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up(context.Background())
+	for _, m := range applied {
+		log.Info().Int("version", m.Version).Str("name", m.Name).Msg("Applied migration")
+	}
+
+	return err
 }
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"coding-challenge-go/pkg/auth"
+)
+
+var apiKeyCommands = map[string]command{
+	"create": createAPIKey,
+	"revoke": revokeAPIKey,
+	"list":   listAPIKeys,
+}
+
+type apiKeyJSON struct {
+	Name       string     `json:"name"`
+	Key        string     `json:"key,omitempty"`
+	Scopes     string     `json:"scopes"`
+	SellerUUID string     `json:"seller_uuid,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// createAPIKey creates the key and prints it, it can not be shown again. The key of a seller may
+// modify only its products.
+func createAPIKey(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("apikeys create")
+	name := flags.String("name", "", "the name of the key, e.g. the service using it")
+	scopes := flags.String("scopes", "", "the comma-separated scopes granted to the key: "+
+		"products:read, products:write, sellers:admin")
+	sellerUUID := flags.String("seller", "", "the UUID of the seller the key is bound to")
+
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	parsed, err := auth.ParseScopes(*scopes)
+	if err != nil {
+		return err
+	}
+
+	key, err := auth.NewRepository(e.db, e.cfg.DBQueryTimeout).Create(ctx, *name, parsed, *sellerUUID)
+	if err != nil {
+		return fmt.Errorf("fail to create key: %w", err)
+	}
+
+	return out.render(
+		apiKeyJSON{Name: *name, Key: key, Scopes: auth.FormatScopes(parsed), SellerUUID: *sellerUUID},
+		[]string{"NAME", "KEY"},
+		[][]string{{*name, key}},
+	)
+}
+
+func revokeAPIKey(ctx context.Context, e *env, args []string) error {
+	flags, _ := newFlagSet("apikeys revoke")
+	name := flags.String("name", "", "the name of the key")
+
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return fmt.Errorf("-name is required")
+	}
+
+	if err := auth.NewRepository(e.db, e.cfg.DBQueryTimeout).Revoke(ctx, *name); err != nil {
+		return fmt.Errorf("fail to revoke key: %w", err)
+	}
+
+	return nil
+}
+
+func listAPIKeys(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("apikeys list")
+
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	keys, err := auth.NewRepository(e.db, e.cfg.DBQueryTimeout).List(ctx)
+	if err != nil {
+		return fmt.Errorf("fail to list keys: %w", err)
+	}
+
+	v := make([]apiKeyJSON, 0, len(keys))
+	rows := make([][]string, 0, len(keys))
+
+	for _, key := range keys {
+		createdAt := key.CreatedAt
+
+		revoked := "-"
+		if key.RevokedAt != nil {
+			revoked = key.RevokedAt.Format(time.RFC3339)
+		}
+
+		seller := key.SellerUUID
+		if seller == "" {
+			seller = "-"
+		}
+
+		v = append(v, apiKeyJSON{
+			Name:       key.Name,
+			Scopes:     auth.FormatScopes(key.Scopes),
+			SellerUUID: key.SellerUUID,
+			CreatedAt:  &createdAt,
+			RevokedAt:  key.RevokedAt,
+		})
+		rows = append(rows, []string{
+			key.Name, auth.FormatScopes(key.Scopes), seller, key.CreatedAt.Format(time.RFC3339), revoked,
+		})
+	}
+
+	return out.render(v, []string{"NAME", "SCOPES", "SELLER", "CREATED", "REVOKED"}, rows)
+}
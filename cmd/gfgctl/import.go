@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"coding-challenge-go/pkg/api/importer"
+	"coding-challenge-go/pkg/api/seller"
+)
+
+var rowErrorHeader = []string{"LINE", "EXTERNAL_ID", "FIELD", "ERROR"}
+
+// renderJob renders the job as JSON, or the errors of its rows as a table or CSV along with a
+// summary on stderr.
+func renderJob(out *output, job *importer.Job) error {
+	if out.format != "json" {
+		fmt.Fprintf(os.Stderr, "job %s %s: %d rows, %d processed, %d created, %d updated, %d failed\n",
+			job.UUID, job.Status, job.Total, job.Processed, job.Created, job.Updated, job.Failed)
+
+		if job.Error != "" {
+			fmt.Fprintln(os.Stderr, "error:", job.Error)
+		}
+	}
+
+	rows := make([][]string, 0, len(job.Errors))
+	for _, e := range job.Errors {
+		rows = append(rows, []string{strconv.Itoa(e.Line), e.ExternalID, e.Field, e.Error})
+	}
+
+	return out.render(job, rowErrorHeader, rows)
+}
+
+func newImporter(e *env) (*importer.Importer, error) {
+	admin := productAdmin(e)
+
+	return importer.NewImporter(
+		admin,
+		seller.NewRepository(e.db, e.cfg.DBQueryTimeout),
+		importer.NewRepository(e.db, e.cfg.DBQueryTimeout),
+	), nil
+}
+
+// importProducts imports the file synchronously, as a job like the imports of the API.
+func importProducts(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("products import")
+	file := flags.String("file", "", "the CSV or NDJSON file of the products")
+	format := flags.String("format", "", "the format of the file: csv or ndjson, by its extension by default")
+	dryRun := flags.Bool("dry-run", false, "validates the rows without importing them")
+	upsert := flags.Bool("upsert", false, "updates the products of the sellers by their external IDs")
+
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	if *file == "" {
+		return fmt.Errorf("-file is required")
+	}
+
+	if *format == "" {
+		*format = map[string]string{".csv": "csv", ".ndjson": "ndjson", ".jsonl": "ndjson"}[filepath.Ext(*file)]
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	rows, err := importer.Parse(f, importer.Format(*format), e.cfg.ImportMaxRows)
+	if err != nil {
+		return err
+	}
+
+	im, err := newImporter(e)
+	if err != nil {
+		return err
+	}
+
+	job, err := im.Run(ctx, rows, importer.Options{Format: importer.Format(*format), DryRun: *dryRun, Upsert: *upsert})
+	if err != nil {
+		return fmt.Errorf("fail to import products: %w", err)
+	}
+
+	if err := renderJob(out, job); err != nil {
+		return err
+	}
+
+	if job.Status == importer.StatusFailed {
+		return fmt.Errorf("import failed: %s", job.Error)
+	}
+
+	return nil
+}
+
+func importStatus(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("products import-status")
+
+	uuid, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if uuid == "" {
+		return fmt.Errorf("job UUID is required")
+	}
+
+	im, err := newImporter(e)
+	if err != nil {
+		return err
+	}
+
+	job, err := im.Job(ctx, uuid)
+	if err != nil {
+		return fmt.Errorf("fail to get import job: %w", err)
+	}
+
+	return renderJob(out, job)
+}
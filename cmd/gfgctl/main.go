@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+
+	"coding-challenge-go/pkg/config"
+	"coding-challenge-go/pkg/redact"
+	"coding-challenge-go/pkg/storage"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const usage = `Operates the product service, sharing the repositories of the API. The DB is configured by DB_BACKEND
+and DB_DSN.
+
+Usage:
+  gfgctl products list [-seller <uuid>] [-page <n>] [-size <n>]
+  gfgctl products get <uuid>
+  gfgctl products create -name <name> -brand <brand> -stock <n> -seller <uuid>
+  gfgctl products update <uuid> [-name <name>] [-brand <brand>] [-stock <n>]
+  gfgctl products adjust-stock <uuid> -delta <n>
+  gfgctl products delete <uuid>
+  gfgctl products import -file <path> [-format csv|ndjson] [-dry-run] [-upsert]
+                                                      imports the products, the errors of the rows are reported
+  gfgctl products import-status <uuid>                reports the job of an import, e.g. started by the API
+
+  gfgctl sellers list
+  gfgctl sellers get <uuid>
+  gfgctl sellers create -name <name> -email <email> -phone <phone>
+  gfgctl sellers update <uuid> [-name <name>] [-email <email>] [-phone <phone>]
+  gfgctl sellers delete <uuid>
+  gfgctl sellers notify <uuid> [-channel email|sms]   sends a test notification to the seller
+
+  gfgctl migrate up|status|seed
+  gfgctl migrate down [-steps <n>]
+  gfgctl migrate to <version>
+
+  gfgctl apikeys create -name <name> -scopes <scope,...> [-seller <uuid>]
+  gfgctl apikeys revoke -name <name>
+  gfgctl apikeys list
+
+Every command accepts -o table|json|csv, table by default.
+The stock changes notify the sellers by NOTIFY_EMAIL and NOTIFY_SMS like the API.
+`
+
+// env is the environment of the commands.
+type env struct {
+	cfg      config.ENVConfig
+	db       *sql.DB
+	redactor *redact.Redactor
+}
+
+// command runs a command of a resource with its args.
+type command func(ctx context.Context, e *env, args []string) error
+
+var resources = map[string]map[string]command{
+	"products": productCommands,
+	"sellers":  sellerCommands,
+	"migrate":  migrateCommands,
+	"apikeys":  apiKeyCommands,
+}
+
+func main() {
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	log.Logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	zerolog.DefaultContextLogger = &log.Logger
+
+	if err := run(context.Background(), os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "gfgctl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string) error {
+	if len(args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("resource and command are required")
+	}
+
+	cmd, ok := resources[args[0]][args[1]]
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", args[0]+" "+args[1])
+	}
+
+	e := &env{}
+	if err := envconfig.Process("", &e.cfg); err != nil {
+		return fmt.Errorf("fail to retrieve ENV config: %w", err)
+	}
+
+	redactor, err := redact.New(redact.Mode(e.cfg.LogPIIRedaction), e.cfg.LogPIIHashKey, e.cfg.LogPIIDebug)
+	if err != nil {
+		return err
+	}
+
+	// all the log lines, e.g. of the notifications, are masked before written like by the API.
+	e.redactor = redactor
+	log.Logger = zerolog.New(redactor.Writer(zerolog.ConsoleWriter{Out: os.Stderr}))
+
+	if e.cfg.LogPIIDebug {
+		log.Warn().Msg("PII redaction of logs is disabled by LOG_PII_DEBUG")
+	}
+
+	// NOTE - the memory backend lives in the process of the API, it can not be administrated.
+	if e.cfg.DBBackend == config.DBBackendMemory {
+		return fmt.Errorf("DB_BACKEND=%s is not supported, the commands administrate the SQL DBs", e.cfg.DBBackend)
+	}
+
+	dialect, err := storage.DialectByName(e.cfg.DBBackend)
+	if err != nil {
+		return err
+	}
+
+	db, err := storage.Open(dialect, e.cfg.DBDSN)
+	if err != nil {
+		return fmt.Errorf("fail to open DB: %w", err)
+	}
+
+	defer db.Close()
+
+	e.db = db
+
+	return cmd(ctx, e, args[2:])
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"coding-challenge-go/pkg/migrate"
+)
+
+var migrateCommands = map[string]command{
+	"up":     migrateUp,
+	"down":   migrateDown,
+	"to":     migrateTo,
+	"status": migrateStatus,
+	"seed":   migrateSeed,
+}
+
+var migrationHeader = []string{"VERSION", "NAME", "APPLIED"}
+
+type migrationJSON struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// renderMigrations renders the migrations done, the ones done before a failure are rendered too.
+func renderMigrations(out *output, done []migrate.Migration, err error) error {
+	v := make([]migrationJSON, 0, len(done))
+	rows := make([][]string, 0, len(done))
+
+	for _, m := range done {
+		v = append(v, migrationJSON{Version: m.Version, Name: m.Name})
+		rows = append(rows, []string{fmt.Sprintf("%04d", m.Version), m.Name})
+	}
+
+	if renderErr := out.render(v, migrationHeader[:2], rows); err == nil {
+		err = renderErr
+	}
+
+	return err
+}
+
+func migrateUp(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("migrate up")
+
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	migrator, err := migrate.New(e.db)
+	if err != nil {
+		return err
+	}
+
+	done, err := migrator.Up(ctx)
+
+	return renderMigrations(out, done, err)
+}
+
+func migrateDown(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("migrate down")
+	steps := flags.Int("steps", 1, "the number of the migrations to roll back")
+
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	if *steps < 1 {
+		return fmt.Errorf("-steps must be positive")
+	}
+
+	migrator, err := migrate.New(e.db)
+	if err != nil {
+		return err
+	}
+
+	done, err := migrator.Down(ctx, *steps)
+
+	return renderMigrations(out, done, err)
+}
+
+func migrateTo(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("migrate to")
+
+	arg, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	version, err := strconv.Atoi(arg)
+	if err != nil {
+		return fmt.Errorf("invalid version %q, 0 rolls back all the migrations", arg)
+	}
+
+	migrator, err := migrate.New(e.db)
+	if err != nil {
+		return err
+	}
+
+	done, err := migrator.To(ctx, version)
+
+	return renderMigrations(out, done, err)
+}
+
+func migrateStatus(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("migrate status")
+
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	migrator, err := migrate.New(e.db)
+	if err != nil {
+		return err
+	}
+
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+
+	v := make([]migrationJSON, 0, len(statuses))
+	rows := make([][]string, 0, len(statuses))
+
+	for _, s := range statuses {
+		applied := "pending"
+		if s.AppliedAt != nil {
+			applied = s.AppliedAt.Format(time.RFC3339)
+		}
+
+		v = append(v, migrationJSON{Version: s.Version, Name: s.Name, AppliedAt: s.AppliedAt})
+		rows = append(rows, []string{fmt.Sprintf("%04d", s.Version), s.Name, applied})
+	}
+
+	return out.render(v, migrationHeader, rows)
+}
+
+// migrateSeed inserts the sample sellers and products, for local development only.
+func migrateSeed(ctx context.Context, e *env, args []string) error {
+	flags, _ := newFlagSet("migrate seed")
+
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	migrator, err := migrate.New(e.db)
+	if err != nil {
+		return err
+	}
+
+	return migrator.Seed(ctx)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// output renders the results of a command in the format of its -o flag.
+type output struct {
+	format string
+	w      io.Writer
+}
+
+// newFlagSet builds the flags of a command, along with its -o flag.
+func newFlagSet(name string) (*flag.FlagSet, *output) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	out := &output{w: os.Stdout}
+	flags.StringVar(&out.format, "o", "table", "the output format: table, json or csv")
+
+	return flags, out
+}
+
+// parseArgs parses the flags of the command, which may follow its positional argument, e.g. the UUID
+// of the updated product. It returns the positional argument, which is empty when there is none.
+func parseArgs(flags *flag.FlagSet, args []string) (string, error) {
+	var arg string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		arg, args = args[0], args[1:]
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return "", err
+	}
+
+	if arg == "" {
+		arg = flags.Arg(0)
+	}
+
+	// NOTE - the format is validated before the command changes anything.
+	switch format := flags.Lookup("o").Value.String(); format {
+	case "table", "json", "csv":
+	default:
+		return "", fmt.Errorf("unknown output format %q", format)
+	}
+
+	return arg, nil
+}
+
+// render renders v as JSON, or its rows under the header as a table or CSV.
+func (o *output) render(v interface{}, header []string, rows [][]string) error {
+	switch o.format {
+	case "json":
+		encoder := json.NewEncoder(o.w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(v)
+	case "csv":
+		w := csv.NewWriter(o.w)
+		if err := w.Write(header); err != nil {
+			return err
+		}
+
+		if err := w.WriteAll(rows); err != nil {
+			return err
+		}
+
+		return w.Error()
+	case "table":
+		w := tabwriter.NewWriter(o.w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, strings.Join(header, "\t"))
+
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, "\t"))
+		}
+
+		return w.Flush()
+	}
+
+	return fmt.Errorf("unknown output format %q", o.format)
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"coding-challenge-go/pkg/api/product"
+	"coding-challenge-go/pkg/api/seller"
+	"coding-challenge-go/pkg/storage"
+)
+
+var productCommands = map[string]command{
+	"list":          listProducts,
+	"get":           getProduct,
+	"create":        createProduct,
+	"update":        updateProduct,
+	"adjust-stock":  adjustStock,
+	"delete":        deleteProduct,
+	"import":        importProducts,
+	"import-status": importStatus,
+}
+
+var productHeader = []string{"UUID", "NAME", "BRAND", "STOCK", "SELLER"}
+
+// productAdmin builds the Admin of the products, which notifies the sellers like the API.
+func productAdmin(e *env) *product.Admin {
+	var emailProvider, smsProvider product.StockChangedNotifier
+
+	if e.cfg.NotifySMS {
+		smsProvider = seller.NewSMSProvider(e.redactor)
+	}
+
+	if e.cfg.NotifyEmail {
+		emailProvider = seller.NewEmailProvider(e.redactor)
+	}
+
+	repository := product.NewRepository(e.db, e.cfg.DBQueryTimeout)
+	sellerRepository := seller.NewRepository(e.db, e.cfg.DBQueryTimeout)
+
+	pc := product.NewController(
+		repository,
+		repository,
+		repository,
+		repository,
+		repository,
+		sellerRepository,
+		emailProvider,
+		smsProvider,
+	)
+
+	return product.NewAdmin(pc.WithUnitOfWork(
+		product.NewUnitOfWork(storage.NewTxManager(e.db, e.cfg.DBTxMaxRetries), repository, sellerRepository),
+	))
+}
+
+func renderProducts(out *output, v interface{}, products ...*product.Product) error {
+	rows := make([][]string, 0, len(products))
+	for _, p := range products {
+		rows = append(rows, []string{p.UUID, p.Name, p.Brand, strconv.Itoa(p.Stock), p.SellerUUID})
+	}
+
+	return out.render(v, productHeader, rows)
+}
+
+func listProducts(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("products list")
+	sellerUUID := flags.String("seller", "", "lists only the products of the seller")
+	page := flags.Int("page", 1, "the page of the products")
+	size := flags.Int("size", 100, "the number of the products per page")
+
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	if *page < 1 || *size < 1 {
+		return fmt.Errorf("-page and -size must be positive")
+	}
+
+	admin := productAdmin(e)
+
+	products, err := admin.List(ctx, *sellerUUID, (*page-1)*(*size), *size)
+	if err != nil {
+		return fmt.Errorf("fail to list products: %w", err)
+	}
+
+	if products == nil {
+		products = []*product.Product{}
+	}
+
+	return renderProducts(out, products, products...)
+}
+
+func getProduct(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("products get")
+
+	uuid, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if uuid == "" {
+		return fmt.Errorf("product UUID is required")
+	}
+
+	admin := productAdmin(e)
+
+	p, err := admin.Get(ctx, uuid)
+	if err != nil {
+		return fmt.Errorf("fail to get product: %w", err)
+	}
+
+	return renderProducts(out, p, p)
+}
+
+func createProduct(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("products create")
+	p := &product.Product{}
+	flags.StringVar(&p.Name, "name", "", "the name of the product")
+	flags.StringVar(&p.Brand, "brand", "", "the brand of the product")
+	flags.IntVar(&p.Stock, "stock", 0, "the stock of the product")
+	flags.StringVar(&p.SellerUUID, "seller", "", "the UUID of the seller of the product")
+
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	if p.Name == "" || p.Brand == "" || p.SellerUUID == "" {
+		return fmt.Errorf("-name, -brand and -seller are required")
+	}
+
+	p, err := productAdmin(e).Create(ctx, p)
+	if err != nil {
+		return fmt.Errorf("fail to create product: %w", err)
+	}
+
+	return renderProducts(out, p, p)
+}
+
+func updateProduct(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("products update")
+	name := flags.String("name", "", "the name of the product, unchanged when empty")
+	brand := flags.String("brand", "", "the brand of the product, unchanged when empty")
+	stock := flags.Int("stock", -1, "the stock of the product, unchanged when negative")
+
+	uuid, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if uuid == "" {
+		return fmt.Errorf("product UUID is required")
+	}
+
+	admin := productAdmin(e)
+
+	p, err := admin.Get(ctx, uuid)
+	if err != nil {
+		return fmt.Errorf("fail to get product: %w", err)
+	}
+
+	if *name != "" {
+		p.Name = *name
+	}
+
+	if *brand != "" {
+		p.Brand = *brand
+	}
+
+	if *stock >= 0 {
+		p.Stock = *stock
+	}
+
+	if err := admin.Update(ctx, p); err != nil {
+		return fmt.Errorf("fail to update product: %w", err)
+	}
+
+	return renderProducts(out, p, p)
+}
+
+func adjustStock(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("products adjust-stock")
+	delta := flags.Int("delta", 0, "the delta of the stock, e.g. -1 for a sold item")
+
+	uuid, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if uuid == "" || *delta == 0 {
+		return fmt.Errorf("product UUID and -delta are required")
+	}
+
+	admin := productAdmin(e)
+
+	p, err := admin.AdjustStock(ctx, uuid, *delta)
+	if err != nil {
+		return fmt.Errorf("fail to adjust stock: %w", err)
+	}
+
+	return renderProducts(out, p, p)
+}
+
+func deleteProduct(ctx context.Context, e *env, args []string) error {
+	flags, _ := newFlagSet("products delete")
+
+	uuid, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if uuid == "" {
+		return fmt.Errorf("product UUID is required")
+	}
+
+	admin := productAdmin(e)
+
+	if err := admin.Delete(ctx, uuid); err != nil {
+		return fmt.Errorf("fail to delete product: %w", err)
+	}
+
+	return nil
+}
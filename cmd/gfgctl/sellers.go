@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"coding-challenge-go/pkg/api/product"
+	"coding-challenge-go/pkg/api/seller"
+	"coding-challenge-go/pkg/storage"
+
+	"github.com/rs/zerolog"
+)
+
+var sellerCommands = map[string]command{
+	"list":   listSellers,
+	"get":    getSeller,
+	"create": createSeller,
+	"update": updateSeller,
+	"delete": deleteSeller,
+	"notify": notifySeller,
+}
+
+var sellerHeader = []string{"UUID", "NAME", "EMAIL", "PHONE"}
+
+func renderSellers(out *output, v interface{}, sellers ...*seller.Seller) error {
+	rows := make([][]string, 0, len(sellers))
+	for _, s := range sellers {
+		rows = append(rows, []string{s.UUID, s.Name, s.Email, s.Phone})
+	}
+
+	return out.render(v, sellerHeader, rows)
+}
+
+// findSeller returns the seller, storage.ErrNotFound when it does not exist.
+func findSeller(ctx context.Context, repository *seller.Repository, uuid string) (*seller.Seller, error) {
+	if uuid == "" {
+		return nil, fmt.Errorf("seller UUID is required")
+	}
+
+	s, err := repository.FindByUUID(ctx, uuid)
+	if err != nil {
+		return nil, fmt.Errorf("fail to get seller: %w", err)
+	}
+
+	if s == nil {
+		return nil, fmt.Errorf("fail to get seller: %w", storage.ErrNotFound)
+	}
+
+	return s, nil
+}
+
+func listSellers(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("sellers list")
+
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	sellers, err := seller.NewRepository(e.db, e.cfg.DBQueryTimeout).List(ctx)
+	if err != nil {
+		return fmt.Errorf("fail to list sellers: %w", err)
+	}
+
+	if sellers == nil {
+		sellers = []*seller.Seller{}
+	}
+
+	return renderSellers(out, sellers, sellers...)
+}
+
+func getSeller(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("sellers get")
+
+	uuid, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	s, err := findSeller(ctx, seller.NewRepository(e.db, e.cfg.DBQueryTimeout), uuid)
+	if err != nil {
+		return err
+	}
+
+	return renderSellers(out, s, s)
+}
+
+func createSeller(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("sellers create")
+	s := &seller.Seller{}
+	flags.StringVar(&s.Name, "name", "", "the name of the seller")
+	flags.StringVar(&s.Email, "email", "", "the email of the seller, notified of the stock changes")
+	flags.StringVar(&s.Phone, "phone", "", "the phone of the seller, notified of the stock changes")
+
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	if s.Name == "" || s.Email == "" || s.Phone == "" {
+		return fmt.Errorf("-name, -email and -phone are required")
+	}
+
+	s, err := seller.NewRepository(e.db, e.cfg.DBQueryTimeout).Insert(ctx, s)
+	if err != nil {
+		return fmt.Errorf("fail to create seller: %w", err)
+	}
+
+	return renderSellers(out, s, s)
+}
+
+func updateSeller(ctx context.Context, e *env, args []string) error {
+	flags, out := newFlagSet("sellers update")
+	name := flags.String("name", "", "the name of the seller, unchanged when empty")
+	email := flags.String("email", "", "the email of the seller, unchanged when empty")
+	phone := flags.String("phone", "", "the phone of the seller, unchanged when empty")
+
+	uuid, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	repository := seller.NewRepository(e.db, e.cfg.DBQueryTimeout)
+
+	s, err := findSeller(ctx, repository, uuid)
+	if err != nil {
+		return err
+	}
+
+	if *name != "" {
+		s.Name = *name
+	}
+
+	if *email != "" {
+		s.Email = *email
+	}
+
+	if *phone != "" {
+		s.Phone = *phone
+	}
+
+	if err := repository.Update(ctx, s); err != nil {
+		return fmt.Errorf("fail to update seller: %w", err)
+	}
+
+	return renderSellers(out, s, s)
+}
+
+func deleteSeller(ctx context.Context, e *env, args []string) error {
+	flags, _ := newFlagSet("sellers delete")
+
+	uuid, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	if uuid == "" {
+		return fmt.Errorf("seller UUID is required")
+	}
+
+	if err := seller.NewRepository(e.db, e.cfg.DBQueryTimeout).Delete(ctx, uuid); err != nil {
+		return fmt.Errorf("fail to delete seller, its products and API keys must be deleted first: %w", err)
+	}
+
+	return nil
+}
+
+// notifySeller sends a test notification of a stock change to the seller, by all the channels
+// regardless of NOTIFY_EMAIL and NOTIFY_SMS unless one is chosen. The notifications are logged.
+func notifySeller(ctx context.Context, e *env, args []string) error {
+	flags, _ := newFlagSet("sellers notify")
+	channel := flags.String("channel", "", "the channel of the notification: email or sms, both by default")
+
+	uuid, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+
+	s, err := findSeller(ctx, seller.NewRepository(e.db, e.cfg.DBQueryTimeout), uuid)
+	if err != nil {
+		return err
+	}
+
+	// the providers log the notifications at debug level.
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+
+	var notified bool
+
+	if *channel == "" || *channel == "email" {
+		notify(ctx, seller.NewEmailProvider(e.redactor), s.UUID, s.Email)
+		notified = true
+	}
+
+	if *channel == "" || *channel == "sms" {
+		notify(ctx, seller.NewSMSProvider(e.redactor), s.UUID, s.Phone)
+		notified = true
+	}
+
+	if !notified {
+		return fmt.Errorf("unknown channel %q", *channel)
+	}
+
+	return nil
+}
+
+func notify(ctx context.Context, notifier product.StockChangedNotifier, sellerUUID, receiverID string) {
+	notifier.StockChanged(ctx, sellerUUID, receiverID, 0, 0, "gfgctl test notification")
+}
@@ -0,0 +1,29 @@
+package apierror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+
+	"coding-challenge-go/pkg/storage"
+)
+
+// StatusCode returns the HTTP status code which the error of the lower layers
+// has to be responded with.
+func StatusCode(err error) int {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, storage.ErrConflict), errors.Is(err, storage.ErrDuplicateUUID):
+		return http.StatusConflict
+	case errors.Is(err, storage.ErrForeignKey):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled), errors.Is(err, sql.ErrConnDone):
+		return http.StatusServiceUnavailable
+	}
+
+	return http.StatusInternalServerError
+}
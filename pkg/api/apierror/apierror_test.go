@@ -0,0 +1,36 @@
+package apierror
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"coding-challenge-go/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatusCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "not found", err: fmt.Errorf("update: %w", storage.ErrNotFound), want: http.StatusNotFound},
+		{name: "conflict", err: storage.ErrConflict, want: http.StatusConflict},
+		{name: "duplicate uuid", err: storage.ErrDuplicateUUID, want: http.StatusConflict},
+		{name: "foreign key", err: storage.ErrForeignKey, want: http.StatusUnprocessableEntity},
+		{name: "deadline exceeded", err: fmt.Errorf("query: %w", context.DeadlineExceeded), want: http.StatusGatewayTimeout},
+		{name: "canceled", err: context.Canceled, want: http.StatusServiceUnavailable},
+		{name: "connection done", err: sql.ErrConnDone, want: http.StatusServiceUnavailable},
+		{name: "any error", err: errors.New("any error"), want: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, StatusCode(tt.err))
+		})
+	}
+}
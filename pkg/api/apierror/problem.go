@@ -0,0 +1,172 @@
+package apierror
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"coding-challenge-go/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	// ContentTypeProblem is the media type of the problem details, see RFC 7807.
+	ContentTypeProblem = "application/problem+json"
+
+	// legacyVersion is the API version which keeps responding the errors as {"error": "message"}.
+	legacyVersion = "v1"
+	// typeBaseURI is the base of the URIs which identify the problem types.
+	typeBaseURI = "https://api.gfg.com/problems/"
+	// headerRequestID is the response header carrying the request ID, which is set by the middleware.
+	headerRequestID = "X-Request-ID"
+)
+
+// Code is a stable machine-readable code of an error, clients can rely on it
+// instead of the human-readable messages.
+type Code string
+
+const (
+	CodeInvalidRequest       Code = "invalid_request"
+	CodeValidationFailed     Code = "validation_failed"
+	CodeRouteNotFound        Code = "route_not_found"
+	CodeUnauthorized         Code = "unauthorized"
+	CodeForbidden            Code = "forbidden"
+	CodeUnsupportedVersion   Code = "unsupported_version"
+	CodeVersionSunset        Code = "version_sunset"
+	CodeRateLimited          Code = "rate_limited"
+	CodeIdempotencyKeyReused Code = "idempotency_key_reused"
+	CodeIdempotencyKeyInUse  Code = "idempotency_key_in_use"
+	CodeInsufficientStock    Code = "insufficient_stock"
+	CodeUnsupportedMediaType Code = "unsupported_media_type"
+	CodePayloadTooLarge      Code = "payload_too_large"
+	CodeImportJobNotFound    Code = "import_job_not_found"
+	CodeProductNotFound      Code = "product_not_found"
+	CodeSellerNotFound       Code = "seller_not_found"
+	CodeNotFound             Code = "not_found"
+	CodeConflict             Code = "conflict"
+	CodeDuplicateUUID        Code = "duplicate_uuid"
+	CodeReferenceNotFound    Code = "reference_not_found"
+	CodeTimeout              Code = "timeout"
+	CodeServiceUnavailable   Code = "service_unavailable"
+	CodeInternalServerError  Code = "internal_server_error"
+)
+
+// Problem is the problem details of an error response, see RFC 7807.
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      Code         `json:"code"`
+	RequestID string       `json:"request_id,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is the validation error of a single field of the request.
+type FieldError struct {
+	Field  string `json:"field"`
+	Rule   string `json:"rule"`
+	Detail string `json:"detail"`
+}
+
+// CodeOf returns the code of the error of the lower layers, see StatusCode.
+func CodeOf(err error) Code {
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		return CodeNotFound
+	case errors.Is(err, storage.ErrDuplicateUUID):
+		return CodeDuplicateUUID
+	case errors.Is(err, storage.ErrConflict):
+		return CodeConflict
+	case errors.Is(err, storage.ErrForeignKey):
+		return CodeReferenceNotFound
+	case errors.Is(err, context.DeadlineExceeded):
+		return CodeTimeout
+	case errors.Is(err, context.Canceled), errors.Is(err, sql.ErrConnDone):
+		return CodeServiceUnavailable
+	}
+
+	return CodeInternalServerError
+}
+
+// Respond aborts the request with the error response in the representation of the API version:
+// v1 keeps responding {"error": message} for backward compatibility, later versions respond
+// the problem details.
+func Respond(c *gin.Context, status int, code Code, message string, fieldErrors ...FieldError) {
+	if version, _ := c.Get("version"); version == legacyVersion {
+		c.AbortWithStatusJSON(status, gin.H{"error": message})
+		return
+	}
+
+	body, err := json.Marshal(Problem{
+		Type:      typeBaseURI + string(code),
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    message,
+		Instance:  c.Request.URL.RequestURI(),
+		Code:      code,
+		RequestID: c.Writer.Header().Get(headerRequestID),
+		Errors:    fieldErrors,
+	})
+	if err != nil {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	c.Data(status, ContentTypeProblem, body)
+	c.Abort()
+}
+
+// RespondBinding aborts the request with the error of binding the request into the given struct,
+// the validation errors are described per field, as they are named in the request.
+func RespondBinding(c *gin.Context, err error, request interface{}) {
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		Respond(c, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+
+	fieldErrors := make([]FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:  requestFieldName(request, fe.StructField()),
+			Rule:   fe.Tag(),
+			Detail: fe.Error(),
+		})
+	}
+
+	Respond(c, http.StatusBadRequest, CodeValidationFailed, err.Error(), fieldErrors...)
+}
+
+// requestFieldName returns the name of the struct field in the request, taken from
+// the form or json tag, as the struct field names are not known by the clients.
+func requestFieldName(request interface{}, structField string) string {
+	t := reflect.TypeOf(request)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == nil || t.Kind() != reflect.Struct {
+		return structField
+	}
+
+	field, ok := t.FieldByName(structField)
+	if !ok {
+		return structField
+	}
+
+	for _, key := range []string{"form", "json"} {
+		if name := strings.Split(field.Tag.Get(key), ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return structField
+}
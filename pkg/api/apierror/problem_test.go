@@ -0,0 +1,77 @@
+package apierror
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRespond(t *testing.T) {
+	tests := []struct {
+		name           string
+		version        string
+		handler        gin.HandlerFunc
+		expStatus      int
+		expContentType string
+		expBody        string
+	}{
+		{
+			name:    "v1: responds legacy error",
+			version: "v1",
+			handler: func(c *gin.Context) {
+				Respond(c, http.StatusNotFound, CodeProductNotFound, "Product is not found")
+			},
+			expStatus:      http.StatusNotFound,
+			expContentType: "application/json; charset=utf-8",
+			expBody:        `{"error":"Product is not found"}`,
+		},
+		{
+			name:    "v2: responds problem details with request ID",
+			version: "v2",
+			handler: func(c *gin.Context) {
+				c.Header("X-Request-ID", "req-1")
+				Respond(c, http.StatusNotFound, CodeProductNotFound, "Product is not found")
+			},
+			expStatus:      http.StatusNotFound,
+			expContentType: ContentTypeProblem,
+			expBody:        `{"type":"https://api.gfg.com/problems/product_not_found","title":"Not Found","status":404,"detail":"Product is not found","instance":"/test?id=1","code":"product_not_found","request_id":"req-1"}`,
+		},
+		{
+			name:    "v2: responds validation errors per field",
+			version: "v2",
+			handler: func(c *gin.Context) {
+				request := &struct {
+					Name string `form:"name" binding:"required"`
+					Page int    `form:"page" binding:"min=1"`
+				}{}
+
+				RespondBinding(c, c.ShouldBindQuery(request), request)
+			},
+			expStatus:      http.StatusBadRequest,
+			expContentType: ContentTypeProblem,
+			expBody:        `{"type":"https://api.gfg.com/problems/validation_failed","title":"Bad Request","status":400,"detail":"Key: 'Name' Error:Field validation for 'Name' failed on the 'required' tag\nKey: 'Page' Error:Field validation for 'Page' failed on the 'min' tag","instance":"/test?id=1","code":"validation_failed","errors":[{"field":"name","rule":"required","detail":"Key: 'Name' Error:Field validation for 'Name' failed on the 'required' tag"},{"field":"page","rule":"min","detail":"Key: 'Page' Error:Field validation for 'Page' failed on the 'min' tag"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.GET("/test", func(c *gin.Context) {
+				c.Set("version", tt.version)
+				tt.handler(c)
+			})
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, "/test?id=1", nil)
+			assert.NoError(t, err)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expStatus, w.Code)
+			assert.Equal(t, tt.expContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.expBody, w.Body.String())
+		})
+	}
+}
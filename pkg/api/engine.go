@@ -1,37 +1,107 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
 
+	"coding-challenge-go/pkg/api/importer"
 	"coding-challenge-go/pkg/api/middleware"
+	"coding-challenge-go/pkg/api/openapi"
 	"coding-challenge-go/pkg/api/product"
 	"coding-challenge-go/pkg/api/seller"
+	"coding-challenge-go/pkg/auth"
 	"coding-challenge-go/pkg/config"
+	"coding-challenge-go/pkg/idempotency"
+	"coding-challenge-go/pkg/ratelimit"
+	"coding-challenge-go/pkg/redact"
+	"coding-challenge-go/pkg/storage"
+	"coding-challenge-go/pkg/storage/memory"
 
 	"github.com/gin-gonic/gin"
 )
 
+// dateLayout is the layout of the deprecation and sunset dates in the config.
+const dateLayout = "2006-01-02"
+
+// maxRequestBytes bounds the JSON bodies read by the idempotency middleware, which is far above
+// the batches of 1000 operations.
+const maxRequestBytes = 1 << 20
+
 // CreateAPIEngine creates engine instance that serves API endpoints,
 // consider it as a router for incoming requests.
-func CreateAPIEngine(db *sql.DB, cfg config.ENVConfig) (*gin.Engine, error) {
+//
+// The shutdown returned along waits for the work started in the background by the requests, e.g.
+// the imports, it is to be called once the server stopped serving.
+func CreateAPIEngine(db *sql.DB, cfg config.ENVConfig) (_ *gin.Engine, shutdown func(ctx context.Context) error, _ error) {
 	r := gin.New()
+	// NOTE - gin trusts the forwarded headers of any client, the clients are resolved by middleware.ClientIP.
+	r.ForwardedByClientIP = false
+
+	proxies, err := parseProxies(cfg.TrustedProxies)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.Use(middleware.Tracing)
+	r.Use(middleware.ClientIP(proxies))
+	r.Use(middleware.RequestID)
+	r.Use(middleware.AccessLog)
+	registry, err := newVersionRegistry(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	r.Use(middleware.APIVersionResolver)
+	r.Use(middleware.NewAPIVersionResolver(registry))
 
-	v1 := r.Group("api/v1")
-	v2 := r.Group("api/v2")
+	// the specifications are public, all the other routes require the scope of their resource.
+	r.GET("api/v1/openapi.json", openapi.Handler)
+	r.GET("api/v2/openapi.json", openapi.Handler)
+	r.GET("api/v3/openapi.json", openapi.Handler)
+
+	authenticate, scope, err := authorization(db, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	read, write, sellersAdmin := scope(auth.ScopeProductsRead), scope(auth.ScopeProductsWrite), scope(auth.ScopeSellersAdmin)
+
+	limitIP, limit, err := rateLimit(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	productRepository := product.NewRepository(db)
-	sellerRepository := seller.NewRepository(db)
+	// NOTE - the requests are limited after the authentication, so that they are limited per principal,
+	// and by their IPs ahead of it, so that the requests failing it are limited too.
+	handlers := append(append(append([]gin.HandlerFunc{}, limitIP...), authenticate...), limit...)
+
+	v1 := r.Group("api/v1", handlers...)
+	v2 := r.Group("api/v2", handlers...)
+	v3 := r.Group("api/v3", handlers...)
+
+	store, err := newBackend(db, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	productRepository, sellerRepository := store.products, store.sellers
+
+	redactor, err := redact.New(redact.Mode(cfg.LogPIIRedaction), cfg.LogPIIHashKey, cfg.LogPIIDebug)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	var emailProvider, smsProvider product.StockChangedNotifier
 
 	if cfg.NotifySMS {
-		smsProvider = seller.NewSMSProvider()
+		smsProvider = seller.NewSMSProvider(redactor)
 	}
 
 	if cfg.NotifyEmail {
-		emailProvider = seller.NewEmailProvider()
+		emailProvider = seller.NewEmailProvider(redactor)
 	}
 
 	productController := product.NewController(
@@ -44,26 +114,255 @@ func CreateAPIEngine(db *sql.DB, cfg config.ENVConfig) (*gin.Engine, error) {
 		emailProvider,
 		smsProvider,
 	)
+	productController.WithUnitOfWork(store.unitOfWork)
+
+	// the creations and the stock adjustments can be retried safely with an Idempotency-Key.
+	idempotent := middleware.Idempotency(store.idempotency, cfg.IdempotencyTTL, maxRequestBytes)
 
-	v1.GET("products", productController.List)
-	v1.GET("product", productController.Get)
-	v1.POST("product", productController.Post)
-	v1.PUT("product", productController.Put)
-	v1.DELETE("product", productController.Delete)
+	v1.GET("products", read, productController.List)
+	v1.GET("product", read, productController.Get)
+	v1.POST("product", write, idempotent, productController.Post)
+	v1.PUT("product", write, productController.Put)
+	v1.DELETE("product", write, productController.Delete)
 	sellerController := seller.NewController(sellerRepository, sellerRepository)
-	v1.GET("sellers", sellerController.List)
+	v1.GET("sellers", sellersAdmin, sellerController.List)
 
 	// The decision of having same Controller and having different view as per
 	// different API version is - as this is the minimal change and easy to maintain
 	// and very less duplication. Having Different controllers could be useful, if
 	// we have major changes and previous versions are going to be removed in short time,
 	// but this is not clear from the requirement, so I am assuming we will maintain 2 versions.
-	v2.GET("products", productController.List)
-	v2.GET("product", productController.Get)
-	v2.POST("product", productController.Post)
-	v2.PUT("product", productController.Put)
-	v2.DELETE("product", productController.Delete)
-	v2.GET("sellers/top10", sellerController.Top10)
-
-	return r, nil
+	v2.GET("products", read, productController.List)
+	v2.GET("products/export", read, productController.Export)
+	v2.GET("product", read, productController.Get)
+	v2.POST("product", write, idempotent, productController.Post)
+	v2.PUT("product", write, productController.Put)
+	v2.PATCH("product/stock", write, idempotent, productController.AdjustStock)
+	// the batches of writes are executed in a transaction, or one by one in the best-effort mode.
+	v2.POST("products/batch", write, idempotent, product.NewBatchController(productController, store.unitOfWork).Post)
+	v2.DELETE("product", write, productController.Delete)
+	v2.GET("sellers/top10", sellersAdmin, sellerController.Top10)
+
+	// the catalogs are imported by background jobs, whose progress and errors are polled.
+	imports := importer.NewImporter(product.NewAdmin(productController), sellerRepository, store.jobs)
+	importController := importer.NewController(imports, cfg.ImportMaxRows)
+	idempotentImport := middleware.Idempotency(store.idempotency, cfg.IdempotencyTTL, importer.MaxFileBytes(cfg.ImportMaxRows))
+	v2.POST("products/import", write, idempotentImport, importController.Post)
+	v2.GET("products/import", read, importController.Get)
+
+	// v3 identifies the resources by the path instead of the id query param, the same controllers
+	// serve it. The router can not mix static and wildcard segments, so the top sellers moved out of
+	// sellers/ to top-sellers.
+	v3.GET("products", read, productController.List)
+	v3.POST("products", write, idempotent, productController.Post)
+	v3.GET("products/:uuid", read, productController.Get)
+	v3.PUT("products/:uuid", write, productController.Put)
+	v3.PATCH("products/:uuid/stock", write, idempotent, productController.AdjustStock)
+	v3.DELETE("products/:uuid", write, productController.Delete)
+	v3.GET("sellers", sellersAdmin, sellerController.List)
+	v3.GET("sellers/:uuid/products", read, productController.ListBySeller)
+	v3.GET("top-sellers", sellersAdmin, sellerController.Top10)
+
+	// The unversioned routes negotiate the version by Accept or API-Version headers,
+	// see middleware.APIVersionResolver.
+	unversioned := r.Group("api", handlers...)
+	unversioned.GET("products", read, productController.List)
+	unversioned.GET("product", read, productController.Get)
+	unversioned.POST("product", write, idempotent, productController.Post)
+	unversioned.PUT("product", write, productController.Put)
+	unversioned.DELETE("product", write, productController.Delete)
+	unversioned.GET("sellers", sellersAdmin, sellerController.List)
+	unversioned.GET("sellers/top10", sellersAdmin, sellerController.Top10)
+
+	return r, imports.Wait, nil
+}
+
+// backend is the storage of the repositories, selected by DB_BACKEND.
+type backend struct {
+	products    product.Store
+	sellers     seller.Store
+	unitOfWork  product.UnitOfWork
+	idempotency middleware.IdempotencyStore
+	jobs        importer.JobStore
+}
+
+// newBackend builds the repositories of the storage backend of the config.
+func newBackend(db *sql.DB, cfg config.ENVConfig) (*backend, error) {
+	switch cfg.DBBackend {
+	case config.DBBackendMemory:
+		mdb := memory.New()
+
+		if cfg.DBMemorySeed {
+			if err := memory.Seed(context.Background(), mdb); err != nil {
+				return nil, err
+			}
+		}
+
+		return &backend{
+			products:    product.NewMemoryRepository(mdb),
+			sellers:     seller.NewMemoryRepository(mdb),
+			unitOfWork:  product.NewMemoryUnitOfWork(mdb),
+			idempotency: idempotency.NewMemoryStore(),
+			jobs:        importer.NewMemoryRepository(),
+		}, nil
+	// NOTE - the zero config, e.g. of the tests, is of MySQL. The repositories adapt their queries to the
+	// dialect of the driver of the DB.
+	case config.DBBackendMySQL, config.DBBackendPostgres, config.DBBackendSQLite, "":
+		productRepository := product.NewRepository(db, cfg.DBQueryTimeout)
+		sellerRepository := seller.NewRepository(db, cfg.DBQueryTimeout)
+
+		return &backend{
+			products:    productRepository,
+			sellers:     sellerRepository,
+			unitOfWork:  product.NewUnitOfWork(storage.NewTxManager(db, cfg.DBTxMaxRetries), productRepository, sellerRepository),
+			idempotency: idempotency.NewRepository(db, cfg.DBQueryTimeout),
+			jobs:        importer.NewRepository(db, cfg.DBQueryTimeout),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("api: unknown DB_BACKEND %q", cfg.DBBackend)
+}
+
+// authorization returns the middlewares authenticating the requests and requiring the scopes,
+// they let all the requests through when the authentication is disabled.
+func authorization(db *sql.DB, cfg config.ENVConfig) ([]gin.HandlerFunc, func(auth.Scope) gin.HandlerFunc, error) {
+	if !cfg.AuthEnabled {
+		return nil, func(auth.Scope) gin.HandlerFunc {
+			return func(*gin.Context) {}
+		}, nil
+	}
+
+	// NOTE - the API keys are stored only in the SQL DBs.
+	if cfg.DBBackend == config.DBBackendMemory {
+		return nil, nil, fmt.Errorf("api: AUTH_ENABLED is not supported by DB_BACKEND=%s, set AUTH_ENABLED=false", config.DBBackendMemory)
+	}
+
+	var verifier middleware.TokenVerifier
+
+	if cfg.JWTJWKS != "" {
+		// NOTE - the tokens issued by the SSO for other services must not be accepted.
+		if cfg.JWTIssuer == "" || cfg.JWTAudience == "" {
+			return nil, nil, fmt.Errorf("api: JWT_ISSUER and JWT_AUDIENCE are required by JWT_JWKS")
+		}
+
+		verifier = auth.NewJWTVerifier(auth.NewKeySet(cfg.JWTJWKS, cfg.JWTJWKSRefresh), auth.JWTConfig{
+			Issuer:      cfg.JWTIssuer,
+			Audience:    cfg.JWTAudience,
+			ClockSkew:   cfg.JWTClockSkew,
+			ScopeClaim:  cfg.JWTScopeClaim,
+			SellerClaim: cfg.JWTSellerClaim,
+		})
+	}
+
+	authenticate := middleware.Authenticate(auth.NewRepository(db, cfg.DBQueryTimeout), verifier)
+
+	return []gin.HandlerFunc{authenticate}, middleware.RequireScope, nil
+}
+
+// rateLimit returns the middlewares limiting the requests by the IPs ahead of the authentication and
+// by the principals after it, none when the rate limit is disabled.
+func rateLimit(cfg config.ENVConfig) ([]gin.HandlerFunc, []gin.HandlerFunc, error) {
+	if !cfg.RateLimitEnabled {
+		return nil, nil, nil
+	}
+
+	ipLimit, err := ratelimit.ParseLimit(cfg.RateLimitIP)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	limits := middleware.RateLimits{}
+
+	if limits.Read, err = ratelimit.ParseLimit(cfg.RateLimitRead); err != nil {
+		return nil, nil, err
+	}
+
+	if limits.Write, err = ratelimit.ParseLimit(cfg.RateLimitWrite); err != nil {
+		return nil, nil, err
+	}
+
+	if limits.ReadQuotas, err = parseQuotas(cfg.RateLimitReadQuotas); err != nil {
+		return nil, nil, err
+	}
+
+	if limits.WriteQuotas, err = parseQuotas(cfg.RateLimitWriteQuotas); err != nil {
+		return nil, nil, err
+	}
+
+	store := ratelimit.NewMemoryStore()
+
+	return []gin.HandlerFunc{middleware.RateLimitByIP(store, ipLimit)},
+		[]gin.HandlerFunc{middleware.RateLimit(store, limits)}, nil
+}
+
+// parseProxies parses the IPs or the CIDRs of the trusted proxies.
+func parseProxies(proxies []string) ([]netip.Prefix, error) {
+	parsed := make([]netip.Prefix, 0, len(proxies))
+
+	for _, proxy := range proxies {
+		if addr, err := netip.ParseAddr(proxy); err == nil {
+			parsed = append(parsed, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("api: invalid trusted proxy %q", proxy)
+		}
+
+		parsed = append(parsed, prefix.Masked())
+	}
+
+	return parsed, nil
+}
+
+// parseQuotas parses the limits of the principals by their names.
+func parseQuotas(quotas map[string]string) (map[string]ratelimit.Limit, error) {
+	parsed := make(map[string]ratelimit.Limit, len(quotas))
+
+	for name, quota := range quotas {
+		if way, _, _ := strings.Cut(name, "/"); way != "apikey" && way != "jwt" {
+			return nil, fmt.Errorf("api: invalid quota of %s: the principal is not named as apikey/<name> or jwt/<sub>", name)
+		}
+
+		limit, err := ratelimit.ParseLimit(quota)
+		if err != nil {
+			return nil, fmt.Errorf("api: invalid quota of %s: %w", name, err)
+		}
+
+		parsed[name] = limit
+	}
+
+	return parsed, nil
+}
+
+// newVersionRegistry builds the registry of the served API versions with their deprecation plan.
+func newVersionRegistry(cfg config.ENVConfig) (*middleware.VersionRegistry, error) {
+	versions := []middleware.Version{{Name: "v1"}, {Name: "v2"}, {Name: "v3"}}
+
+	for i := range versions {
+		v := &versions[i]
+
+		if date, ok := cfg.APIDeprecated[v.Name]; ok {
+			deprecatedAt, err := time.Parse(dateLayout, date)
+			if err != nil {
+				return nil, fmt.Errorf("api: invalid deprecation date of %s: %w", v.Name, err)
+			}
+
+			v.DeprecatedAt = deprecatedAt
+			v.MigrationURL = strings.ReplaceAll(cfg.APIMigrationDocsURL, "{version}", v.Name)
+		}
+
+		if date, ok := cfg.APISunset[v.Name]; ok {
+			sunset, err := time.Parse(dateLayout, date)
+			if err != nil {
+				return nil, fmt.Errorf("api: invalid sunset date of %s: %w", v.Name, err)
+			}
+
+			v.Sunset = sunset
+			v.RejectAfterSunset = cfg.APIRejectAfterSunset
+		}
+	}
+
+	return middleware.NewVersionRegistry("v2", versions...)
 }
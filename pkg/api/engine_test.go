@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"coding-challenge-go/pkg/api/openapi"
+	"coding-challenge-go/pkg/config"
+	"coding-challenge-go/pkg/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pathParam matches the path parameters of the routes, e.g. :uuid.
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+func TestCreateAPIEngine_routesAreSpecified(t *testing.T) {
+	engine, _, err := CreateAPIEngine(nil, config.ENVConfig{LogPIIRedaction: "full"})
+	assert.NoError(t, err)
+
+	for _, route := range engine.Routes() {
+		// the unversioned routes are specified by the versions they negotiate.
+		components := strings.SplitN(strings.TrimPrefix(route.Path, "/api/"), "/", 2)
+		if len(components) < 2 || !regexp.MustCompile(`^v\d+$`).MatchString(components[0]) {
+			continue
+		}
+
+		version, path := components[0], "/"+pathParam.ReplaceAllString(components[1], "{$1}")
+
+		doc, ok := openapi.Spec(version)
+		if !assert.True(t, ok, "no specification of %s", version) {
+			continue
+		}
+
+		_, ok = doc.Paths[path][strings.ToLower(route.Method)]
+		assert.True(t, ok, "%s %s is not specified in %s", route.Method, path, version)
+	}
+}
+
+func TestCreateAPIEngine_memoryBackend(t *testing.T) {
+	cfg := config.ENVConfig{LogPIIRedaction: "full", DBBackend: config.DBBackendMemory, DBMemorySeed: true}
+
+	engine, _, err := CreateAPIEngine(nil, cfg)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v2/products", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"Raja"`)
+
+	// the API keys are stored only in MySQL.
+	cfg.AuthEnabled = true
+	_, _, err = CreateAPIEngine(nil, cfg)
+	assert.Error(t, err)
+
+	_, _, err = CreateAPIEngine(nil, config.ENVConfig{LogPIIRedaction: "full", DBBackend: "mongodb"})
+	assert.Error(t, err)
+}
+
+func TestParseQuotas(t *testing.T) {
+	quotas, err := parseQuotas(map[string]string{"apikey/shop": "6000/1m", "jwt/feed": "1200/1m"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]ratelimit.Limit{
+		"apikey/shop": {Requests: 6000, Period: time.Minute},
+		"jwt/feed":    {Requests: 1200, Period: time.Minute},
+	}, quotas)
+
+	_, err = parseQuotas(map[string]string{"shop": "6000/1m"})
+	assert.Error(t, err)
+
+	_, err = parseQuotas(map[string]string{"apikey/shop": "6000"})
+	assert.Error(t, err)
+}
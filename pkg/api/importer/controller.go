@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"coding-challenge-go/pkg/api/apierror"
+	"coding-challenge-go/pkg/auth"
+	"coding-challenge-go/pkg/logging"
+	"coding-challenge-go/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// maxBytesPerRow bounds the size of the imported files along with the limit of their rows.
+const maxBytesPerRow = 2048
+
+// MaxFileBytes returns the maximum size of the imported files of maxRows rows.
+func MaxFileBytes(maxRows int) int64 {
+	return int64(maxRows+1) * maxBytesPerRow
+}
+
+// controller is an HTTP controller handles HTTP requests for the imports of the products.
+type controller struct {
+	importer *Importer
+	maxRows  int
+}
+
+// NewController builds the import controller, the files may have maxRows rows at most.
+func NewController(importer *Importer, maxRows int) *controller {
+	return &controller{importer: importer, maxRows: maxRows}
+}
+
+// Post starts the import of the CSV or NDJSON file of the body, and responds the job with 202. The
+// progress and the errors of the rows are responded by Get.
+func (ic *controller) Post(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	request := &struct {
+		DryRun bool   `form:"dry_run"`
+		Mode   string `form:"mode,default=insert" binding:"oneof=insert upsert"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		apierror.RespondBinding(c, err, request)
+		return
+	}
+
+	format, ok := FormatOf(c.ContentType())
+	if !ok {
+		apierror.Respond(c, http.StatusUnsupportedMediaType, apierror.CodeUnsupportedMediaType,
+			"Content-Type must be text/csv or application/x-ndjson")
+		return
+	}
+
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, MaxFileBytes(ic.maxRows))
+
+	rows, err := Parse(body, format, ic.maxRows)
+
+	var tooLarge *http.MaxBytesError
+
+	switch {
+	case errors.Is(err, ErrTooManyRows), errors.As(err, &tooLarge):
+		apierror.Respond(c, http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, "File is too large")
+		return
+	case err != nil:
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidRequest, strings.TrimPrefix(err.Error(), "importer: "))
+		return
+	case len(rows) == 0:
+		apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidRequest, "File has no rows")
+		return
+	}
+
+	job, err := ic.importer.Start(ctx, rows, Options{
+		Format:    format,
+		DryRun:    request.DryRun,
+		Upsert:    request.Mode == "upsert",
+		Principal: auth.PrincipalFrom(ctx),
+	})
+
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to start import job")
+		apierror.Respond(c, apierror.StatusCode(err), apierror.CodeOf(err), "Fail to start import job")
+		return
+	}
+
+	c.Header("Location", c.Request.URL.Path+"?id="+job.UUID)
+	c.JSON(http.StatusAccepted, job)
+}
+
+// Get returns the job with its progress and the errors of its rows. The jobs of other principals
+// are not found.
+func (ic *controller) Get(c *gin.Context) {
+	request := &struct {
+		UUID string `form:"id" binding:"required"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		apierror.RespondBinding(c, err, request)
+		return
+	}
+
+	ctx := logging.WithStr(c.Request.Context(), "import_job_uuid", request.UUID)
+
+	job, err := ic.importer.Job(ctx, request.UUID)
+
+	if errors.Is(err, storage.ErrNotFound) || (err == nil && !mayRead(auth.PrincipalFrom(ctx), job)) {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeImportJobNotFound, "Import job is not found")
+		return
+	}
+
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to query import job")
+		apierror.Respond(c, apierror.StatusCode(err), apierror.CodeOf(err), "Fail to query import job")
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// mayRead reports whether the principal may read the job, the jobs are owned by the IDs of their
+// principals. The admins read all the jobs.
+func mayRead(principal *auth.Principal, job *Job) bool {
+	return principal == nil || principal.ID == job.Principal || principal.HasScope(auth.ScopeSellersAdmin)
+}
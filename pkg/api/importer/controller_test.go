@@ -0,0 +1,227 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"coding-challenge-go/pkg/api/middleware"
+	"coding-challenge-go/pkg/api/product"
+	"coding-challenge-go/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func newTestRouter(im *Importer, principal *auth.Principal) *gin.Engine {
+	ic := NewController(im, 2)
+
+	r := gin.Default()
+	r.Use(middleware.APIVersionResolver, func(c *gin.Context) {
+		if principal != nil {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		}
+	})
+
+	r.POST("/api/v2/products/import", ic.Post)
+	r.GET("/api/v2/products/import", ic.Get)
+
+	return r
+}
+
+func Test_controller_Post(t *testing.T) {
+	const csvFile = "name,brand,seller\nShoes,Nike," + sellerUUID + "\n"
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		expStatus   int
+		expCode     string
+	}{
+		{
+			name:        "Returns 202, when CSV is imported",
+			path:        "/api/v2/products/import",
+			contentType: "text/csv",
+			body:        csvFile,
+			expStatus:   http.StatusAccepted,
+		},
+		{
+			name:        "Returns 202, when NDJSON is upserted in dry run",
+			path:        "/api/v2/products/import?mode=upsert&dry_run=true",
+			contentType: "application/x-ndjson",
+			body:        `{"external_id":"sku-1","name":"Shoes","brand":"Nike","seller":"` + sellerUUID + `"}`,
+			expStatus:   http.StatusAccepted,
+		},
+		{
+			name:        "Returns 400, when mode is unknown",
+			path:        "/api/v2/products/import?mode=replace",
+			contentType: "text/csv",
+			body:        csvFile,
+			expStatus:   http.StatusBadRequest,
+			expCode:     "validation_failed",
+		},
+		{
+			name:        "Returns 415, when Content-Type is not CSV or NDJSON",
+			path:        "/api/v2/products/import",
+			contentType: "application/json",
+			body:        `[]`,
+			expStatus:   http.StatusUnsupportedMediaType,
+			expCode:     "unsupported_media_type",
+		},
+		{
+			name:        "Returns 413, when file has too many rows",
+			path:        "/api/v2/products/import",
+			contentType: "text/csv",
+			body:        csvFile + "Shirt,Zara," + sellerUUID + "\nHat,Gap," + sellerUUID + "\n",
+			expStatus:   http.StatusRequestEntityTooLarge,
+			expCode:     "payload_too_large",
+		},
+		{
+			name:        "Returns 413, when file is too large",
+			path:        "/api/v2/products/import",
+			contentType: "text/csv",
+			body:        "name,brand,seller\n" + strings.Repeat("x", 3*maxBytesPerRow) + ",Nike," + sellerUUID + "\n",
+			expStatus:   http.StatusRequestEntityTooLarge,
+			expCode:     "payload_too_large",
+		},
+		{
+			name:        "Returns 400, when CSV has unknown column",
+			path:        "/api/v2/products/import",
+			contentType: "text/csv",
+			body:        "name,brand,seller,color\n",
+			expStatus:   http.StatusBadRequest,
+			expCode:     "invalid_request",
+		},
+		{
+			name:        "Returns 400, when file has no rows",
+			path:        "/api/v2/products/import",
+			contentType: "text/csv",
+			body:        "name,brand,seller\n",
+			expStatus:   http.StatusBadRequest,
+			expCode:     "invalid_request",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			products := new(ProductWriterMock)
+			products.On("Create", mock.Anything, mock.Anything).Return(&product.Product{}, nil)
+
+			im := NewImporter(products, newSellerFinder(), newJobStore())
+			r := newTestRouter(im, nil)
+
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", tt.contentType)
+
+			r.ServeHTTP(w, req)
+			assert.NoError(t, im.Wait(context.Background()))
+
+			assert.Equal(t, tt.expStatus, w.Code)
+
+			body := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+			if tt.expCode != "" {
+				assert.Equal(t, tt.expCode, body["code"])
+				return
+			}
+
+			assert.Equal(t, "running", body["status"])
+			assert.Equal(t, "/api/v2/products/import?id="+body["uuid"].(string), w.Header().Get("Location"))
+		})
+	}
+}
+
+func Test_controller_Get(t *testing.T) {
+	const jobUUID = "0b6a4b0c-33c2-4f2e-9c39-5f1f4e3cd0a1"
+
+	job := &Job{UUID: jobUUID, Principal: "apikey:1", Format: FormatCSV, Status: StatusSucceeded, Total: 1, Processed: 1,
+		Failed: 1, Errors: []RowError{{Line: 2, Field: "stock", Error: "must not be negative"}}}
+
+	tests := []struct {
+		name      string
+		principal *auth.Principal
+		path      string
+		expStatus int
+		expCode   string
+	}{
+		{
+			name:      "Returns 200, when job is of the principal",
+			principal: &auth.Principal{ID: "apikey:1", Name: "shop"},
+			path:      "/api/v2/products/import?id=" + jobUUID,
+			expStatus: http.StatusOK,
+		},
+		{
+			name:      "Returns 200, when principal is admin",
+			principal: &auth.Principal{ID: "apikey:2", Name: "backoffice", Scopes: []auth.Scope{auth.ScopeSellersAdmin}},
+			path:      "/api/v2/products/import?id=" + jobUUID,
+			expStatus: http.StatusOK,
+		},
+		{
+			name:      "Returns 404, when job is of another principal",
+			principal: &auth.Principal{ID: "apikey:3", Name: "other-shop"},
+			path:      "/api/v2/products/import?id=" + jobUUID,
+			expStatus: http.StatusNotFound,
+			expCode:   "import_job_not_found",
+		},
+		{
+			name:      "Returns 404, when job is of another principal of the same name",
+			principal: &auth.Principal{ID: "jwt:shop", Name: "shop"},
+			path:      "/api/v2/products/import?id=" + jobUUID,
+			expStatus: http.StatusNotFound,
+			expCode:   "import_job_not_found",
+		},
+		{
+			name:      "Returns 404, when job is not found",
+			path:      "/api/v2/products/import?id=missing",
+			expStatus: http.StatusNotFound,
+			expCode:   "import_job_not_found",
+		},
+		{
+			name:      "Returns 400, when id is missing",
+			path:      "/api/v2/products/import",
+			expStatus: http.StatusBadRequest,
+			expCode:   "validation_failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jobs := new(JobStoreMock)
+			jobs.On("findByUUID", mock.Anything, jobUUID).Return(job, nil)
+			jobs.On("findByUUID", mock.Anything, "missing").Return(nil, nil)
+
+			r := newTestRouter(NewImporter(nil, nil, jobs), tt.principal)
+
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, tt.path, nil)
+			assert.NoError(t, err)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expStatus, w.Code)
+
+			if tt.expCode != "" {
+				body := map[string]interface{}{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, tt.expCode, body["code"])
+				return
+			}
+
+			assert.JSONEq(t, `{"uuid":"`+jobUUID+`","format":"csv","dry_run":false,"upsert":false,"status":"succeeded",`+
+				`"total":1,"processed":1,"created":0,"updated":0,"failed":1,`+
+				`"errors":[{"line":2,"field":"stock","error":"must not be negative"}],"created_at":"0001-01-01T00:00:00Z"}`,
+				w.Body.String())
+		})
+	}
+}
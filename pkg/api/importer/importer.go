@@ -0,0 +1,366 @@
+// Package importer imports the catalogs of the sellers, CSV or NDJSON files of thousands of products,
+// by background jobs which report the errors of every row.
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"coding-challenge-go/pkg/api/product"
+	"coding-challenge-go/pkg/api/seller"
+	"coding-challenge-go/pkg/auth"
+	"coding-challenge-go/pkg/storage"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Status is the status of a job.
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	// StatusFailed is the status of the job which is stopped by an error of the DB, the rows imported
+	// before it are not rolled back.
+	StatusFailed Status = "failed"
+)
+
+// progressEvery is the number of the rows after which the progress of the job is stored.
+const progressEvery = 100
+
+// sellersBatch is the number of the sellers queried at once.
+const sellersBatch = 500
+
+// errInterrupted fails the jobs which are not finished by the shutdown of the server.
+var errInterrupted = errors.New("interrupted by shutdown")
+
+// Job is an import of a file, along with its progress and the errors of its rows.
+type Job struct {
+	UUID      string `json:"uuid"`
+	Principal string `json:"-"`
+	Format    Format `json:"format"`
+	// DryRun validates the rows without importing them.
+	DryRun bool `json:"dry_run"`
+	// Upsert updates the products of the sellers by their external IDs, instead of inserting them.
+	Upsert     bool       `json:"upsert"`
+	Status     Status     `json:"status"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Created    int        `json:"created"`
+	Updated    int        `json:"updated"`
+	Failed     int        `json:"failed"`
+	Errors     []RowError `json:"errors"`
+	Error      string     `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Options are the options of an import.
+type Options struct {
+	Format Format
+	DryRun bool
+	Upsert bool
+	// Principal is the principal importing the file, the principal of a seller may import only
+	// its products. It is nil when the authentication is disabled, e.g. for the admin tools.
+	Principal *auth.Principal
+}
+
+// ProductWriter writes the imported products.
+type ProductWriter interface {
+	Create(ctx context.Context, product *product.Product) (*product.Product, error)
+	Upsert(ctx context.Context, product *product.Product) (bool, error)
+}
+
+// SellerFinder finds the sellers of the imported products.
+type SellerFinder interface {
+	FindByUUIDs(ctx context.Context, uuids []string) ([]*seller.Seller, error)
+}
+
+// JobStore stores the jobs and their progress.
+type JobStore interface {
+	insert(ctx context.Context, job *Job) error
+	update(ctx context.Context, job *Job) error
+	findByUUID(ctx context.Context, uuid string) (*Job, error)
+}
+
+// Importer imports the files by jobs.
+type Importer struct {
+	products ProductWriter
+	sellers  SellerFinder
+	jobs     JobStore
+	now      func() time.Time
+
+	wg sync.WaitGroup
+	// interrupted stops the jobs at their next row, see Wait.
+	interrupted atomic.Bool
+}
+
+// NewImporter builds the Importer.
+func NewImporter(products ProductWriter, sellers SellerFinder, jobs JobStore) *Importer {
+	return &Importer{products: products, sellers: sellers, jobs: jobs, now: time.Now}
+}
+
+// Start starts the job importing the rows in the background, and returns it as started.
+func (im *Importer) Start(ctx context.Context, rows []Row, opts Options) (*Job, error) {
+	job, err := im.create(ctx, rows, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	started := *job
+
+	// NOTE - the job outlives the request, but keeps the logger and the trace of its context.
+	ctx = context.WithoutCancel(ctx)
+
+	im.wg.Add(1)
+
+	go func() {
+		defer im.wg.Done()
+		im.run(ctx, job, rows, opts.Principal)
+	}()
+
+	return &started, nil
+}
+
+// Run imports the rows and returns the finished job, e.g. for the admin tools.
+func (im *Importer) Run(ctx context.Context, rows []Row, opts Options) (*Job, error) {
+	job, err := im.create(ctx, rows, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	im.run(ctx, job, rows, opts.Principal)
+
+	return job, nil
+}
+
+// Wait waits for the jobs started in the background, e.g. on the shutdown of the server. When ctx is
+// done first, the jobs are interrupted at their next row and stored as failed, so that they are not
+// left as running, and the error of ctx is returned.
+func (im *Importer) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		im.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	// NOTE - the rows in progress are bounded by the query timeout, the jobs stop shortly.
+	im.interrupted.Store(true)
+	<-done
+
+	return ctx.Err()
+}
+
+// Job returns the job, storage.ErrNotFound when it does not exist.
+func (im *Importer) Job(ctx context.Context, uuid string) (*Job, error) {
+	job, err := im.jobs.findByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if job == nil {
+		return nil, storage.ErrNotFound
+	}
+
+	return job, nil
+}
+
+func (im *Importer) create(ctx context.Context, rows []Row, opts Options) (*Job, error) {
+	job := &Job{
+		UUID:      uuid.New().String(),
+		Format:    opts.Format,
+		DryRun:    opts.DryRun,
+		Upsert:    opts.Upsert,
+		Status:    StatusRunning,
+		Total:     len(rows),
+		Errors:    []RowError{},
+		CreatedAt: im.now().UTC().Truncate(time.Second),
+	}
+
+	if opts.Principal != nil {
+		job.Principal = opts.Principal.ID
+	}
+
+	if err := im.jobs.insert(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// run imports the rows and stores the progress of the job along the way.
+func (im *Importer) run(ctx context.Context, job *Job, rows []Row, principal *auth.Principal) {
+	logger := log.Ctx(ctx).With().Str("import_job_uuid", job.UUID).Logger()
+
+	err := im.process(ctx, job, rows, principal)
+
+	job.Status = StatusSucceeded
+	if err != nil {
+		logger.Error().Err(err).Msg("Fail to import products")
+
+		job.Status, job.Error = StatusFailed, err.Error()
+	}
+
+	finishedAt := im.now().UTC().Truncate(time.Second)
+	job.FinishedAt = &finishedAt
+
+	if err := im.jobs.update(ctx, job); err != nil {
+		logger.Error().Err(err).Msg("Fail to store import job")
+		return
+	}
+
+	logger.Info().
+		Int("processed", job.Processed).
+		Int("failed", job.Failed).
+		Str("status", string(job.Status)).
+		Msg("Import finished")
+}
+
+// process imports the rows, the errors of the rows are reported and the other ones stop the job.
+func (im *Importer) process(ctx context.Context, job *Job, rows []Row, principal *auth.Principal) error {
+	sellers, err := im.findSellers(ctx, rows)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		if im.interrupted.Load() {
+			return errInterrupted
+		}
+
+		created, rowErr, err := im.importRow(ctx, job, row, sellers, principal)
+		if err != nil {
+			return fmt.Errorf("fail to import line %d: %w", row.Line, err)
+		}
+
+		switch {
+		case rowErr != nil:
+			job.Failed++
+			job.Errors = append(job.Errors, *rowErr)
+		case job.DryRun:
+		case created:
+			job.Created++
+		default:
+			job.Updated++
+		}
+
+		job.Processed++
+
+		if job.Processed%progressEvery == 0 && job.Processed < job.Total {
+			if err := im.jobs.update(ctx, job); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// importRow imports the row unless it is invalid, whose error is returned as the error of the row.
+func (im *Importer) importRow(
+	ctx context.Context,
+	job *Job,
+	row Row,
+	sellers map[string]bool,
+	principal *auth.Principal,
+) (bool, *RowError, error) {
+	if row.Err != nil {
+		return false, row.Err, nil
+	}
+
+	if rowErr := row.validate(job.Upsert); rowErr != nil {
+		return false, rowErr, nil
+	}
+
+	sellerErr := &RowError{Line: row.Line, ExternalID: row.ExternalID, Field: "seller"}
+
+	if principal != nil && !principal.MayModify(row.SellerUUID) {
+		sellerErr.Error = "products of the seller may not be modified"
+		return false, sellerErr, nil
+	}
+
+	if !sellers[row.SellerUUID] {
+		sellerErr.Error = "seller is not found"
+		return false, sellerErr, nil
+	}
+
+	if job.DryRun {
+		return false, nil, nil
+	}
+
+	p := &product.Product{
+		Name:       row.Name,
+		Brand:      row.Brand,
+		Stock:      row.Stock,
+		SellerUUID: row.SellerUUID,
+		ExternalID: row.ExternalID,
+	}
+
+	var (
+		created = true
+		err     error
+	)
+
+	if job.Upsert {
+		created, err = im.products.Upsert(ctx, p)
+	} else {
+		_, err = im.products.Create(ctx, p)
+	}
+
+	switch {
+	case errors.Is(err, storage.ErrForeignKey):
+		// NOTE - the seller is deleted in the meantime.
+		sellerErr.Error = "seller is not found"
+		return false, sellerErr, nil
+	case errors.Is(err, storage.ErrConflict):
+		return false, &RowError{Line: row.Line, ExternalID: row.ExternalID, Field: "external_id", Error: "already exists"}, nil
+	}
+
+	return created, nil, err
+}
+
+// findSellers returns the UUIDs of the sellers of the rows which exist.
+func (im *Importer) findSellers(ctx context.Context, rows []Row) (map[string]bool, error) {
+	var uuids []string
+
+	seen := map[string]bool{}
+
+	for _, row := range rows {
+		if row.SellerUUID != "" && !seen[row.SellerUUID] {
+			seen[row.SellerUUID] = true
+			uuids = append(uuids, row.SellerUUID)
+		}
+	}
+
+	found := make(map[string]bool, len(uuids))
+
+	for start := 0; start < len(uuids); start += sellersBatch {
+		end := start + sellersBatch
+		if end > len(uuids) {
+			end = len(uuids)
+		}
+
+		sellers, err := im.sellers.FindByUUIDs(ctx, uuids[start:end])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, s := range sellers {
+			found[s.UUID] = true
+		}
+	}
+
+	return found, nil
+}
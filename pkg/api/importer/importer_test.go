@@ -0,0 +1,332 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"coding-challenge-go/pkg/api/product"
+	"coding-challenge-go/pkg/api/seller"
+	"coding-challenge-go/pkg/auth"
+	"coding-challenge-go/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// ProductWriterMock is a mock type for the ProductWriter type
+type ProductWriterMock struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: ctx, _a1
+func (_m *ProductWriterMock) Create(ctx context.Context, _a1 *product.Product) (*product.Product, error) {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 *product.Product
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*product.Product)
+	}
+
+	return r0, ret.Error(1)
+}
+
+// Upsert provides a mock function with given fields: ctx, _a1
+func (_m *ProductWriterMock) Upsert(ctx context.Context, _a1 *product.Product) (bool, error) {
+	ret := _m.Called(ctx, _a1)
+
+	return ret.Bool(0), ret.Error(1)
+}
+
+// SellerFinderMock is a mock type for the SellerFinder type
+type SellerFinderMock struct {
+	mock.Mock
+}
+
+// FindByUUIDs provides a mock function with given fields: ctx, uuids
+func (_m *SellerFinderMock) FindByUUIDs(ctx context.Context, uuids []string) ([]*seller.Seller, error) {
+	ret := _m.Called(ctx, uuids)
+
+	var r0 []*seller.Seller
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).([]*seller.Seller)
+	}
+
+	return r0, ret.Error(1)
+}
+
+// JobStoreMock is a mock type for the JobStore type
+type JobStoreMock struct {
+	mock.Mock
+}
+
+// insert provides a mock function with given fields: ctx, job
+func (_m *JobStoreMock) insert(ctx context.Context, job *Job) error {
+	return _m.Called(ctx, job).Error(0)
+}
+
+// update provides a mock function with given fields: ctx, job
+func (_m *JobStoreMock) update(ctx context.Context, job *Job) error {
+	return _m.Called(ctx, job).Error(0)
+}
+
+// findByUUID provides a mock function with given fields: ctx, uuid
+func (_m *JobStoreMock) findByUUID(ctx context.Context, uuid string) (*Job, error) {
+	ret := _m.Called(ctx, uuid)
+
+	var r0 *Job
+	if ret.Get(0) != nil {
+		r0 = ret.Get(0).(*Job)
+	}
+
+	return r0, ret.Error(1)
+}
+
+const otherSellerUUID = "e2f2c2e5-6aa0-4bbb-8b4b-3a9b4d8e0a42"
+
+func newJobStore() *JobStoreMock {
+	jobs := new(JobStoreMock)
+	jobs.On("insert", mock.Anything, mock.Anything).Return(nil)
+	jobs.On("update", mock.Anything, mock.Anything).Return(nil)
+
+	return jobs
+}
+
+func newSellerFinder() *SellerFinderMock {
+	sellers := new(SellerFinderMock)
+	sellers.On("FindByUUIDs", mock.Anything, mock.Anything).Return([]*seller.Seller{{UUID: sellerUUID}}, nil)
+
+	return sellers
+}
+
+func TestImporter_Run(t *testing.T) {
+	rows := []Row{
+		{Line: 2, ExternalID: "sku-1", Name: "Shoes", Brand: "Nike", Stock: 1, SellerUUID: sellerUUID},
+		{Line: 3, ExternalID: "sku-2", Name: "Shirt", Brand: "Zara", Stock: -1, SellerUUID: sellerUUID},
+		{Line: 4, ExternalID: "sku-3", Name: "Hat", Brand: "Gap", SellerUUID: otherSellerUUID},
+		{Line: 5, Err: &RowError{Line: 5, Error: "expected 5 fields, got 2"}},
+		{Line: 6, ExternalID: "sku-4", Name: "Cap", Brand: "Gap", SellerUUID: sellerUUID},
+		{Line: 7, ExternalID: "sku-5", Name: "Bag", Brand: "Gap", SellerUUID: sellerUUID},
+	}
+
+	products := new(ProductWriterMock)
+	products.On("Create", mock.Anything, &product.Product{Name: "Shoes", Brand: "Nike", Stock: 1, SellerUUID: sellerUUID, ExternalID: "sku-1"}).
+		Return(&product.Product{}, nil)
+	products.On("Create", mock.Anything, &product.Product{Name: "Cap", Brand: "Gap", SellerUUID: sellerUUID, ExternalID: "sku-4"}).
+		Return(nil, storage.ErrConflict)
+	products.On("Create", mock.Anything, &product.Product{Name: "Bag", Brand: "Gap", SellerUUID: sellerUUID, ExternalID: "sku-5"}).
+		Return(nil, storage.ErrForeignKey)
+
+	sellers := newSellerFinder()
+	jobs := newJobStore()
+
+	job, err := NewImporter(products, sellers, jobs).Run(context.Background(), rows, Options{Format: FormatCSV})
+	assert.NoError(t, err)
+
+	assert.Equal(t, StatusSucceeded, job.Status)
+	assert.Equal(t, FormatCSV, job.Format)
+	assert.Equal(t, 6, job.Total)
+	assert.Equal(t, 6, job.Processed)
+	assert.Equal(t, 1, job.Created)
+	assert.Equal(t, 0, job.Updated)
+	assert.Equal(t, 5, job.Failed)
+	assert.NotNil(t, job.FinishedAt)
+	assert.Equal(t, []RowError{
+		{Line: 3, ExternalID: "sku-2", Field: "stock", Error: "must not be negative"},
+		{Line: 4, ExternalID: "sku-3", Field: "seller", Error: "seller is not found"},
+		{Line: 5, Error: "expected 5 fields, got 2"},
+		{Line: 6, ExternalID: "sku-4", Field: "external_id", Error: "already exists"},
+		{Line: 7, ExternalID: "sku-5", Field: "seller", Error: "seller is not found"},
+	}, job.Errors)
+
+	sellers.AssertCalled(t, "FindByUUIDs", mock.Anything, []string{sellerUUID, otherSellerUUID})
+	products.AssertExpectations(t)
+	jobs.AssertNumberOfCalls(t, "insert", 1)
+	jobs.AssertNumberOfCalls(t, "update", 1)
+}
+
+func TestImporter_RunUpsert(t *testing.T) {
+	rows := []Row{
+		{Line: 1, ExternalID: "sku-1", Name: "Shoes", Brand: "Nike", SellerUUID: sellerUUID},
+		{Line: 2, ExternalID: "sku-2", Name: "Shirt", Brand: "Zara", SellerUUID: sellerUUID},
+		{Line: 3, Name: "Hat", Brand: "Gap", SellerUUID: sellerUUID},
+	}
+
+	products := new(ProductWriterMock)
+	products.On("Upsert", mock.Anything, mock.MatchedBy(func(p *product.Product) bool { return p.ExternalID == "sku-1" })).
+		Return(true, nil)
+	products.On("Upsert", mock.Anything, mock.MatchedBy(func(p *product.Product) bool { return p.ExternalID == "sku-2" })).
+		Return(false, nil)
+
+	job, err := NewImporter(products, newSellerFinder(), newJobStore()).
+		Run(context.Background(), rows, Options{Format: FormatNDJSON, Upsert: true})
+	assert.NoError(t, err)
+
+	assert.Equal(t, StatusSucceeded, job.Status)
+	assert.Equal(t, 1, job.Created)
+	assert.Equal(t, 1, job.Updated)
+	assert.Equal(t, []RowError{{Line: 3, Field: "external_id", Error: "is required by upsert"}}, job.Errors)
+	products.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestImporter_RunDryRun(t *testing.T) {
+	rows := []Row{
+		{Line: 1, Name: "Shoes", Brand: "Nike", SellerUUID: sellerUUID},
+		{Line: 2, Name: "Shirt", Brand: "Zara", SellerUUID: otherSellerUUID},
+	}
+
+	products := new(ProductWriterMock)
+
+	job, err := NewImporter(products, newSellerFinder(), newJobStore()).
+		Run(context.Background(), rows, Options{DryRun: true})
+	assert.NoError(t, err)
+
+	assert.Equal(t, StatusSucceeded, job.Status)
+	assert.Equal(t, 2, job.Processed)
+	assert.Equal(t, 0, job.Created)
+	assert.Equal(t, 1, job.Failed)
+	products.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestImporter_RunPrincipal(t *testing.T) {
+	rows := []Row{
+		{Line: 1, Name: "Shoes", Brand: "Nike", SellerUUID: sellerUUID},
+		{Line: 2, Name: "Shirt", Brand: "Zara", SellerUUID: otherSellerUUID},
+	}
+
+	products := new(ProductWriterMock)
+	products.On("Create", mock.Anything, mock.Anything).Return(&product.Product{}, nil)
+
+	sellers := new(SellerFinderMock)
+	sellers.On("FindByUUIDs", mock.Anything, mock.Anything).
+		Return([]*seller.Seller{{UUID: sellerUUID}, {UUID: otherSellerUUID}}, nil)
+
+	principal := &auth.Principal{ID: "apikey:1", Name: "shop", Scopes: []auth.Scope{auth.ScopeProductsWrite}, SellerUUID: sellerUUID}
+
+	job, err := NewImporter(products, sellers, newJobStore()).
+		Run(context.Background(), rows, Options{Principal: principal})
+	assert.NoError(t, err)
+
+	assert.Equal(t, "apikey:1", job.Principal)
+	assert.Equal(t, 1, job.Created)
+	assert.Equal(t, []RowError{{Line: 2, Field: "seller", Error: "products of the seller may not be modified"}}, job.Errors)
+	products.AssertNumberOfCalls(t, "Create", 1)
+}
+
+func TestImporter_RunFailure(t *testing.T) {
+	rows := make([]Row, 0, 250)
+	for i := 0; i < 250; i++ {
+		rows = append(rows, Row{Line: i + 1, Name: fmt.Sprintf("product %d", i), Brand: "Nike", SellerUUID: sellerUUID})
+	}
+
+	products := new(ProductWriterMock)
+	products.On("Create", mock.Anything, mock.MatchedBy(func(p *product.Product) bool { return p.Name != "product 200" })).
+		Return(&product.Product{}, nil)
+	products.On("Create", mock.Anything, mock.MatchedBy(func(p *product.Product) bool { return p.Name == "product 200" })).
+		Return(nil, errors.New("any sql error"))
+
+	jobs := newJobStore()
+
+	job, err := NewImporter(products, newSellerFinder(), jobs).Run(context.Background(), rows, Options{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, StatusFailed, job.Status)
+	assert.Equal(t, "fail to import line 201: any sql error", job.Error)
+	assert.Equal(t, 200, job.Processed)
+	assert.Equal(t, 200, job.Created)
+	// NOTE - the progress is stored after 100 and 200 rows, and the failed job at last.
+	jobs.AssertNumberOfCalls(t, "update", 3)
+}
+
+func TestImporter_RunSellersFailure(t *testing.T) {
+	sellers := new(SellerFinderMock)
+	sellers.On("FindByUUIDs", mock.Anything, mock.Anything).Return(nil, errors.New("any sql error"))
+
+	job, err := NewImporter(new(ProductWriterMock), sellers, newJobStore()).
+		Run(context.Background(), []Row{{Line: 1, Name: "Shoes", Brand: "Nike", SellerUUID: sellerUUID}}, Options{})
+	assert.NoError(t, err)
+
+	assert.Equal(t, StatusFailed, job.Status)
+	assert.Equal(t, 0, job.Processed)
+}
+
+func TestImporter_Start(t *testing.T) {
+	products := new(ProductWriterMock)
+	products.On("Create", mock.Anything, mock.Anything).Return(&product.Product{}, nil)
+
+	jobs := newJobStore()
+	im := NewImporter(products, newSellerFinder(), jobs)
+
+	started, err := im.Start(context.Background(), []Row{{Line: 1, Name: "Shoes", Brand: "Nike", SellerUUID: sellerUUID}}, Options{})
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRunning, started.Status)
+	assert.Equal(t, 1, started.Total)
+
+	assert.NoError(t, im.Wait(context.Background()))
+
+	finished := jobs.Calls[len(jobs.Calls)-1].Arguments.Get(1).(*Job)
+	assert.Equal(t, started.UUID, finished.UUID)
+	assert.Equal(t, StatusSucceeded, finished.Status)
+	assert.Equal(t, 1, finished.Created)
+
+	failing := new(JobStoreMock)
+	failing.On("insert", mock.Anything, mock.Anything).Return(errors.New("any sql error"))
+
+	_, err = NewImporter(products, newSellerFinder(), failing).Start(context.Background(), nil, Options{})
+	assert.Error(t, err)
+}
+
+func TestImporter_Wait_interrupted(t *testing.T) {
+	creating, release := make(chan struct{}), make(chan struct{})
+
+	products := new(ProductWriterMock)
+	products.On("Create", mock.Anything, mock.Anything).Return(&product.Product{}, nil).Run(func(mock.Arguments) {
+		creating <- struct{}{}
+		<-release
+	}).Once()
+
+	jobs := newJobStore()
+	im := NewImporter(products, newSellerFinder(), jobs)
+
+	rows := []Row{
+		{Line: 1, Name: "Shoes", Brand: "Nike", SellerUUID: sellerUUID},
+		{Line: 2, Name: "Shirt", Brand: "Puma", SellerUUID: sellerUUID},
+	}
+
+	_, err := im.Start(context.Background(), rows, Options{})
+	assert.NoError(t, err)
+	<-creating
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	waited := make(chan error)
+	go func() { waited <- im.Wait(ctx) }()
+
+	assert.Eventually(t, im.interrupted.Load, time.Second, time.Millisecond)
+	close(release)
+	assert.ErrorIs(t, <-waited, context.Canceled)
+
+	finished := jobs.Calls[len(jobs.Calls)-1].Arguments.Get(1).(*Job)
+	assert.Equal(t, StatusFailed, finished.Status)
+	assert.Equal(t, "interrupted by shutdown", finished.Error)
+	assert.Equal(t, 1, finished.Processed)
+	assert.NotNil(t, finished.FinishedAt)
+}
+
+func TestImporter_Job(t *testing.T) {
+	jobs := new(JobStoreMock)
+	jobs.On("findByUUID", mock.Anything, "found").Return(&Job{UUID: "found"}, nil)
+	jobs.On("findByUUID", mock.Anything, "missing").Return(nil, nil)
+
+	im := NewImporter(nil, nil, jobs)
+
+	got, err := im.Job(context.Background(), "found")
+	assert.NoError(t, err)
+	assert.Equal(t, &Job{UUID: "found"}, got)
+
+	_, err = im.Job(context.Background(), "missing")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
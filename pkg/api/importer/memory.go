@@ -0,0 +1,71 @@
+package importer
+
+import (
+	"context"
+	"sync"
+
+	"coding-challenge-go/pkg/storage"
+)
+
+// NewMemoryRepository builds the in-memory repository of the jobs, for the local development without
+// MySQL.
+func NewMemoryRepository() *memoryRepository {
+	return &memoryRepository{jobs: map[string]*Job{}}
+}
+
+// memoryRepository is the in-memory implementation of the JobStore, it stores copies of the jobs.
+type memoryRepository struct {
+	mu   sync.Mutex
+	jobs map[string]*Job
+}
+
+func (r *memoryRepository) insert(ctx context.Context, job *Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[job.UUID]; ok {
+		return storage.ErrDuplicateUUID
+	}
+
+	r.jobs[job.UUID] = copyJob(job)
+
+	return nil
+}
+
+func (r *memoryRepository) update(ctx context.Context, job *Job) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.jobs[job.UUID]; !ok {
+		return storage.ErrNotFound
+	}
+
+	r.jobs[job.UUID] = copyJob(job)
+
+	return nil
+}
+
+func (r *memoryRepository) findByUUID(ctx context.Context, uuid string) (*Job, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, ok := r.jobs[uuid]
+	if !ok {
+		return nil, nil
+	}
+
+	return copyJob(job), nil
+}
+
+// copyJob returns a copy of the job, so that the running job is not read while it is updated.
+func copyJob(job *Job) *Job {
+	copied := *job
+	copied.Errors = append([]RowError{}, job.Errors...)
+
+	if job.FinishedAt != nil {
+		finishedAt := *job.FinishedAt
+		copied.FinishedAt = &finishedAt
+	}
+
+	return &copied
+}
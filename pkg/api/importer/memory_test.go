@@ -0,0 +1,45 @@
+package importer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"coding-challenge-go/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryRepository(t *testing.T) {
+	ctx := context.Background()
+	r := NewMemoryRepository()
+
+	job := &Job{UUID: "e943dc0a-98bb-47b4-9d1d-056b95d3f064", Format: FormatCSV, Status: StatusRunning, Total: 2, CreatedAt: time.Now()}
+	assert.NoError(t, r.insert(ctx, job))
+	assert.ErrorIs(t, r.insert(ctx, job), storage.ErrDuplicateUUID)
+
+	found, err := r.findByUUID(ctx, job.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRunning, found.Status)
+	assert.Equal(t, []RowError{}, found.Errors)
+
+	finishedAt := time.Now()
+	job.Status, job.Processed, job.FinishedAt = StatusSucceeded, 2, &finishedAt
+	job.Errors = append(job.Errors, RowError{Line: 2, Field: "name", Error: "is required"})
+	assert.NoError(t, r.update(ctx, job))
+
+	// the stored job is a copy, it is not changed along with the running job.
+	job.Errors[0].Error = "changed"
+
+	found, err = r.findByUUID(ctx, job.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSucceeded, found.Status)
+	assert.Equal(t, 2, found.Processed)
+	assert.Equal(t, []RowError{{Line: 2, Field: "name", Error: "is required"}}, found.Errors)
+
+	assert.ErrorIs(t, r.update(ctx, &Job{UUID: "missing"}), storage.ErrNotFound)
+
+	found, err = r.findByUUID(ctx, "missing")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+}
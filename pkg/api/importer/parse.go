@@ -0,0 +1,254 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Format is the format of the imported file.
+type Format string
+
+const (
+	// FormatCSV is a CSV file whose header names the columns: external_id, name, brand, stock and seller.
+	FormatCSV Format = "csv"
+	// FormatNDJSON is a file of a JSON object per line, of the same fields as the CSV columns.
+	FormatNDJSON Format = "ndjson"
+)
+
+// FormatOf returns the format of the media type, e.g. of the Content-Type of the request.
+func FormatOf(mediaType string) (Format, bool) {
+	mediaType, _, _ = mime.ParseMediaType(mediaType)
+
+	switch mediaType {
+	case "text/csv":
+		return FormatCSV, true
+	case "application/x-ndjson", "application/ndjson":
+		return FormatNDJSON, true
+	}
+
+	return "", false
+}
+
+// ErrTooManyRows is returned by Parse when the file has more rows than the limit.
+var ErrTooManyRows = errors.New("importer: too many rows")
+
+// maxNDJSONLine bounds the lines of the NDJSON files.
+const maxNDJSONLine = 1 << 20
+
+// Row is a product of the imported file.
+type Row struct {
+	// Line is the line of the row in the file, the rows are reported by their lines.
+	Line       int    `json:"-"`
+	ExternalID string `json:"external_id"`
+	Name       string `json:"name"`
+	Brand      string `json:"brand"`
+	Stock      int    `json:"stock"`
+	SellerUUID string `json:"seller"`
+	// Err is the error of the row which can not be parsed.
+	Err *RowError `json:"-"`
+}
+
+// RowError is the error of a row of the report of the import.
+type RowError struct {
+	Line       int    `json:"line"`
+	ExternalID string `json:"external_id,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Error      string `json:"error"`
+}
+
+// Parse parses the rows of the file, at most maxRows of them. The rows which can not be parsed are
+// returned along with their errors, the file is rejected only when it can not be read at all.
+func Parse(r io.Reader, format Format, maxRows int) ([]Row, error) {
+	switch format {
+	case FormatCSV:
+		return parseCSV(r, maxRows)
+	case FormatNDJSON:
+		return parseNDJSON(r, maxRows)
+	}
+
+	return nil, fmt.Errorf("importer: unknown format %q", format)
+}
+
+var (
+	csvColumns         = map[string]bool{"external_id": true, "name": true, "brand": true, "stock": true, "seller": true}
+	csvRequiredColumns = []string{"name", "brand", "seller"}
+)
+
+func parseCSV(r io.Reader, maxRows int) ([]Row, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("importer: the CSV has no header")
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("importer: invalid CSV header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+
+	for i, column := range header {
+		column = strings.TrimSpace(strings.TrimPrefix(column, "\ufeff"))
+		if !csvColumns[column] {
+			return nil, fmt.Errorf("importer: unknown CSV column %q", column)
+		}
+
+		columns[column] = i
+	}
+
+	for _, column := range csvRequiredColumns {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("importer: the CSV has no %s column", column)
+		}
+	}
+
+	var rows []Row
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return rows, nil
+		}
+
+		if len(rows) == maxRows {
+			return nil, ErrTooManyRows
+		}
+
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			rows = append(rows, Row{Line: parseErr.StartLine, Err: &RowError{Line: parseErr.StartLine, Error: parseErr.Err.Error()}})
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, csvRow(line, header, columns, record))
+	}
+}
+
+func csvRow(line int, header []string, columns map[string]int, record []string) Row {
+	row := Row{Line: line}
+
+	if len(record) != len(header) {
+		row.Err = &RowError{Line: line, Error: fmt.Sprintf("expected %d fields, got %d", len(header), len(record))}
+
+		return row
+	}
+
+	field := func(column string) string {
+		if i, ok := columns[column]; ok {
+			return strings.TrimSpace(record[i])
+		}
+
+		return ""
+	}
+
+	row.ExternalID = field("external_id")
+	row.Name = field("name")
+	row.Brand = field("brand")
+	row.SellerUUID = field("seller")
+
+	if stock := field("stock"); stock != "" {
+		parsed, err := strconv.Atoi(stock)
+		if err != nil {
+			row.Err = &RowError{Line: line, ExternalID: row.ExternalID, Field: "stock", Error: "must be an integer"}
+
+			return row
+		}
+
+		row.Stock = parsed
+	}
+
+	return row
+}
+
+func parseNDJSON(r io.Reader, maxRows int) ([]Row, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxNDJSONLine)
+
+	var (
+		rows []Row
+		line int
+	)
+
+	for scanner.Scan() {
+		line++
+
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		if len(rows) == maxRows {
+			return nil, ErrTooManyRows
+		}
+
+		row := Row{}
+
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&row); err != nil {
+			row = Row{Err: &RowError{Line: line, Error: "invalid JSON: " + strings.TrimPrefix(err.Error(), "json: ")}}
+		}
+
+		row.Line = line
+		rows = append(rows, row)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("importer: invalid NDJSON: %w", err)
+	}
+
+	return rows, nil
+}
+
+// Field limits of the rows, as per the columns of the product table.
+const (
+	maxNameLength       = 200
+	maxExternalIDLength = 100
+)
+
+// validate returns the error of the fields of the row, nil when it is valid. The external ID is
+// required by the upserts.
+func (r Row) validate(upsert bool) *RowError {
+	invalid := func(field, message string) *RowError {
+		return &RowError{Line: r.Line, ExternalID: r.ExternalID, Field: field, Error: message}
+	}
+
+	switch {
+	case upsert && r.ExternalID == "":
+		return invalid("external_id", "is required by upsert")
+	case utf8.RuneCountInString(r.ExternalID) > maxExternalIDLength:
+		return invalid("external_id", fmt.Sprintf("must be at most %d characters", maxExternalIDLength))
+	case r.Name == "":
+		return invalid("name", "is required")
+	case utf8.RuneCountInString(r.Name) > maxNameLength:
+		return invalid("name", fmt.Sprintf("must be at most %d characters", maxNameLength))
+	case r.Brand == "":
+		return invalid("brand", "is required")
+	case utf8.RuneCountInString(r.Brand) > maxNameLength:
+		return invalid("brand", fmt.Sprintf("must be at most %d characters", maxNameLength))
+	case r.Stock < 0:
+		return invalid("stock", "must not be negative")
+	case r.SellerUUID == "":
+		return invalid("seller", "is required")
+	}
+
+	return nil
+}
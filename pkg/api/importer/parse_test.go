@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sellerUUID = "d1e1b1d4-5ff9-4aaa-9a3a-2f8a3c7d9f31"
+
+func TestFormatOf(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		want      Format
+		wantOK    bool
+	}{
+		{"text/csv", FormatCSV, true},
+		{"text/csv; charset=utf-8", FormatCSV, true},
+		{"application/x-ndjson", FormatNDJSON, true},
+		{"application/ndjson", FormatNDJSON, true},
+		{"application/json", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		got, ok := FormatOf(tt.mediaType)
+		assert.Equal(t, tt.want, got, tt.mediaType)
+		assert.Equal(t, tt.wantOK, ok, tt.mediaType)
+	}
+}
+
+func TestParse_CSV(t *testing.T) {
+	file := "\ufeffexternal_id,name,brand,stock,seller\n" +
+		"sku-1,Shoes,Nike,10," + sellerUUID + "\n" +
+		"sku-2, Shirt ,Zara,,\n" +
+		"sku-3,Hat,Gap,many," + sellerUUID + "\n" +
+		"sku-4,Hat\n" +
+		"sku-5,\"Cap,Gap,1," + sellerUUID + "\n"
+
+	got, err := Parse(strings.NewReader(file), FormatCSV, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []Row{
+		{Line: 2, ExternalID: "sku-1", Name: "Shoes", Brand: "Nike", Stock: 10, SellerUUID: sellerUUID},
+		{Line: 3, ExternalID: "sku-2", Name: "Shirt", Brand: "Zara"},
+		{Line: 4, ExternalID: "sku-3", Name: "Hat", Brand: "Gap", SellerUUID: sellerUUID,
+			Err: &RowError{Line: 4, ExternalID: "sku-3", Field: "stock", Error: "must be an integer"}},
+		{Line: 5, Err: &RowError{Line: 5, Error: "expected 5 fields, got 2"}},
+		{Line: 6, Err: &RowError{Line: 6, Error: "extraneous or missing \" in quoted-field"}},
+	}, got)
+}
+
+func TestParse_CSVHeader(t *testing.T) {
+	tests := []struct {
+		file    string
+		wantErr string
+	}{
+		{"", "importer: the CSV has no header"},
+		{"name,brand,seller,color\n", `importer: unknown CSV column "color"`},
+		{"name,brand\n", "importer: the CSV has no seller column"},
+	}
+
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.file), FormatCSV, 10)
+		assert.EqualError(t, err, tt.wantErr)
+	}
+
+	got, err := Parse(strings.NewReader("seller,brand,name\n"), FormatCSV, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestParse_NDJSON(t *testing.T) {
+	file := `{"external_id":"sku-1","name":"Shoes","brand":"Nike","stock":10,"seller":"` + sellerUUID + `"}` + "\n" +
+		"\n" +
+		`{"name":"Shirt","brand":"Zara","color":"red"}` + "\n" +
+		`{"name":"Hat",` + "\n"
+
+	got, err := Parse(strings.NewReader(file), FormatNDJSON, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []Row{
+		{Line: 1, ExternalID: "sku-1", Name: "Shoes", Brand: "Nike", Stock: 10, SellerUUID: sellerUUID},
+		{Line: 3, Err: &RowError{Line: 3, Error: `invalid JSON: unknown field "color"`}},
+		{Line: 4, Err: &RowError{Line: 4, Error: "invalid JSON: unexpected EOF"}},
+	}, got)
+}
+
+func TestParse_TooManyRows(t *testing.T) {
+	_, err := Parse(strings.NewReader("name,brand,seller\na,b,c\nd,e,f\n"), FormatCSV, 1)
+	assert.ErrorIs(t, err, ErrTooManyRows)
+
+	_, err = Parse(strings.NewReader("{}\n{}\n"), FormatNDJSON, 1)
+	assert.ErrorIs(t, err, ErrTooManyRows)
+
+	got, err := Parse(strings.NewReader("{}\n\n"), FormatNDJSON, 1)
+	assert.NoError(t, err)
+	assert.Len(t, got, 1)
+}
+
+func TestRow_validate(t *testing.T) {
+	valid := Row{Line: 2, ExternalID: "sku-1", Name: "Shoes", Brand: "Nike", Stock: 1, SellerUUID: sellerUUID}
+
+	tests := []struct {
+		name   string
+		modify func(r *Row)
+		upsert bool
+		want   *RowError
+	}{
+		{"valid", func(r *Row) {}, false, nil},
+		{"valid upsert", func(r *Row) {}, true, nil},
+		{"no external ID", func(r *Row) { r.ExternalID = "" }, false, nil},
+		{"upsert without external ID", func(r *Row) { r.ExternalID = "" }, true,
+			&RowError{Line: 2, Field: "external_id", Error: "is required by upsert"}},
+		{"long external ID", func(r *Row) { r.ExternalID = strings.Repeat("x", 101) }, false,
+			&RowError{Line: 2, ExternalID: strings.Repeat("x", 101), Field: "external_id", Error: "must be at most 100 characters"}},
+		{"no name", func(r *Row) { r.Name = "" }, false,
+			&RowError{Line: 2, ExternalID: "sku-1", Field: "name", Error: "is required"}},
+		{"long name", func(r *Row) { r.Name = strings.Repeat("é", 201) }, false,
+			&RowError{Line: 2, ExternalID: "sku-1", Field: "name", Error: "must be at most 200 characters"}},
+		{"name of 200 runes", func(r *Row) { r.Name = strings.Repeat("é", 200) }, false, nil},
+		{"no brand", func(r *Row) { r.Brand = "" }, false,
+			&RowError{Line: 2, ExternalID: "sku-1", Field: "brand", Error: "is required"}},
+		{"negative stock", func(r *Row) { r.Stock = -1 }, false,
+			&RowError{Line: 2, ExternalID: "sku-1", Field: "stock", Error: "must not be negative"}},
+		{"no seller", func(r *Row) { r.SellerUUID = "" }, false,
+			&RowError{Line: 2, ExternalID: "sku-1", Field: "seller", Error: "is required"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := valid
+			tt.modify(&row)
+			assert.Equal(t, tt.want, row.validate(tt.upsert))
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"coding-challenge-go/pkg/storage"
+	"coding-challenge-go/pkg/tracing"
+)
+
+// NewRepository builds the DB repository of the jobs.
+func NewRepository(db *sql.DB, queryTimeout time.Duration) *repository {
+	dialect := storage.DialectOf(db)
+
+	return &repository{db: dialect.Bind(db), dialect: dialect, queryTimeout: queryTimeout}
+}
+
+// repository is the DB implementation of the JobStore, the errors of the rows are stored as JSON.
+type repository struct {
+	db      storage.DBTX
+	dialect *storage.Dialect
+	// queryTimeout bounds every query, it is not bounded when it is not positive.
+	queryTimeout time.Duration
+}
+
+func (r *repository) insert(ctx context.Context, job *Job) (err error) {
+	ctx, span := tracing.Start(ctx, "importer.repository.insert", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	_, err = r.db.ExecContext(
+		ctx,
+		"INSERT INTO import_job (uuid, principal, format, dry_run, upsert, status, total, created_at) VALUES(?,?,?,?,?,?,?,?)",
+		job.UUID, job.Principal, job.Format, job.DryRun, job.Upsert, job.Status, job.Total, job.CreatedAt,
+	)
+
+	return storage.FromDB(err)
+}
+
+func (r *repository) update(ctx context.Context, job *Job) (err error) {
+	ctx, span := tracing.Start(ctx, "importer.repository.update", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	rowErrors, err := json.Marshal(job.Errors)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE import_job SET status = ?, processed = ?, created = ?, updated = ?, failed = ?, errors = ?, error = ?, "+
+			"finished_at = ? WHERE uuid = ?",
+		job.Status, job.Processed, job.Created, job.Updated, job.Failed, rowErrors, nullString(job.Error),
+		job.FinishedAt, job.UUID,
+	)
+
+	if err != nil {
+		return storage.FromDB(err)
+	}
+
+	return storage.ExpectAffected(result)
+}
+
+func (r *repository) findByUUID(ctx context.Context, uuid string) (_ *Job, err error) {
+	ctx, span := tracing.Start(ctx, "importer.repository.findByUUID", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT uuid, principal, format, dry_run, upsert, status, total, processed, created, updated, failed, "+
+			"errors, error, created_at, finished_at FROM import_job WHERE uuid = ?",
+		uuid,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var (
+		job        = &Job{}
+		rowErrors  []byte
+		jobErr     sql.NullString
+		finishedAt sql.NullTime
+	)
+
+	err = rows.Scan(
+		&job.UUID, &job.Principal, &job.Format, &job.DryRun, &job.Upsert, &job.Status, &job.Total, &job.Processed,
+		&job.Created, &job.Updated, &job.Failed, &rowErrors, &jobErr, &job.CreatedAt, &finishedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	job.Errors = []RowError{}
+
+	if len(rowErrors) > 0 {
+		if err := json.Unmarshal(rowErrors, &job.Errors); err != nil {
+			return nil, fmt.Errorf("importer.repository.findByUUID: invalid errors: %w", err)
+		}
+	}
+
+	job.Error = jobErr.String
+
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+
+	return job, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,97 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"coding-challenge-go/pkg/storage"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_insert(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	createdAt := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+
+	m.ExpectExec("INSERT INTO import_job").
+		WithArgs("job-1", "shop", FormatCSV, true, false, StatusRunning, 3, createdAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	r := NewRepository(db, 0)
+
+	err := r.insert(context.Background(), &Job{UUID: "job-1", Principal: "shop", Format: FormatCSV, DryRun: true,
+		Status: StatusRunning, Total: 3, CreatedAt: createdAt})
+	assert.NoError(t, err)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRepository_update(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	finishedAt := time.Date(2026, 10, 19, 10, 1, 0, 0, time.UTC)
+
+	m.ExpectExec("UPDATE import_job SET").
+		WithArgs(StatusFailed, 2, 1, 0, 1, []byte(`[{"line":3,"field":"stock","error":"must not be negative"}]`),
+			"any sql error", &finishedAt, "job-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	m.ExpectExec("UPDATE import_job SET").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	r := NewRepository(db, 0)
+
+	job := &Job{UUID: "job-1", Status: StatusFailed, Processed: 2, Created: 1, Failed: 1,
+		Errors: []RowError{{Line: 3, Field: "stock", Error: "must not be negative"}}, Error: "any sql error",
+		FinishedAt: &finishedAt}
+
+	assert.NoError(t, r.update(context.Background(), job))
+	assert.ErrorIs(t, r.update(context.Background(), job), storage.ErrNotFound)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRepository_findByUUID(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	createdAt := time.Date(2026, 10, 19, 10, 0, 0, 0, time.UTC)
+	finishedAt := createdAt.Add(time.Minute)
+
+	columns := []string{"uuid", "principal", "format", "dry_run", "upsert", "status", "total", "processed", "created",
+		"updated", "failed", "errors", "error", "created_at", "finished_at"}
+
+	m.ExpectQuery("SELECT (.+) FROM import_job WHERE uuid = ?").WithArgs("job-1").WillReturnRows(
+		sqlmock.NewRows(columns).AddRow("job-1", "shop", "ndjson", false, true, "succeeded", 2, 2, 1, 1, 0,
+			[]byte(`[]`), nil, createdAt, finishedAt),
+	)
+	m.ExpectQuery("SELECT (.+) FROM import_job WHERE uuid = ?").WithArgs("job-2").WillReturnRows(
+		sqlmock.NewRows(columns).AddRow("job-2", "", "csv", false, false, "running", 2, 0, 0, 0, 0,
+			nil, nil, createdAt, nil),
+	)
+	m.ExpectQuery("SELECT (.+) FROM import_job WHERE uuid = ?").WithArgs("missing").WillReturnRows(sqlmock.NewRows(columns))
+	m.ExpectQuery("SELECT (.+) FROM import_job WHERE uuid = ?").WithArgs("failing").WillReturnError(errors.New("any sql error"))
+
+	r := NewRepository(db, 0)
+
+	got, err := r.findByUUID(context.Background(), "job-1")
+	assert.NoError(t, err)
+	assert.Equal(t, &Job{UUID: "job-1", Principal: "shop", Format: FormatNDJSON, Upsert: true, Status: StatusSucceeded,
+		Total: 2, Processed: 2, Created: 1, Updated: 1, Errors: []RowError{}, CreatedAt: createdAt,
+		FinishedAt: &finishedAt}, got)
+
+	got, err = r.findByUUID(context.Background(), "job-2")
+	assert.NoError(t, err)
+	assert.Equal(t, &Job{UUID: "job-2", Format: FormatCSV, Status: StatusRunning, Total: 2, Errors: []RowError{},
+		CreatedAt: createdAt}, got)
+
+	got, err = r.findByUUID(context.Background(), "missing")
+	assert.NoError(t, err)
+	assert.Nil(t, got)
+
+	_, err = r.findByUUID(context.Background(), "failing")
+	assert.Error(t, err)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// AccessLog is a middleware which logs every served request with the contextual logger.
+func AccessLog(c *gin.Context) {
+	start := time.Now()
+
+	c.Next()
+
+	log.Ctx(c.Request.Context()).Info().
+		Str("path", c.Request.URL.Path).
+		Int("status", c.Writer.Status()).
+		Int("size", c.Writer.Size()).
+		Str("client_ip", clientIP(c)).
+		Dur("latency", time.Since(start)).
+		Msg("Request served")
+}
@@ -1,26 +1,179 @@
 package middleware
 
 import (
+	"mime"
 	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
+	"coding-challenge-go/pkg/api/apierror"
+	"coding-challenge-go/pkg/auth"
+	"coding-challenge-go/pkg/logging"
+
 	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// HeaderAPIVersion is the request header selecting the version on unversioned routes,
+// it is also set in every response to tell the version which served it.
+const HeaderAPIVersion = "API-Version"
+
+var (
+	// APIVersionResolver resolves the version by the registry of v1, v2 and v3, none of them deprecated,
+	// see NewAPIVersionResolver.
+	APIVersionResolver = NewAPIVersionResolver(func() *VersionRegistry {
+		r, _ := NewVersionRegistry("v2", Version{Name: "v1"}, Version{Name: "v2"}, Version{Name: "v3"})
+		return r
+	}())
+
+	// pathVersion matches the version segment of the versioned routes, e.g. /api/v2/products.
+	pathVersion = regexp.MustCompile(`^v\d+$`)
+	// vendorMediaType matches the vendor media types, e.g. application/vnd.gfg.product+json.
+	vendorMediaType = regexp.MustCompile(`^application/vnd\.gfg(\.[a-z0-9\-]+)?\+json$`)
 )
 
-// APIVersionResolver is a middleware which resolves the api version the request
+// NewAPIVersionResolver builds a middleware which resolves the api version the request
 // is coming for.
 //
-// It puts the version in context which can be accessed by next handlers.
-func APIVersionResolver(c *gin.Context) {
+// The version is taken from the path of the versioned routes (/api/v2/products), the unversioned
+// routes (/api/products) negotiate it by the version parameter of the vendor media type in Accept
+// header (application/vnd.gfg.product+json;version=2) or by the API-Version header, in this order,
+// and fall back to the default version of the registry. A version which is not registered is
+// responded with 406.
+//
+// The deprecated versions are signalled by Deprecation, Sunset and Link headers and their usage
+// is counted per client; a version rejected after its sunset is responded with 410.
+//
+// It puts the version in context which can be accessed by next handlers,
+// and adds it to the contextual logger of the request.
+func NewAPIVersionResolver(registry *VersionRegistry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resolveAPIVersion(c, registry)
+	}
+}
+
+// resolveAPIVersion is the APIVersionResolver middleware of the registry.
+func resolveAPIVersion(c *gin.Context, registry *VersionRegistry) {
 	pathComponents := strings.Split(c.FullPath(), "/")
 
-	// FullPath will be /api/version/subPath
+	// FullPath will be /api/version/subPath or /api/subPath, it is empty when no route matches.
 	if pathComponents == nil || len(pathComponents) < 3 {
-		c.JSON(http.StatusNotFound, gin.H{"error": "invalid path requested"})
+		// the version of the requested path decides the representation of the error.
+		if requested := strings.Split(c.Request.URL.Path, "/"); len(requested) >= 3 {
+			c.Set("version", requested[2])
+		}
+
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeRouteNotFound, "invalid path requested")
+		return
+	}
+
+	version := pathComponents[2]
+	negotiated := !pathVersion.MatchString(version)
+
+	if negotiated {
+		// the representation of unversioned routes differs by these headers, caches must know it.
+		c.Writer.Header().Add("Vary", "Accept")
+		c.Writer.Header().Add("Vary", HeaderAPIVersion)
+
+		version = negotiateVersion(c.Request, registry.defaultVersion)
+	}
+
+	v, registered := registry.Lookup(version)
+	if !registered && negotiated {
+		apierror.Respond(c, http.StatusNotAcceptable, apierror.CodeUnsupportedVersion, "unsupported API version "+version)
 		return
 	}
 
-	c.Set("version", pathComponents[2])
+	c.Set("version", version)
+	c.Header(HeaderAPIVersion, version)
+	c.Request = c.Request.WithContext(logging.WithStr(c.Request.Context(), "version", version))
+
+	now := registry.now()
+	if registered && v.rejected(now) {
+		apierror.Respond(c, http.StatusGone, apierror.CodeVersionSunset, "API version "+version+" is not served anymore")
+		return
+	}
+
+	deprecated := registered && v.deprecated(now)
+	if deprecated {
+		setDeprecationHeaders(c.Writer.Header(), v)
+	}
 
 	c.Next()
+
+	// NOTE - the usage is counted once the request is served, as the principal of the request is
+	// authenticated by the middlewares of the route.
+	if deprecated {
+		client := "ip:" + clientIP(c)
+		if principal := auth.PrincipalFrom(c.Request.Context()); principal != nil {
+			client = "principal:" + principal.ID
+		}
+
+		log.Ctx(c.Request.Context()).Warn().
+			Str("client", client).
+			Int64("count", registry.countUsage(version, client)).
+			Msg("Deprecated API version requested")
+	}
+}
+
+// negotiateVersion returns the version requested by the headers of the request.
+func negotiateVersion(r *http.Request, defaultVersion string) string {
+	if version := acceptedVersion(r.Header.Values("Accept")); version != "" {
+		return version
+	}
+
+	if version := r.Header.Get(HeaderAPIVersion); version != "" {
+		return normalizeVersion(version)
+	}
+
+	return defaultVersion
+}
+
+// acceptedVersion returns the version of the most preferred vendor media type with version parameter
+// in the Accept headers, it is empty when there is not any.
+func acceptedVersion(accept []string) string {
+	type candidate struct {
+		version string
+		q       float64
+	}
+
+	var candidates []candidate
+
+	for _, header := range accept {
+		for _, mediaRange := range strings.Split(header, ",") {
+			mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+			if err != nil || !vendorMediaType.MatchString(mediaType) || params["version"] == "" {
+				continue
+			}
+
+			q := 1.0
+			if value, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(value, 64); err != nil {
+					continue
+				}
+			}
+
+			candidates = append(candidates, candidate{version: normalizeVersion(params["version"]), q: q})
+		}
+	}
+
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	return candidates[0].version
+}
+
+// normalizeVersion returns the version as it is named in the paths, e.g. 2 -> v2.
+func normalizeVersion(version string) string {
+	version = strings.ToLower(strings.TrimSpace(version))
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+
+	return version
 }
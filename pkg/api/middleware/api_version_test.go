@@ -0,0 +1,120 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIVersionResolver(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		headers    map[string]string
+		expStatus  int
+		expBody    string
+		expVary    []string
+		expVersion string
+	}{
+		{
+			name:       "resolves the version of the route",
+			path:       "/api/v2/products",
+			expStatus:  http.StatusOK,
+			expBody:    "v2",
+			expVersion: "v2",
+		},
+		{
+			name:       "path version wins over headers",
+			path:       "/api/v1/products",
+			headers:    map[string]string{"Accept": "application/vnd.gfg.product+json;version=2"},
+			expStatus:  http.StatusOK,
+			expBody:    "v1",
+			expVersion: "v1",
+		},
+		{
+			name:       "unversioned: negotiates the version by vendor media type",
+			path:       "/api/products",
+			headers:    map[string]string{"Accept": "application/vnd.gfg.product+json;version=1"},
+			expStatus:  http.StatusOK,
+			expBody:    "v1",
+			expVary:    []string{"Accept", "API-Version"},
+			expVersion: "v1",
+		},
+		{
+			name:       "unversioned: prefers the vendor media type by quality",
+			path:       "/api/products",
+			headers:    map[string]string{"Accept": "application/vnd.gfg.product+json;version=1;q=0.5, application/vnd.gfg.product+json;version=2"},
+			expStatus:  http.StatusOK,
+			expBody:    "v2",
+			expVary:    []string{"Accept", "API-Version"},
+			expVersion: "v2",
+		},
+		{
+			name:       "unversioned: negotiates the version by API-Version header",
+			path:       "/api/products",
+			headers:    map[string]string{"Accept": "application/json", "API-Version": "1"},
+			expStatus:  http.StatusOK,
+			expBody:    "v1",
+			expVary:    []string{"Accept", "API-Version"},
+			expVersion: "v1",
+		},
+		{
+			name:       "unversioned: falls back to default version",
+			path:       "/api/products",
+			expStatus:  http.StatusOK,
+			expBody:    "v2",
+			expVary:    []string{"Accept", "API-Version"},
+			expVersion: "v2",
+		},
+		{
+			name:      "unversioned: responds 406 for unsupported version",
+			path:      "/api/products",
+			headers:   map[string]string{"API-Version": "9"},
+			expStatus: http.StatusNotAcceptable,
+			expBody:   `{"type":"https://api.gfg.com/problems/unsupported_version","title":"Not Acceptable","status":406,"detail":"unsupported API version v9","instance":"/api/products","code":"unsupported_version"}`,
+			expVary:   []string{"Accept", "API-Version"},
+		},
+		{
+			name:      "v1: responds legacy error for unknown route",
+			path:      "/api/v1/unknown",
+			expStatus: http.StatusNotFound,
+			expBody:   `{"error":"invalid path requested"}`,
+		},
+		{
+			name:      "v2: responds problem details for unknown route",
+			path:      "/api/v2/unknown",
+			expStatus: http.StatusNotFound,
+			expBody:   `{"type":"https://api.gfg.com/problems/route_not_found","title":"Not Found","status":404,"detail":"invalid path requested","instance":"/api/v2/unknown","code":"route_not_found"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(APIVersionResolver)
+			versionHandler := func(c *gin.Context) {
+				c.String(http.StatusOK, c.MustGet("version").(string))
+			}
+			r.GET("/api/v1/products", versionHandler)
+			r.GET("/api/v2/products", versionHandler)
+			r.GET("/api/products", versionHandler)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, tt.path, nil)
+			assert.NoError(t, err)
+
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expStatus, w.Code)
+			assert.Equal(t, tt.expBody, w.Body.String())
+			assert.Equal(t, tt.expVary, w.Header().Values("Vary"))
+			assert.Equal(t, tt.expVersion, w.Header().Get(HeaderAPIVersion))
+		})
+	}
+}
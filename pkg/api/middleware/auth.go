@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"coding-challenge-go/pkg/api/apierror"
+	"coding-challenge-go/pkg/auth"
+	"coding-challenge-go/pkg/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// HeaderAPIKey is the request header carrying the API key, the key may be sent
+// as Authorization: Bearer <key> too.
+const HeaderAPIKey = "X-API-Key"
+
+// APIKeyFinder finds the principal of an API key.
+type APIKeyFinder interface {
+	FindByKey(ctx context.Context, key string) (*auth.Principal, error)
+}
+
+// TokenVerifier verifies a bearer token and returns its principal.
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*auth.Principal, error)
+}
+
+// Authenticate builds a middleware which authenticates the request by its API key, or by its
+// JWT bearer token when the verifier is not nil. The requests without a valid credential are
+// responded with 401.
+//
+// It puts the principal in request context, which can be accessed by next handlers
+// with auth.PrincipalFrom, and adds its name to the contextual logger of the request.
+func Authenticate(finder APIKeyFinder, verifier TokenVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := credential(c.Request)
+		if key == "" {
+			unauthorized(c, "API key is required")
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		if verifier != nil && auth.IsJWT(key) {
+			principal, err := verifier.Verify(ctx, key)
+			if errors.Is(err, auth.ErrInvalidToken) {
+				log.Ctx(ctx).Info().Err(err).Msg("Token is rejected")
+				unauthorized(c, "Token is invalid")
+				return
+			}
+
+			if err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("Fail to verify token")
+				apierror.Respond(c, http.StatusServiceUnavailable, apierror.CodeServiceUnavailable, "Fail to authenticate")
+				return
+			}
+
+			authenticated(c, principal)
+			return
+		}
+
+		principal, err := finder.FindByKey(ctx, key)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Fail to query API key")
+			apierror.Respond(c, apierror.StatusCode(err), apierror.CodeOf(err), "Fail to authenticate")
+			return
+		}
+
+		if principal == nil {
+			unauthorized(c, "API key is invalid")
+			return
+		}
+
+		authenticated(c, principal)
+	}
+}
+
+// authenticated serves the request of the principal.
+func authenticated(c *gin.Context, principal *auth.Principal) {
+	ctx := auth.WithPrincipal(logging.WithStr(c.Request.Context(), "principal", principal.Name), principal)
+	c.Request = c.Request.WithContext(ctx)
+
+	c.Next()
+}
+
+// RequireScope builds a middleware which responds 403 to the principals without the scope.
+func RequireScope(scope auth.Scope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal := auth.PrincipalFrom(c.Request.Context())
+		if principal == nil {
+			unauthorized(c, "API key is required")
+			return
+		}
+
+		if !principal.HasScope(scope) {
+			apierror.Respond(c, http.StatusForbidden, apierror.CodeForbidden, "API key is not granted "+string(scope))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// credential returns the API key or the token of the request, it is empty when the request does not send any.
+func credential(r *http.Request) string {
+	if key := r.Header.Get(HeaderAPIKey); key != "" {
+		return key
+	}
+
+	const bearer = "Bearer "
+
+	if authorization := r.Header.Get("Authorization"); len(authorization) > len(bearer) &&
+		strings.EqualFold(authorization[:len(bearer)], bearer) {
+		return strings.TrimSpace(authorization[len(bearer):])
+	}
+
+	return ""
+}
+
+// unauthorized responds 401 with the challenge of the authentication scheme.
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer realm="product-api"`)
+	apierror.Respond(c, http.StatusUnauthorized, apierror.CodeUnauthorized, message)
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// KeyClientIP is the key of the IP of the client in the gin context.
+const KeyClientIP = "client_ip"
+
+// ClientIP builds a middleware which resolves the IP of the client of the request. It is the remote
+// address of the connection, unless the connection is of a trusted proxy: then the client is the
+// rightmost address of X-Forwarded-For which is not a trusted proxy. The forwarded headers of the
+// other connections are ignored, as they are spoofed at will.
+//
+// It puts the IP in context, which is used by the rate limits and the logs instead of
+// gin.Context.ClientIP, which trusts the forwarded headers of any client.
+func ClientIP(trustedProxies []netip.Prefix) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(KeyClientIP, resolveClientIP(c.Request, trustedProxies))
+		c.Next()
+	}
+}
+
+// clientIP returns the IP of the client resolved by ClientIP, the remote address of the connection
+// when it is not resolved.
+func clientIP(c *gin.Context) string {
+	if ip := c.GetString(KeyClientIP); ip != "" {
+		return ip
+	}
+
+	return remoteIP(c.Request)
+}
+
+func resolveClientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	ip := remoteIP(r)
+	if !trusted(ip, trustedProxies) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			break
+		}
+
+		ip = addr.Unmap().String()
+		if !trusted(ip, trustedProxies) {
+			break
+		}
+	}
+
+	return ip
+}
+
+// remoteIP returns the IP of the remote address of the connection.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(strings.TrimSpace(r.RemoteAddr))
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// trusted reports whether the IP is of one of the trusted proxies.
+func trusted(ip string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	for _, p := range trustedProxies {
+		if p.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+
+	return false
+}
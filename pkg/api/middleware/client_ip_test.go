@@ -0,0 +1,47 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientIP(t *testing.T) {
+	proxies := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{name: "remote address", remoteAddr: "192.0.2.1:1234", want: "192.0.2.1"},
+		{name: "ignores the forwarded address of an untrusted client", remoteAddr: "192.0.2.1:1234", forwarded: "198.51.100.1", want: "192.0.2.1"},
+		{name: "forwarded address of a trusted proxy", remoteAddr: "10.0.0.1:1234", forwarded: "198.51.100.1", want: "198.51.100.1"},
+		{name: "skips the trusted proxies of the chain", remoteAddr: "10.0.0.1:1234", forwarded: "203.0.113.9, 198.51.100.1, 10.0.0.2", want: "198.51.100.1"},
+		{name: "stops at an invalid forwarded address", remoteAddr: "10.0.0.1:1234", forwarded: "spoofed, 10.0.0.2", want: "10.0.0.2"},
+		{name: "trusted proxy without forwarded address", remoteAddr: "10.0.0.1:1234", want: "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(ClientIP(proxies))
+			r.GET("/", func(c *gin.Context) { c.String(http.StatusOK, clientIP(c)) })
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.want, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"coding-challenge-go/pkg/api/apierror"
+	"coding-challenge-go/pkg/auth"
+	"coding-challenge-go/pkg/idempotency"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// HeaderIdempotencyKey is the request header carrying the idempotency key of the request.
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed marks the responses which are replayed.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// validIdempotencyKey limits the accepted idempotency keys, e.g. UUIDs.
+var validIdempotencyKey = regexp.MustCompile(`^[\x21-\x7E]{1,255}$`)
+
+// replayedHeaders are the headers of the responses which are stored along with their bodies.
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// IdempotencyStore keeps the responses of the requests by their idempotency keys.
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*idempotency.Record, error)
+	Complete(ctx context.Context, key string, response *idempotency.Response) error
+	Release(ctx context.Context, key string) error
+}
+
+// Idempotency builds a middleware which makes the requests sending an Idempotency-Key safe to retry.
+// The response of the first request of the key is stored for the TTL, and it is replayed to the
+// retries of the request. The keys are scoped by the principal.
+//
+// The key may not be reused by another request, which is responded with 422, nor by a retry while
+// the request is in flight, which is responded with 409. The responses of the server errors and of
+// the panics are not stored, so that the request can be retried. The bodies of the requests sending a key are read
+// to be fingerprinted, the bodies larger than maxBodyBytes are responded with 413.
+func Idempotency(store IdempotencyStore, ttl time.Duration, maxBodyBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(HeaderIdempotencyKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if !validIdempotencyKey.MatchString(key) {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidRequest, "Idempotency-Key is invalid")
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		if principal := auth.PrincipalFrom(ctx); principal != nil {
+			key = principal.ID + "\x00" + key
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			apierror.Respond(c, http.StatusRequestEntityTooLarge, apierror.CodePayloadTooLarge, "Request is too large")
+			return
+		}
+
+		if err != nil {
+			apierror.Respond(c, http.StatusBadRequest, apierror.CodeInvalidRequest, "Fail to read request")
+			return
+		}
+
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		fp := fingerprint(c.Request, body)
+
+		record, err := store.Reserve(ctx, key, fp, ttl)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Fail to reserve idempotency key")
+			apierror.Respond(c, apierror.StatusCode(err), apierror.CodeOf(err), "Fail to reserve idempotency key")
+			return
+		}
+
+		if record != nil {
+			replay(c, record, fp)
+			return
+		}
+
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+
+		// NOTE - the request context may be canceled by the end of the request, the key is released
+		// or its response stored regardless.
+		ctx = context.WithoutCancel(ctx)
+
+		served := false
+
+		// NOTE - the key is released unless the request is served, e.g. when a handler panics, so that
+		// the request can be retried instead of being responded as in progress until the key expires.
+		defer func() {
+			if served {
+				return
+			}
+
+			if err := store.Release(ctx, key); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("Fail to release idempotency key")
+			}
+		}()
+
+		c.Next()
+
+		if w.Status() >= http.StatusInternalServerError {
+			return
+		}
+
+		served = true
+
+		response := &idempotency.Response{Status: w.Status(), Header: http.Header{}, Body: w.body.Bytes()}
+		for _, h := range replayedHeaders {
+			if v := w.Header().Get(h); v != "" {
+				response.Header.Set(h, v)
+			}
+		}
+
+		if err := store.Complete(ctx, key, response); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("Fail to store response of idempotency key")
+		}
+	}
+}
+
+// replay responds the stored response of the key, unless it is of another request or in flight.
+func replay(c *gin.Context, record *idempotency.Record, fingerprint string) {
+	if record.Fingerprint != fingerprint {
+		apierror.Respond(c, http.StatusUnprocessableEntity, apierror.CodeIdempotencyKeyReused,
+			"Idempotency-Key is already used by another request")
+		return
+	}
+
+	if record.Response == nil {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeIdempotencyKeyInUse,
+			"Request of the Idempotency-Key is in progress")
+		return
+	}
+
+	for h := range record.Response.Header {
+		c.Header(h, record.Response.Header.Get(h))
+	}
+
+	c.Header(HeaderIdempotentReplayed, "true")
+	c.Status(record.Response.Status)
+	c.Writer.WriteHeaderNow()
+	_, _ = c.Writer.Write(record.Response.Body)
+	c.Abort()
+}
+
+// fingerprint identifies the request by its method, URL and body.
+func fingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// recordingWriter records the body of the response along writing it.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+
+	return w.ResponseWriter.WriteString(s)
+}
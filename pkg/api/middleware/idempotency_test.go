@@ -0,0 +1,221 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"coding-challenge-go/pkg/auth"
+	"coding-challenge-go/pkg/idempotency"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// idempotencyStoreStub keeps the records in memory.
+type idempotencyStoreStub map[string]*idempotency.Record
+
+func (s idempotencyStoreStub) Reserve(_ context.Context, key, fingerprint string, _ time.Duration) (*idempotency.Record, error) {
+	if record, ok := s[key]; ok {
+		return record, nil
+	}
+
+	s[key] = &idempotency.Record{Fingerprint: fingerprint}
+
+	return nil, nil
+}
+
+func (s idempotencyStoreStub) Complete(_ context.Context, key string, response *idempotency.Response) error {
+	s[key].Response = response
+
+	return nil
+}
+
+func (s idempotencyStoreStub) Release(_ context.Context, key string) error {
+	delete(s, key)
+
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	type request struct {
+		key       string
+		body      string
+		principal string
+	}
+
+	tests := []struct {
+		name        string
+		requests    []request
+		expStatus   int
+		expBody     string
+		expReplayed bool
+		expCreated  int
+	}{
+		{
+			name:       "serves the request without key",
+			requests:   []request{{body: `{"name":"shoes"}`}, {body: `{"name":"shoes"}`}},
+			expStatus:  http.StatusCreated,
+			expBody:    `{"id":2}`,
+			expCreated: 2,
+		},
+		{
+			name:        "replays the response of the retried request",
+			requests:    []request{{key: "key", body: `{"name":"shoes"}`}, {key: "key", body: `{"name":"shoes"}`}},
+			expStatus:   http.StatusCreated,
+			expBody:     `{"id":1}`,
+			expReplayed: true,
+			expCreated:  1,
+		},
+		{
+			name:       "scopes the keys by the principal",
+			requests:   []request{{key: "key", body: `{}`, principal: "apikey:shop"}, {key: "key", body: `{}`, principal: "apikey:other"}},
+			expStatus:  http.StatusCreated,
+			expBody:    `{"id":2}`,
+			expCreated: 2,
+		},
+		{
+			name:       "scopes the keys by the way the principal is authenticated",
+			requests:   []request{{key: "key", body: `{}`, principal: "apikey:shop"}, {key: "key", body: `{}`, principal: "jwt:shop"}},
+			expStatus:  http.StatusCreated,
+			expBody:    `{"id":2}`,
+			expCreated: 2,
+		},
+		{
+			name:       "responds 422, when the key is reused by another request",
+			requests:   []request{{key: "key", body: `{"name":"shoes"}`}, {key: "key", body: `{"name":"boots"}`}},
+			expStatus:  http.StatusUnprocessableEntity,
+			expBody:    `{"type":"https://api.gfg.com/problems/idempotency_key_reused","title":"Unprocessable Entity","status":422,"detail":"Idempotency-Key is already used by another request","instance":"/api/v2/product","code":"idempotency_key_reused"}`,
+			expCreated: 1,
+		},
+		{
+			name:       "releases the key of the failed request, so that it can be retried",
+			requests:   []request{{key: "key", body: `fail`}, {key: "key", body: `fail`}},
+			expStatus:  http.StatusInternalServerError,
+			expBody:    `{}`,
+			expCreated: 0,
+		},
+		{
+			name:      "responds 413, when the body is too large to be fingerprinted",
+			requests:  []request{{key: "key", body: `{"name":"` + strings.Repeat("shoes", 20) + `"}`}},
+			expStatus: http.StatusRequestEntityTooLarge,
+			expBody:   `{"type":"https://api.gfg.com/problems/payload_too_large","title":"Request Entity Too Large","status":413,"detail":"Request is too large","instance":"/api/v2/product","code":"payload_too_large"}`,
+		},
+		{
+			name:      "responds 400, when the key is invalid",
+			requests:  []request{{key: "key with spaces", body: `{}`}},
+			expStatus: http.StatusBadRequest,
+			expBody:   `{"type":"https://api.gfg.com/problems/invalid_request","title":"Bad Request","status":400,"detail":"Idempotency-Key is invalid","instance":"/api/v2/product","code":"invalid_request"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created := 0
+
+			r := gin.New()
+			r.Use(APIVersionResolver)
+			r.Use(func(c *gin.Context) {
+				if id := c.GetHeader("X-Principal"); id != "" {
+					c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principalOf(id)))
+				}
+			})
+			r.POST("/api/v2/product", Idempotency(idempotencyStoreStub{}, time.Hour, 64), func(c *gin.Context) {
+				body := new(bytes.Buffer)
+				_, _ = body.ReadFrom(c.Request.Body)
+
+				if body.String() == "fail" {
+					c.JSON(http.StatusInternalServerError, gin.H{})
+					return
+				}
+
+				created++
+				c.JSON(http.StatusCreated, gin.H{"id": created})
+			})
+
+			var w *httptest.ResponseRecorder
+
+			for _, request := range tt.requests {
+				w = httptest.NewRecorder()
+				req, err := http.NewRequest(http.MethodPost, "/api/v2/product", bytes.NewBufferString(request.body))
+				assert.NoError(t, err)
+				req.Header.Set("Idempotency-Key", request.key)
+				req.Header.Set("X-Principal", request.principal)
+
+				r.ServeHTTP(w, req)
+			}
+
+			assert.Equal(t, tt.expStatus, w.Code)
+			assert.Equal(t, tt.expBody, w.Body.String())
+			assert.Equal(t, tt.expReplayed, w.Header().Get("Idempotent-Replayed") == "true")
+			assert.Equal(t, tt.expCreated, created)
+		})
+	}
+}
+
+func TestIdempotency_inFlight(t *testing.T) {
+	store := idempotencyStoreStub{}
+
+	r := gin.New()
+	r.Use(APIVersionResolver)
+	r.POST("/api/v2/product", Idempotency(store, time.Hour, 64), func(c *gin.Context) {
+		// the retry arrives while the request is in flight.
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodPost, "/api/v2/product", bytes.NewBufferString(`{}`))
+		req.Header.Set("Idempotency-Key", "key")
+
+		r := gin.New()
+		r.Use(APIVersionResolver)
+		r.POST("/api/v2/product", Idempotency(store, time.Hour, 64))
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, `{"type":"https://api.gfg.com/problems/idempotency_key_in_use","title":"Conflict","status":409,"detail":"Request of the Idempotency-Key is in progress","instance":"/api/v2/product","code":"idempotency_key_in_use"}`, w.Body.String())
+
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	w := httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodPost, "/api/v2/product", bytes.NewBufferString(`{}`))
+	assert.NoError(t, err)
+	req.Header.Set("Idempotency-Key", "key")
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestIdempotency_panic(t *testing.T) {
+	store := idempotencyStoreStub{}
+	panicked := false
+
+	r := gin.New()
+	r.Use(gin.Recovery())
+	r.Use(APIVersionResolver)
+	r.POST("/api/v2/product", Idempotency(store, time.Hour, 64), func(c *gin.Context) {
+		if !panicked {
+			panicked = true
+			panic("any panic")
+		}
+
+		c.JSON(http.StatusCreated, gin.H{})
+	})
+
+	var w *httptest.ResponseRecorder
+
+	for i := 0; i < 2; i++ {
+		w = httptest.NewRecorder()
+		req, err := http.NewRequest(http.MethodPost, "/api/v2/product", bytes.NewBufferString(`{}`))
+		assert.NoError(t, err)
+		req.Header.Set("Idempotency-Key", "key")
+
+		r.ServeHTTP(w, req)
+	}
+
+	// the retry of the panicked request is served, instead of being responded as in progress.
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(HeaderIdempotentReplayed))
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"coding-challenge-go/pkg/api/apierror"
+	"coding-challenge-go/pkg/auth"
+	"coding-challenge-go/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+// RateLimits are the limits of the reads and the writes of every client, the quotas
+// override them for the principals by their names prefixed by the way they are authenticated,
+// e.g. apikey/shop or jwt/feed.
+type RateLimits struct {
+	Read, Write             ratelimit.Limit
+	ReadQuotas, WriteQuotas map[string]ratelimit.Limit
+}
+
+// RateLimit builds a middleware which limits the requests of every client, identified by its
+// principal or by its IP when the request is not authenticated. The reads and the writes are
+// limited separately. The requests over the limit are responded with 429.
+//
+// The limit is described by the RateLimit-* headers of the IETF draft, the denied requests
+// by Retry-After too. The requests are let through when the store fails.
+func RateLimit(store ratelimit.Store, limits RateLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+
+		class, limit, quotas := "read", limits.Read, limits.ReadQuotas
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+		default:
+			class, limit, quotas = "write", limits.Write, limits.WriteQuotas
+		}
+
+		client := "ip:" + clientIP(c)
+
+		if principal := auth.PrincipalFrom(ctx); principal != nil {
+			client = "principal:" + principal.ID
+
+			if quota, ok := quotas[quotaKey(principal)]; ok {
+				limit = quota
+			}
+		}
+
+		if take(c, store, client, class, limit) {
+			c.Next()
+		}
+	}
+}
+
+// quotaKey returns the key of the quota of the principal, its name prefixed by the way it is
+// authenticated, so that the subject of a token does not take the quota of an API key of the same name.
+func quotaKey(principal *auth.Principal) string {
+	way, _, _ := strings.Cut(principal.ID, ":")
+
+	return way + "/" + principal.Name
+}
+
+// RateLimitByIP builds a middleware which limits all the requests of every client IP. It runs ahead
+// of the authentication, so that the requests rejected by it are limited too, e.g. the guesses of
+// the API keys. The requests over the limit are responded with 429.
+func RateLimitByIP(store ratelimit.Store, limit ratelimit.Limit) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if take(c, store, "ip:"+clientIP(c), "", limit) {
+			c.Next()
+		}
+	}
+}
+
+// take takes a token of the bucket of the client and the class of the requests, all the requests
+// when it is empty, and describes the limit by the headers. It responds 429 and returns false when
+// the limit is exceeded.
+func take(c *gin.Context, store ratelimit.Store, client, class string, limit ratelimit.Limit) bool {
+	ctx := c.Request.Context()
+
+	key, requests := client, "requests"
+	if class != "" {
+		key, requests = client+":"+class, class+" requests"
+	}
+
+	result, err := store.Take(ctx, key, limit)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("Fail to take rate limit token, request is let through")
+		return true
+	}
+
+	c.Header("RateLimit-Limit", strconv.Itoa(limit.Requests))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", ceilSeconds(result.Reset))
+	c.Header("RateLimit-Policy", fmt.Sprintf("%d;w=%s", limit.Requests, ceilSeconds(limit.Period)))
+
+	if !result.Allowed {
+		log.Ctx(ctx).Info().Str("client", client).Str("class", class).Msg("Rate limit is exceeded")
+
+		c.Header("Retry-After", ceilSeconds(result.RetryAfter))
+		apierror.Respond(c, http.StatusTooManyRequests, apierror.CodeRateLimited,
+			fmt.Sprintf("Rate limit of %d %s per %s is exceeded", limit.Requests, requests, limit.Period))
+
+		return false
+	}
+
+	return true
+}
+
+// ceilSeconds formats the duration in whole seconds, rounded up so that clients do not retry too early.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
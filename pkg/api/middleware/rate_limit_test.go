@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"coding-challenge-go/pkg/auth"
+	"coding-challenge-go/pkg/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// failingStore fails to take any token.
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("any store error")
+}
+
+func TestRateLimit(t *testing.T) {
+	limits := RateLimits{
+		Read:       ratelimit.Limit{Requests: 2, Period: time.Minute},
+		Write:      ratelimit.Limit{Requests: 1, Period: time.Minute},
+		ReadQuotas: map[string]ratelimit.Limit{"apikey/shop": {Requests: 3, Period: time.Minute}},
+	}
+
+	type request struct {
+		method    string
+		principal string
+	}
+
+	tests := []struct {
+		name       string
+		store      ratelimit.Store
+		requests   []request
+		expStatus  int
+		expHeaders map[string]string
+		expBody    string
+	}{
+		{
+			name:       "serves the request within the limit",
+			store:      ratelimit.NewMemoryStore(),
+			requests:   []request{{method: http.MethodGet}},
+			expStatus:  http.StatusOK,
+			expHeaders: map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "1", "RateLimit-Reset": "30", "RateLimit-Policy": "2;w=60"},
+		},
+		{
+			name:       "responds 429, when the reads exceed the limit",
+			store:      ratelimit.NewMemoryStore(),
+			requests:   []request{{method: http.MethodGet}, {method: http.MethodGet}, {method: http.MethodGet}},
+			expStatus:  http.StatusTooManyRequests,
+			expHeaders: map[string]string{"RateLimit-Remaining": "0", "Retry-After": "30"},
+			expBody:    `{"type":"https://api.gfg.com/problems/rate_limited","title":"Too Many Requests","status":429,"detail":"Rate limit of 2 read requests per 1m0s is exceeded","instance":"/api/v2/product","code":"rate_limited"}`,
+		},
+		{
+			name:       "limits the writes separately",
+			store:      ratelimit.NewMemoryStore(),
+			requests:   []request{{method: http.MethodGet}, {method: http.MethodGet}, {method: http.MethodPost}},
+			expStatus:  http.StatusOK,
+			expHeaders: map[string]string{"RateLimit-Limit": "1", "RateLimit-Remaining": "0"},
+		},
+		{
+			name:  "limits the principals separately",
+			store: ratelimit.NewMemoryStore(),
+			requests: []request{
+				{method: http.MethodPost, principal: "apikey:other"},
+				{method: http.MethodPost, principal: "apikey:writer"},
+			},
+			expStatus: http.StatusOK,
+		},
+		{
+			name:  "limits the principals of the same name authenticated differently separately",
+			store: ratelimit.NewMemoryStore(),
+			requests: []request{
+				{method: http.MethodPost, principal: "apikey:writer"},
+				{method: http.MethodPost, principal: "jwt:writer"},
+			},
+			expStatus: http.StatusOK,
+		},
+		{
+			name:  "applies the quota of the principal",
+			store: ratelimit.NewMemoryStore(),
+			requests: []request{
+				{method: http.MethodGet, principal: "apikey:shop"},
+				{method: http.MethodGet, principal: "apikey:shop"},
+				{method: http.MethodGet, principal: "apikey:shop"},
+			},
+			expStatus:  http.StatusOK,
+			expHeaders: map[string]string{"RateLimit-Limit": "3", "RateLimit-Remaining": "0"},
+		},
+		{
+			name:  "does not apply the quota of the API key to the token of the same name",
+			store: ratelimit.NewMemoryStore(),
+			requests: []request{
+				{method: http.MethodGet, principal: "jwt:shop"},
+				{method: http.MethodGet, principal: "jwt:shop"},
+				{method: http.MethodGet, principal: "jwt:shop"},
+			},
+			expStatus:  http.StatusTooManyRequests,
+			expHeaders: map[string]string{"RateLimit-Limit": "2", "RateLimit-Remaining": "0"},
+			expBody:    `{"type":"https://api.gfg.com/problems/rate_limited","title":"Too Many Requests","status":429,"detail":"Rate limit of 2 read requests per 1m0s is exceeded","instance":"/api/v2/product","code":"rate_limited"}`,
+		},
+		{
+			name:      "lets the request through, when the store fails",
+			store:     failingStore{},
+			requests:  []request{{method: http.MethodGet}},
+			expStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(APIVersionResolver)
+			r.Use(func(c *gin.Context) {
+				if id := c.GetHeader("X-Principal"); id != "" {
+					c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principalOf(id)))
+				}
+			})
+			r.Use(RateLimit(tt.store, limits))
+			r.GET("/api/v2/product", func(c *gin.Context) { c.Status(http.StatusOK) })
+			r.POST("/api/v2/product", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			var w *httptest.ResponseRecorder
+
+			for _, request := range tt.requests {
+				w = httptest.NewRecorder()
+				req, err := http.NewRequest(request.method, "/api/v2/product", nil)
+				assert.NoError(t, err)
+				req.Header.Set("X-Principal", request.principal)
+
+				r.ServeHTTP(w, req)
+			}
+
+			assert.Equal(t, tt.expStatus, w.Code)
+			assert.Equal(t, tt.expBody, w.Body.String())
+			for k, v := range tt.expHeaders {
+				assert.Equal(t, v, w.Header().Get(k), k)
+			}
+		})
+	}
+}
+
+// principalOf returns the principal of the ID, named by the ID without the way it is authenticated.
+func principalOf(id string) *auth.Principal {
+	return &auth.Principal{ID: id, Name: id[strings.Index(id, ":")+1:]}
+}
+
+func TestRateLimitByIP(t *testing.T) {
+	store := ratelimit.NewMemoryStore()
+
+	r := gin.New()
+	r.Use(APIVersionResolver)
+	r.Use(RateLimitByIP(store, ratelimit.Limit{Requests: 2, Period: time.Minute}))
+	// the requests are rejected by the authentication, they are limited nevertheless.
+	r.GET("/api/v2/product", func(c *gin.Context) { c.Status(http.StatusUnauthorized) })
+	r.POST("/api/v2/product", func(c *gin.Context) { c.Status(http.StatusUnauthorized) })
+
+	var w *httptest.ResponseRecorder
+
+	for i, method := range []string{http.MethodGet, http.MethodPost, http.MethodGet} {
+		w = httptest.NewRecorder()
+		req, err := http.NewRequest(method, "/api/v2/product", nil)
+		assert.NoError(t, err)
+		req.RemoteAddr = "192.0.2.1:1234"
+		// the forwarded addresses of the clients which are not trusted proxies are spoofed.
+		req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i))
+		req.Header.Set("X-Real-Ip", fmt.Sprintf("203.0.113.%d", i))
+
+		r.ServeHTTP(w, req)
+	}
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, `{"type":"https://api.gfg.com/problems/rate_limited","title":"Too Many Requests","status":429,"detail":"Rate limit of 2 requests per 1m0s is exceeded","instance":"/api/v2/product","code":"rate_limited"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, err := http.NewRequest(http.MethodGet, "/api/v2/product", nil)
+	assert.NoError(t, err)
+	req.RemoteAddr = "192.0.2.2:1234"
+
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code, "the IPs are limited separately")
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// HeaderRequestID is the header which carries the request ID in both request and response.
+	HeaderRequestID = "X-Request-ID"
+	// KeyRequestID is the key of the request ID in the gin context.
+	KeyRequestID = "request_id"
+)
+
+// validRequestID limits the accepted incoming request IDs, so that clients can not inject
+// arbitrary content into the logs.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._\-]{1,128}$`)
+
+// RequestID is a middleware which assigns an ID to every request, or propagates
+// the one sent by the client in X-Request-ID header.
+//
+// It puts the ID in context and a logger with the request scoped fields in request context,
+// which can be accessed by next handlers with log.Ctx.
+func RequestID(c *gin.Context) {
+	id := c.GetHeader(HeaderRequestID)
+	if !validRequestID.MatchString(id) {
+		id = uuid.New().String()
+	}
+
+	c.Set(KeyRequestID, id)
+	c.Header(HeaderRequestID, id)
+
+	logCtx := log.Logger.With().
+		Str("request_id", id).
+		Str("method", c.Request.Method).
+		Str("route", c.FullPath())
+
+	if sc := trace.SpanContextFromContext(c.Request.Context()); sc.IsValid() {
+		logCtx = logCtx.Str("trace_id", sc.TraceID().String())
+	}
+
+	logger := logCtx.Logger()
+	c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context()))
+
+	c.Next()
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		expSame   bool
+	}{
+		{
+			name:      "propagates the incoming request ID",
+			requestID: "4bf92f35-77b3-4da6",
+			expSame:   true,
+		},
+		{
+			name: "generates the request ID when it is not sent",
+		},
+		{
+			name:      "generates the request ID when the incoming one is invalid",
+			requestID: "id\nwith new line",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			globalLogger := log.Logger
+			log.Logger = zerolog.New(buf)
+			defer func() { log.Logger = globalLogger }()
+
+			r := gin.New()
+			r.Use(RequestID, APIVersionResolver)
+			r.GET("/api/v1/product", func(c *gin.Context) {
+				log.Ctx(c.Request.Context()).Info().Msg("in handler")
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/product", nil)
+			assert.NoError(t, err)
+			req.Header.Set(HeaderRequestID, tt.requestID)
+
+			r.ServeHTTP(w, req)
+
+			requestID := w.Header().Get(HeaderRequestID)
+			assert.NotEmpty(t, requestID)
+			assert.Equal(t, tt.expSame, requestID == tt.requestID)
+
+			line := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+			assert.Equal(t, requestID, line["request_id"])
+			assert.Equal(t, "/api/v1/product", line["route"])
+			assert.Equal(t, "GET", line["method"])
+			assert.Equal(t, "v1", line["version"])
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"coding-challenge-go/pkg/tracing"
+	"coding-challenge-go/pkg/tracing/tracingtest"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	tests := []struct {
+		name        string
+		traceparent string
+		expTraceID  string
+		expParentID string
+		expStatus   int
+	}{
+		{
+			name:        "continues the trace of the incoming traceparent header",
+			traceparent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			expTraceID:  "4bf92f3577b34da6a3ce929d0e0e4736",
+			expParentID: "00f067aa0ba902b7",
+			expStatus:   http.StatusOK,
+		},
+		{
+			name:      "starts a new trace without traceparent header",
+			expStatus: http.StatusOK,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tp, exporter := tracingtest.NewInMemoryProvider()
+			defer tp.Shutdown(context.Background())
+
+			r := gin.New()
+			r.Use(Tracing)
+			r.GET("/api/v1/product", func(c *gin.Context) {
+				_, span := tracing.Start(c.Request.Context(), "child")
+				span.End()
+				c.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, "/api/v1/product?id=1", nil)
+			assert.NoError(t, err)
+
+			if tt.traceparent != "" {
+				req.Header.Set("traceparent", tt.traceparent)
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expStatus, w.Code)
+
+			spans := exporter.GetSpans()
+			if !assert.Len(t, spans, 2) {
+				return
+			}
+
+			child, server := spans[0], spans[1]
+			assert.Equal(t, "GET /api/v1/product", server.Name)
+			assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+			assert.Equal(t, server.SpanContext.SpanID(), child.Parent.SpanID())
+			assert.Equal(t, server.SpanContext.TraceID(), child.SpanContext.TraceID())
+
+			if tt.expTraceID != "" {
+				assert.Equal(t, tt.expTraceID, server.SpanContext.TraceID().String())
+				assert.Equal(t, tt.expParentID, server.Parent.SpanID().String())
+			} else {
+				assert.False(t, server.Parent.IsValid())
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Version is an API version served by the engine, along with its deprecation plan.
+type Version struct {
+	// Name is the version as it is named in the paths, e.g. v1.
+	Name string
+	// DeprecatedAt is the time since when the version is deprecated, zero when it is not deprecated.
+	DeprecatedAt time.Time
+	// Sunset is the time after which the version may not be served anymore, zero when it is not planned.
+	Sunset time.Time
+	// MigrationURL is the documentation of migrating away from the version.
+	MigrationURL string
+	// RejectAfterSunset makes the version responded with 410 after its sunset.
+	RejectAfterSunset bool
+}
+
+// deprecated reports whether the version is deprecated at the given time.
+func (v Version) deprecated(now time.Time) bool {
+	return !v.DeprecatedAt.IsZero() && !now.Before(v.DeprecatedAt)
+}
+
+// rejected reports whether the version must not be served anymore at the given time.
+func (v Version) rejected(now time.Time) bool {
+	return v.RejectAfterSunset && !v.Sunset.IsZero() && !now.Before(v.Sunset)
+}
+
+// maxUsageClients bounds the clients whose usage of a deprecated version is counted apart, the
+// requests of the other clients are counted together as otherClients.
+const maxUsageClients = 1000
+
+// otherClients is the client counting the usage of the clients beyond maxUsageClients.
+const otherClients = "other"
+
+// VersionRegistry holds the API versions served by the engine, it signals the deprecated
+// versions to the clients and counts their usage per client.
+type VersionRegistry struct {
+	versions       map[string]Version
+	defaultVersion string
+	now            func() time.Time
+	maxClients     int
+
+	mu sync.Mutex
+	// usage counts the requests of deprecated versions per version and client.
+	usage map[string]map[string]int64
+}
+
+// NewVersionRegistry builds the VersionRegistry, the defaultVersion serves the requests of
+// unversioned routes which do not select any version.
+func NewVersionRegistry(defaultVersion string, versions ...Version) (*VersionRegistry, error) {
+	r := &VersionRegistry{
+		versions:       make(map[string]Version, len(versions)),
+		defaultVersion: defaultVersion,
+		now:            time.Now,
+		maxClients:     maxUsageClients,
+		usage:          make(map[string]map[string]int64),
+	}
+
+	for _, v := range versions {
+		r.versions[v.Name] = v
+	}
+
+	if _, ok := r.versions[defaultVersion]; !ok {
+		return nil, fmt.Errorf("middleware.NewVersionRegistry: default version %q is not registered", defaultVersion)
+	}
+
+	return r, nil
+}
+
+// Lookup returns the registered version.
+func (r *VersionRegistry) Lookup(name string) (Version, bool) {
+	v, ok := r.versions[name]
+
+	return v, ok
+}
+
+// countUsage counts the request of the deprecated version by the client and returns the count so far.
+// The clients beyond maxClients of the version are counted as otherClients, so that the usage does
+// not grow by every client.
+func (r *VersionRegistry) countUsage(version, client string) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	clients := r.usage[version]
+	if clients == nil {
+		clients = make(map[string]int64)
+		r.usage[version] = clients
+	}
+
+	if _, ok := clients[client]; !ok && len(clients) >= r.maxClients {
+		client = otherClients
+	}
+
+	clients[client]++
+
+	return clients[client]
+}
+
+// setDeprecationHeaders sets the headers signalling the deprecation of the version,
+// see RFC 9745 (Deprecation) and RFC 8594 (Sunset).
+func setDeprecationHeaders(h http.Header, v Version) {
+	h.Set("Deprecation", fmt.Sprintf("@%d", v.DeprecatedAt.Unix()))
+
+	if !v.Sunset.IsZero() {
+		h.Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+	}
+
+	if v.MigrationURL != "" {
+		h.Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"; type="text/html"`, v.MigrationURL))
+	}
+}
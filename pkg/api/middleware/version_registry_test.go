@@ -0,0 +1,152 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"coding-challenge-go/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewAPIVersionResolver_deprecation(t *testing.T) {
+	deprecatedAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		now           time.Time
+		reject        bool
+		path          string
+		expStatus     int
+		expBody       string
+		principal     string
+		expDeprecated bool
+		expUsage      map[string]map[string]int64
+	}{
+		{
+			name:      "serves the version before its deprecation",
+			now:       deprecatedAt.Add(-time.Hour),
+			path:      "/api/v1/products",
+			expStatus: http.StatusOK,
+			expBody:   "v1",
+			expUsage:  map[string]map[string]int64{},
+		},
+		{
+			name:          "signals the deprecated version and counts its usage",
+			now:           deprecatedAt,
+			path:          "/api/v1/products",
+			expStatus:     http.StatusOK,
+			expBody:       "v1",
+			expDeprecated: true,
+			expUsage:      map[string]map[string]int64{"v1": {"ip:192.0.2.1": 1}},
+		},
+		{
+			name:          "counts the usage of the principal instead of its IP",
+			now:           deprecatedAt,
+			principal:     "apikey:1",
+			path:          "/api/v1/products",
+			expStatus:     http.StatusOK,
+			expBody:       "v1",
+			expDeprecated: true,
+			expUsage:      map[string]map[string]int64{"v1": {"principal:apikey:1": 1}},
+		},
+		{
+			name:          "serves the version after its sunset, when rejecting is disabled",
+			now:           sunset.Add(time.Hour),
+			path:          "/api/v1/products",
+			expStatus:     http.StatusOK,
+			expBody:       "v1",
+			expDeprecated: true,
+			expUsage:      map[string]map[string]int64{"v1": {"ip:192.0.2.1": 1}},
+		},
+		{
+			name:      "rejects the version after its sunset",
+			now:       sunset,
+			reject:    true,
+			path:      "/api/v1/products",
+			expStatus: http.StatusGone,
+			expBody:   `{"error":"API version v1 is not served anymore"}`,
+			expUsage:  map[string]map[string]int64{},
+		},
+		{
+			name:      "does not signal the version which is not deprecated",
+			now:       sunset,
+			path:      "/api/v2/products",
+			expStatus: http.StatusOK,
+			expBody:   "v2",
+			expUsage:  map[string]map[string]int64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry, err := NewVersionRegistry("v2",
+				Version{
+					Name:              "v1",
+					DeprecatedAt:      deprecatedAt,
+					Sunset:            sunset,
+					MigrationURL:      "https://docs.gfg.com/migrate/v1",
+					RejectAfterSunset: tt.reject,
+				},
+				Version{Name: "v2"},
+			)
+			assert.NoError(t, err)
+			registry.now = func() time.Time { return tt.now }
+
+			r := gin.New()
+			r.Use(NewAPIVersionResolver(registry))
+			if tt.principal != "" {
+				r.Use(func(c *gin.Context) {
+					c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principalOf(tt.principal)))
+				})
+			}
+			versionHandler := func(c *gin.Context) {
+				c.String(http.StatusOK, c.MustGet("version").(string))
+			}
+			r.GET("/api/v1/products", versionHandler)
+			r.GET("/api/v2/products", versionHandler)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, tt.path, nil)
+			assert.NoError(t, err)
+			req.RemoteAddr = "192.0.2.1:1234"
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expStatus, w.Code)
+			assert.Equal(t, tt.expBody, w.Body.String())
+			assert.Equal(t, tt.expUsage, registry.usage)
+
+			if tt.expDeprecated {
+				assert.Equal(t, "@1767225600", w.Header().Get("Deprecation"))
+				assert.Equal(t, "Wed, 01 Jul 2026 00:00:00 GMT", w.Header().Get("Sunset"))
+				assert.Equal(t, `<https://docs.gfg.com/migrate/v1>; rel="deprecation"; type="text/html"`, w.Header().Get("Link"))
+			} else {
+				assert.Empty(t, w.Header().Get("Deprecation"))
+			}
+		})
+	}
+}
+
+func TestNewVersionRegistry(t *testing.T) {
+	_, err := NewVersionRegistry("v3", Version{Name: "v1"}, Version{Name: "v2"})
+	assert.Error(t, err)
+}
+
+func TestVersionRegistry_countUsage(t *testing.T) {
+	registry, err := NewVersionRegistry("v1", Version{Name: "v1"})
+	assert.NoError(t, err)
+
+	registry.maxClients = 2
+
+	for _, client := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3", "192.0.2.1", "192.0.2.4"} {
+		registry.countUsage("v1", client)
+	}
+
+	assert.Equal(t, map[string]map[string]int64{
+		"v1": {"192.0.2.1": 2, "192.0.2.2": 1, "other": 2},
+	}, registry.usage)
+}
@@ -0,0 +1,107 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"coding-challenge-go/pkg/api/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		expStatus  int
+		expVersion string
+	}{
+		{
+			name:       "v1: serves the document",
+			path:       "/api/v1/openapi.json",
+			expStatus:  http.StatusOK,
+			expVersion: "v1",
+		},
+		{
+			name:       "v2: serves the document",
+			path:       "/api/v2/openapi.json",
+			expStatus:  http.StatusOK,
+			expVersion: "v2",
+		},
+		{
+			name:      "responds 404, when the version is not specified",
+			path:      "/api/v9/openapi.json",
+			expStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(middleware.APIVersionResolver)
+			r.GET("/api/v1/openapi.json", Handler)
+			r.GET("/api/v2/openapi.json", Handler)
+			r.GET("/api/v9/openapi.json", Handler)
+
+			w := httptest.NewRecorder()
+			req, err := http.NewRequest(http.MethodGet, tt.path, nil)
+			assert.NoError(t, err)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expStatus, w.Code)
+
+			if tt.expStatus == http.StatusOK {
+				doc := &Document{}
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), doc))
+				assert.Equal(t, Version, doc.OpenAPI)
+				assert.Equal(t, tt.expVersion, doc.Info.Version)
+			}
+		})
+	}
+}
+
+func TestSpec_refsAreResolved(t *testing.T) {
+	for version := range specs {
+		doc, _ := Spec(version)
+
+		data, err := json.Marshal(doc)
+		assert.NoError(t, err)
+
+		var refs []string
+		collectRefs(data, &refs)
+
+		for _, ref := range refs {
+			_, ok := doc.Components.Schemas[strings.TrimPrefix(ref, "#/components/schemas/")]
+			assert.True(t, ok, "%s: %s is not resolved", version, ref)
+		}
+	}
+}
+
+// collectRefs collects the values of all the $ref keys of the JSON.
+func collectRefs(data []byte, refs *[]string) {
+	var value interface{}
+	_ = json.Unmarshal(data, &value)
+
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		switch v := v.(type) {
+		case map[string]interface{}:
+			for key, child := range v {
+				if ref, ok := child.(string); ok && key == "$ref" {
+					*refs = append(*refs, ref)
+				}
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+
+	walk(value)
+}
@@ -0,0 +1,573 @@
+package openapi
+
+import (
+	"net/http"
+	"strconv"
+
+	"coding-challenge-go/pkg/api/apierror"
+)
+
+// specs build the documents by API version.
+var specs = map[string]func() *Document{
+	"v1": specV1,
+	"v2": specV2,
+	"v3": specV3,
+}
+
+// Spec returns the document of the API version.
+func Spec(version string) (*Document, bool) {
+	spec, ok := specs[version]
+	if !ok {
+		return nil, false
+	}
+
+	return spec(), true
+}
+
+// builder builds the document of an API version, the errors are represented as per the version.
+type builder struct {
+	version string
+	doc     *Document
+}
+
+func newBuilder(version, description string, schemas map[string]*Schema) *builder {
+	if version == "v1" {
+		schemas["Error"] = object(map[string]*Schema{"error": str("The message of the error.")}, "error")
+	} else {
+		schemas["Problem"] = object(map[string]*Schema{
+			"type":       str("The URI identifying the problem type."),
+			"title":      str("The status text."),
+			"status":     integer("The HTTP status code."),
+			"detail":     str("The human-readable message of the error."),
+			"instance":   str("The requested URI."),
+			"code":       codeSchema(),
+			"request_id": str("The ID of the request, see X-Request-ID header."),
+			"errors":     array(ref("FieldError")),
+		}, "type", "title", "status", "code")
+		schemas["FieldError"] = object(map[string]*Schema{
+			"field":  str("The name of the invalid field of the request."),
+			"rule":   str("The validation rule the field failed on."),
+			"detail": str("The human-readable message of the error."),
+		}, "field", "rule", "detail")
+	}
+
+	schemas["Seller"] = object(map[string]*Schema{
+		"uuid":  uuid(""),
+		"name":  str(""),
+		"email": str(""),
+		"phone": str(""),
+	}, "uuid", "name", "email", "phone")
+	schemas["ProductCreate"] = object(map[string]*Schema{
+		"name":   str(""),
+		"brand":  str(""),
+		"stock":  integer(""),
+		"seller": uuid("The UUID of the Seller of the product."),
+	}, "seller")
+	schemas["ProductUpdate"] = object(map[string]*Schema{
+		"name":  str(""),
+		"brand": str(""),
+		"stock": integer(""),
+	})
+
+	return &builder{
+		version: version,
+		doc: &Document{
+			OpenAPI: Version,
+			Info: Info{
+				Title:       "Product API",
+				Description: description,
+				Version:     version,
+			},
+			Servers: []Server{{URL: "/api/" + version}},
+			Paths:   make(map[string]PathItem),
+			Components: Components{
+				Schemas: schemas,
+				SecuritySchemes: map[string]*SecurityScheme{
+					"apiKey": {
+						Type:        "apiKey",
+						Description: "The API key granted the scope of the resource: products:read, products:write or sellers:admin.",
+						Name:        "X-API-Key",
+						In:          "header",
+					},
+					"bearer": {Type: "http", Description: "The API key, or the JWT of the SSO, as bearer token.", Scheme: "bearer"},
+				},
+			},
+			Security: []SecurityRequirement{{"apiKey": {}}, {"bearer": {}}},
+		},
+	}
+}
+
+// operation adds the operation of the path, along with the error responses of the given statuses
+// and of the authentication and the rate limit.
+func (b *builder) operation(method, path string, op *Operation, errorStatuses ...int) {
+	if op.Security == nil {
+		errorStatuses = append(errorStatuses, http.StatusUnauthorized, http.StatusForbidden, http.StatusTooManyRequests)
+	}
+
+	for _, status := range errorStatuses {
+		op.Responses[strconv.Itoa(status)] = b.errorResponse(status)
+	}
+
+	if r, ok := op.Responses[strconv.Itoa(http.StatusTooManyRequests)]; ok {
+		r.Headers = map[string]Header{
+			"Retry-After": {Description: "The seconds until the request may be sent again.", Schema: integer("")},
+		}
+		op.Responses[strconv.Itoa(http.StatusTooManyRequests)] = r
+	}
+
+	if b.doc.Paths[path] == nil {
+		b.doc.Paths[path] = make(PathItem)
+	}
+
+	b.doc.Paths[path][method] = op
+}
+
+// errorResponse is the response of the error as per the version.
+func (b *builder) errorResponse(status int) Response {
+	if b.version == "v1" {
+		return Response{Description: http.StatusText(status), Content: jsonContent(ref("Error"))}
+	}
+
+	return Response{
+		Description: http.StatusText(status),
+		Content:     map[string]MediaType{apierror.ContentTypeProblem: {Schema: ref("Problem")}},
+	}
+}
+
+// specification adds the operation serving the document itself.
+func (b *builder) specification() *Document {
+	b.operation("get", "/openapi.json", &Operation{
+		OperationID: "getSpecification",
+		Summary:     "Returns the OpenAPI document of the version.",
+		Security:    &[]SecurityRequirement{},
+		Responses: map[string]Response{
+			"200": {Description: "The OpenAPI document.", Content: jsonContent(&Schema{Type: "object"})},
+		},
+	})
+
+	return b.doc
+}
+
+func codeSchema() *Schema {
+	codes := []apierror.Code{
+		apierror.CodeInvalidRequest,
+		apierror.CodeValidationFailed,
+		apierror.CodeRouteNotFound,
+		apierror.CodeUnauthorized,
+		apierror.CodeForbidden,
+		apierror.CodeUnsupportedVersion,
+		apierror.CodeVersionSunset,
+		apierror.CodeRateLimited,
+		apierror.CodeIdempotencyKeyReused,
+		apierror.CodeIdempotencyKeyInUse,
+		apierror.CodeInsufficientStock,
+		apierror.CodeUnsupportedMediaType,
+		apierror.CodePayloadTooLarge,
+		apierror.CodeImportJobNotFound,
+		apierror.CodeProductNotFound,
+		apierror.CodeSellerNotFound,
+		apierror.CodeNotFound,
+		apierror.CodeConflict,
+		apierror.CodeDuplicateUUID,
+		apierror.CodeReferenceNotFound,
+		apierror.CodeTimeout,
+		apierror.CodeServiceUnavailable,
+		apierror.CodeInternalServerError,
+	}
+
+	s := str("The stable machine-readable code of the error.")
+	for _, code := range codes {
+		s.Enum = append(s.Enum, string(code))
+	}
+
+	return s
+}
+
+var (
+	pageParam = Parameter{Name: "page", In: "query", Description: "The page of 10 products.", Schema: &Schema{Type: "integer", Default: 1}}
+	idParam   = Parameter{Name: "id", In: "query", Description: "The UUID of the product.", Required: true, Schema: uuid("")}
+	uuidParam = func(description string) Parameter {
+		return Parameter{Name: "uuid", In: "path", Description: description, Required: true, Schema: uuid("")}
+	}
+	fieldsParam = Parameter{
+		Name:        "fields",
+		In:          "query",
+		Description: "The comma-separated fields to return: uuid, name, brand, stock, seller.",
+		Schema:      str(""),
+	}
+	idempotencyKeyParam = Parameter{
+		Name:        "Idempotency-Key",
+		In:          "header",
+		Description: "The unique key of the request, e.g. a UUID, whose response is replayed to its retries for a day by default.",
+		Schema:      str(""),
+	}
+	embedParam = Parameter{
+		Name:        "embed",
+		In:          "query",
+		Description: "The resources to embed under _embedded.",
+		Schema:      &Schema{Type: "string", Enum: []string{"seller"}},
+	}
+)
+
+// productProperties are the properties shared by the product representations of all the versions.
+func productProperties() map[string]*Schema {
+	return map[string]*Schema{
+		"uuid":  uuid(""),
+		"name":  str(""),
+		"brand": str(""),
+		"stock": integer(""),
+	}
+}
+
+func stockAdjustment() *Schema {
+	return object(map[string]*Schema{"delta": integer("The non-zero change of the stock, e.g. -1 for a sold item.")}, "delta")
+}
+
+// importJob is the job of an import of products along with the errors of its rows.
+func importJob() *Schema {
+	rowError := object(map[string]*Schema{
+		"line":        integer("The line of the row in the file."),
+		"external_id": str(""),
+		"field":       str("The invalid field of the row."),
+		"error":       str(""),
+	}, "line", "error")
+
+	status := str("")
+	status.Enum = []string{"running", "succeeded", "failed"}
+
+	return object(map[string]*Schema{
+		"uuid":        uuid(""),
+		"format":      {Type: "string", Enum: []string{"csv", "ndjson"}},
+		"dry_run":     {Type: "boolean"},
+		"upsert":      {Type: "boolean"},
+		"status":      status,
+		"total":       integer("The number of the rows of the file."),
+		"processed":   integer(""),
+		"created":     integer(""),
+		"updated":     integer(""),
+		"failed":      integer(""),
+		"errors":      array(rowError),
+		"error":       str("The error which stopped the failed job."),
+		"created_at":  {Type: "string", Format: "date-time"},
+		"finished_at": {Type: "string", Format: "date-time"},
+	}, "uuid", "format", "dry_run", "upsert", "status", "total", "processed", "created", "updated", "failed", "errors", "created_at")
+}
+
+func batchRequest() *Schema {
+	operation := object(map[string]*Schema{
+		"op":     {Type: "string", Enum: []string{"create", "update", "delete", "adjust_stock"}},
+		"uuid":   uuid("The UUID of the product, required by all the operations but create."),
+		"name":   str("The name of the created or updated product."),
+		"brand":  str("The brand of the created or updated product."),
+		"stock":  integer("The stock of the created or updated product."),
+		"seller": uuid("The UUID of the Seller of the created product."),
+		"delta":  integer("The delta of the adjusted stock."),
+	}, "op")
+
+	operations := array(operation)
+	operations.Description = "1 to 1000 operations, executed in order."
+
+	return object(map[string]*Schema{
+		"mode":       {Type: "string", Enum: []string{"atomic", "best_effort"}, Default: "atomic"},
+		"operations": operations,
+	}, "operations")
+}
+
+func batchResponse() *Schema {
+	result := object(map[string]*Schema{
+		"index":   integer("The index of the operation in the request."),
+		"op":      str(""),
+		"status":  integer("The HTTP status of the operation as it was requested on its own."),
+		"product": ref("productV2"),
+		"error":   object(map[string]*Schema{"code": codeSchema(), "detail": str("")}, "code", "detail"),
+	}, "index", "op", "status")
+
+	return object(map[string]*Schema{
+		"mode":      {Type: "string", Enum: []string{"atomic", "best_effort"}},
+		"succeeded": integer(""),
+		"failed":    integer(""),
+		"results":   array(result),
+	}, "mode", "succeeded", "failed", "results")
+}
+
+func link() *Schema {
+	return object(map[string]*Schema{"href": str("")}, "href")
+}
+
+func embedded() *Schema {
+	return object(map[string]*Schema{
+		"seller": object(map[string]*Schema{"uuid": uuid(""), "name": str("")}, "uuid", "name"),
+	})
+}
+
+func specV1() *Document {
+	product := object(productProperties(), "uuid", "name", "brand", "stock", "seller_uuid")
+	product.Properties["seller_uuid"] = uuid("")
+
+	b := newBuilder("v1", "The legacy version, whose errors are responded as {\"error\": \"message\"}.",
+		map[string]*Schema{"product": product})
+
+	b.operation("get", "/products", &Operation{
+		OperationID: "listProducts",
+		Summary:     "Returns a page of products.",
+		Parameters:  []Parameter{pageParam},
+		Responses:   map[string]Response{"200": {Description: "The products.", Content: jsonContent(array(ref("product")))}},
+	}, http.StatusBadRequest, http.StatusInternalServerError)
+
+	b.operation("get", "/product", &Operation{
+		OperationID: "getProduct",
+		Summary:     "Returns the product, null when it is not found.",
+		Parameters:  []Parameter{idParam},
+		Responses: map[string]Response{
+			"200": {Description: "The product.", Content: jsonContent(&Schema{Ref: "#/components/schemas/product", Nullable: true})},
+		},
+	}, http.StatusBadRequest, http.StatusInternalServerError)
+
+	b.operation("post", "/product", &Operation{
+		OperationID: "createProduct",
+		Summary:     "Creates the product.",
+		Parameters:  []Parameter{idempotencyKeyParam},
+		RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("ProductCreate"))},
+		Responses:   map[string]Response{"200": {Description: "The created product.", Content: jsonContent(ref("product"))}},
+	}, http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError)
+
+	b.operation("put", "/product", &Operation{
+		OperationID: "updateProduct",
+		Summary:     "Updates the product.",
+		Parameters:  []Parameter{idParam},
+		RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("ProductUpdate"))},
+		Responses:   map[string]Response{"200": {Description: "The updated product.", Content: jsonContent(ref("product"))}},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError)
+
+	b.operation("delete", "/product", &Operation{
+		OperationID: "deleteProduct",
+		Summary:     "Deletes the product.",
+		Parameters:  []Parameter{idParam},
+		Responses:   map[string]Response{"200": {Description: "The product is deleted.", Content: jsonContent(object(nil))}},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError)
+
+	b.operation("get", "/sellers", &Operation{
+		OperationID: "listSellers",
+		Summary:     "Returns all the sellers.",
+		Responses:   map[string]Response{"200": {Description: "The sellers.", Content: jsonContent(array(ref("Seller")))}},
+	}, http.StatusInternalServerError)
+
+	return b.specification()
+}
+
+func specV2() *Document {
+	productV2 := object(productProperties(), "uuid", "name", "brand", "stock", "seller")
+	productV2.Properties["seller"] = object(map[string]*Schema{
+		"uuid":   uuid(""),
+		"_links": object(map[string]*Schema{"self": link()}, "self"),
+	}, "uuid", "_links")
+	productV2.Properties["_embedded"] = embedded()
+
+	b := newBuilder("v2", "The errors are responded as RFC 7807 problem details.",
+		map[string]*Schema{"productV2": productV2, "StockAdjustment": stockAdjustment(), "ImportJob": importJob(),
+			"BatchRequest": batchRequest(), "BatchResponse": batchResponse()})
+
+	b.operation("get", "/products", &Operation{
+		OperationID: "listProducts",
+		Summary:     "Returns a page of products.",
+		Parameters:  []Parameter{pageParam, fieldsParam, embedParam},
+		Responses:   map[string]Response{"200": {Description: "The products.", Content: jsonContent(array(ref("productV2")))}},
+	}, http.StatusBadRequest, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("get", "/product", &Operation{
+		OperationID: "getProduct",
+		Summary:     "Returns the product.",
+		Parameters:  []Parameter{idParam, fieldsParam, embedParam},
+		Responses:   map[string]Response{"200": {Description: "The product.", Content: jsonContent(ref("productV2"))}},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("post", "/product", &Operation{
+		OperationID: "createProduct",
+		Summary:     "Creates the product.",
+		Parameters:  []Parameter{idempotencyKeyParam},
+		RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("ProductCreate"))},
+		Responses:   map[string]Response{"200": {Description: "The created product.", Content: jsonContent(ref("productV2"))}},
+	}, http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("put", "/product", &Operation{
+		OperationID: "updateProduct",
+		Summary:     "Updates the product.",
+		Parameters:  []Parameter{idParam},
+		RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("ProductUpdate"))},
+		Responses:   map[string]Response{"200": {Description: "The updated product.", Content: jsonContent(ref("productV2"))}},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("patch", "/product/stock", &Operation{
+		OperationID: "adjustProductStock",
+		Summary:     "Adjusts the stock of the product by the delta, the stock never becomes negative.",
+		Parameters:  []Parameter{idParam, idempotencyKeyParam},
+		RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("StockAdjustment"))},
+		Responses:   map[string]Response{"200": {Description: "The adjusted product.", Content: jsonContent(ref("productV2"))}},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("delete", "/product", &Operation{
+		OperationID: "deleteProduct",
+		Summary:     "Deletes the product.",
+		Parameters:  []Parameter{idParam},
+		Responses:   map[string]Response{"200": {Description: "The product is deleted.", Content: jsonContent(object(nil))}},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("get", "/products/export", &Operation{
+		OperationID: "exportProducts",
+		Summary:     "Streams all the products of the filters, gzipped by Accept-Encoding: gzip.",
+		Parameters: []Parameter{
+			{Name: "format", In: "query", Description: "The format of the export.",
+				Schema: &Schema{Type: "string", Enum: []string{"ndjson", "csv"}, Default: "ndjson"}},
+			{Name: "representation", In: "query", Description: "The version of the representation of the products.",
+				Schema: &Schema{Type: "string", Enum: []string{"v1", "v2"}, Default: "v2"}},
+			{Name: "seller", In: "query", Description: "Exports only the products of the seller.", Schema: uuid("")},
+			{Name: "brand", In: "query", Description: "Exports only the products of the brand.", Schema: str("")},
+			{Name: "in_stock", In: "query", Description: "Exports only the products in stock.", Schema: &Schema{Type: "boolean"}},
+		},
+		Responses: map[string]Response{"200": {
+			Description: "The products, a JSON object per line or a CSV row each.",
+			Content: map[string]MediaType{
+				"application/x-ndjson": {Schema: ref("productV2")},
+				"text/csv":             {Schema: str("uuid,name,brand,stock,seller")},
+			},
+		}},
+	}, http.StatusBadRequest, http.StatusInternalServerError, http.StatusServiceUnavailable)
+
+	b.operation("post", "/products/batch", &Operation{
+		OperationID: "batchProducts",
+		Summary: "Executes the operations on the products: atomic batches run in a transaction and respond the error of " +
+			"their first failing operation, best-effort batches respond the result of every operation. The sellers are " +
+			"notified of the stock changes after the commit.",
+		Parameters:  []Parameter{idempotencyKeyParam},
+		RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("BatchRequest"))},
+		Responses:   map[string]Response{"200": {Description: "The results of the operations.", Content: jsonContent(ref("BatchResponse"))}},
+	}, http.StatusBadRequest, http.StatusForbidden, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	importRow := object(map[string]*Schema{
+		"external_id": str("The ID of the product in the catalog of the seller, required by upsert."),
+		"name":        str(""),
+		"brand":       str(""),
+		"stock":       integer(""),
+		"seller":      uuid("The UUID of the Seller of the product."),
+	}, "name", "brand", "seller")
+
+	b.operation("post", "/products/import", &Operation{
+		OperationID: "importProducts",
+		Summary:     "Starts the import of the products of the CSV file, whose header names the columns, or of the NDJSON file.",
+		Parameters: []Parameter{
+			{Name: "dry_run", In: "query", Description: "Validates the rows without importing them.", Schema: &Schema{Type: "boolean"}},
+			{Name: "mode", In: "query", Description: "upsert updates the products of the sellers by their external IDs.",
+				Schema: &Schema{Type: "string", Enum: []string{"insert", "upsert"}, Default: "insert"}},
+			idempotencyKeyParam,
+		},
+		RequestBody: &RequestBody{Required: true, Content: map[string]MediaType{
+			"text/csv":             {Schema: str("external_id,name,brand,stock,seller")},
+			"application/x-ndjson": {Schema: importRow},
+		}},
+		Responses: map[string]Response{
+			"202": {
+				Description: "The started job, see Location.",
+				Headers:     map[string]Header{"Location": {Description: "The URL of the job.", Schema: str("")}},
+				Content:     jsonContent(ref("ImportJob")),
+			},
+		},
+	}, http.StatusBadRequest, http.StatusRequestEntityTooLarge, http.StatusUnsupportedMediaType, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("get", "/products/import", &Operation{
+		OperationID: "getImportJob",
+		Summary:     "Returns the progress of the import job and the errors of its rows.",
+		Parameters:  []Parameter{{Name: "id", In: "query", Description: "The UUID of the job.", Required: true, Schema: uuid("")}},
+		Responses:   map[string]Response{"200": {Description: "The job.", Content: jsonContent(ref("ImportJob"))}},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("get", "/sellers/top10", &Operation{
+		OperationID: "listTopSellers",
+		Summary:     "Returns the 10 sellers with the most products.",
+		Responses:   map[string]Response{"200": {Description: "The sellers.", Content: jsonContent(array(ref("Seller")))}},
+	}, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	return b.specification()
+}
+
+func specV3() *Document {
+	productV3 := object(productProperties(), "uuid", "name", "brand", "stock", "seller", "_links")
+	productV3.Properties["seller"] = object(map[string]*Schema{
+		"uuid":   uuid(""),
+		"_links": object(map[string]*Schema{"products": link()}, "products"),
+	}, "uuid", "_links")
+	productV3.Properties["_links"] = object(map[string]*Schema{"self": link()}, "self")
+	productV3.Properties["_embedded"] = embedded()
+
+	b := newBuilder("v3", "The resources are identified by the path, the errors are responded as RFC 7807 problem details.",
+		map[string]*Schema{"productV3": productV3, "StockAdjustment": stockAdjustment()})
+
+	b.operation("get", "/products", &Operation{
+		OperationID: "listProducts",
+		Summary:     "Returns a page of products.",
+		Parameters:  []Parameter{pageParam, fieldsParam, embedParam},
+		Responses:   map[string]Response{"200": {Description: "The products.", Content: jsonContent(array(ref("productV3")))}},
+	}, http.StatusBadRequest, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("post", "/products", &Operation{
+		OperationID: "createProduct",
+		Summary:     "Creates the product.",
+		Parameters:  []Parameter{idempotencyKeyParam},
+		RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("ProductCreate"))},
+		Responses: map[string]Response{"201": {
+			Description: "The created product.",
+			Headers:     map[string]Header{"Location": {Description: "The path of the created product.", Schema: str("")}},
+			Content:     jsonContent(ref("productV3")),
+		}},
+	}, http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("get", "/products/{uuid}", &Operation{
+		OperationID: "getProduct",
+		Summary:     "Returns the product.",
+		Parameters:  []Parameter{uuidParam("The UUID of the product."), fieldsParam, embedParam},
+		Responses:   map[string]Response{"200": {Description: "The product.", Content: jsonContent(ref("productV3"))}},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("put", "/products/{uuid}", &Operation{
+		OperationID: "updateProduct",
+		Summary:     "Updates the product.",
+		Parameters:  []Parameter{uuidParam("The UUID of the product.")},
+		RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("ProductUpdate"))},
+		Responses:   map[string]Response{"200": {Description: "The updated product.", Content: jsonContent(ref("productV3"))}},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("patch", "/products/{uuid}/stock", &Operation{
+		OperationID: "adjustProductStock",
+		Summary:     "Adjusts the stock of the product by the delta, the stock never becomes negative.",
+		Parameters:  []Parameter{uuidParam("The UUID of the product."), idempotencyKeyParam},
+		RequestBody: &RequestBody{Required: true, Content: jsonContent(ref("StockAdjustment"))},
+		Responses:   map[string]Response{"200": {Description: "The adjusted product.", Content: jsonContent(ref("productV3"))}},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("delete", "/products/{uuid}", &Operation{
+		OperationID: "deleteProduct",
+		Summary:     "Deletes the product.",
+		Parameters:  []Parameter{uuidParam("The UUID of the product.")},
+		Responses:   map[string]Response{"204": {Description: "The product is deleted."}},
+	}, http.StatusNotFound, http.StatusConflict, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("get", "/sellers", &Operation{
+		OperationID: "listSellers",
+		Summary:     "Returns all the sellers.",
+		Responses:   map[string]Response{"200": {Description: "The sellers.", Content: jsonContent(array(ref("Seller")))}},
+	}, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("get", "/sellers/{uuid}/products", &Operation{
+		OperationID: "listSellerProducts",
+		Summary:     "Returns a page of the products of the seller.",
+		Parameters:  []Parameter{uuidParam("The UUID of the seller."), pageParam, fieldsParam, embedParam},
+		Responses:   map[string]Response{"200": {Description: "The products.", Content: jsonContent(array(ref("productV3")))}},
+	}, http.StatusBadRequest, http.StatusNotFound, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	b.operation("get", "/top-sellers", &Operation{
+		OperationID: "listTopSellers",
+		Summary:     "Returns the 10 sellers with the most products.",
+		Responses:   map[string]Response{"200": {Description: "The sellers.", Content: jsonContent(array(ref("Seller")))}},
+	}, http.StatusInternalServerError, http.StatusServiceUnavailable, http.StatusGatewayTimeout)
+
+	return b.specification()
+}
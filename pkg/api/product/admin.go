@@ -0,0 +1,122 @@
+package product
+
+import (
+	"context"
+
+	"coding-challenge-go/pkg/storage"
+)
+
+// Product is the product managed by the Admin.
+type Product = product
+
+// Admin manages the products without HTTP, e.g. for cmd/gfgctl. It shares the repositories of the
+// controller and notifies the sellers of the stock changes like it, but it is not authorized.
+type Admin struct {
+	pc *controller
+}
+
+// NewAdmin builds the Admin of the dependencies of the controller.
+func NewAdmin(pc *controller) *Admin {
+	return &Admin{pc: pc}
+}
+
+// List returns a page of the products, of the seller when its UUID is not empty.
+func (a *Admin) List(ctx context.Context, sellerUUID string, offset, limit int) ([]*Product, error) {
+	if sellerUUID != "" {
+		return a.pc.finder.listBySeller(ctx, sellerUUID, offset, limit)
+	}
+
+	return a.pc.finder.list(ctx, offset, limit)
+}
+
+// Get returns the product.
+//
+// Returns storage.ErrNotFound, when the product does not exist.
+func (a *Admin) Get(ctx context.Context, uuid string) (*Product, error) {
+	product, err := a.pc.finderByUUID.findByUUID(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if product == nil {
+		return nil, storage.ErrNotFound
+	}
+
+	return product, nil
+}
+
+// Create creates the product, its UUID is generated.
+//
+// Returns storage.ErrForeignKey, when the seller of the product does not exist.
+func (a *Admin) Create(ctx context.Context, product *Product) (*Product, error) {
+	return a.pc.inserter.insert(ctx, product)
+}
+
+// Upsert updates the product of the seller which has the same external ID, or creates the product when
+// there is none, e.g. for the imports of the catalogs. It reports whether the product is created. The
+// sellers are not notified of the stock changes, as the imports would flood them.
+//
+// Returns storage.ErrForeignKey, when the seller of the product does not exist.
+func (a *Admin) Upsert(ctx context.Context, product *Product) (bool, error) {
+	return a.pc.inserter.upsert(ctx, product)
+}
+
+// Update updates the name, the brand and the stock of the product, the seller is notified when
+// the stock is changed.
+//
+// Returns storage.ErrNotFound, when the product does not exist.
+func (a *Admin) Update(ctx context.Context, product *Product) error {
+	var oldStock int
+
+	// NOTE - the stock is read and updated in a transaction, which is retried as a whole on deadlock.
+	// The product is not locked: when it is updated in between, the last write wins and the seller is
+	// notified of the change from the stock read, not from the overwritten one.
+	err := a.pc.do(ctx, func(tx *Tx) error {
+		old, err := tx.Products.findByUUID(ctx, product.UUID)
+		if err != nil {
+			return err
+		}
+
+		if old == nil {
+			return storage.ErrNotFound
+		}
+
+		oldStock = old.Stock
+
+		return tx.Products.update(ctx, product)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return a.pc.notifyStockChanged(ctx, product, oldStock)
+}
+
+// AdjustStock adjusts the stock of the product by the delta atomically, and notifies the seller.
+//
+// Returns ErrInsufficientStock, when the stock would be negative. Returns storage.ErrNotFound,
+// when the product does not exist.
+func (a *Admin) AdjustStock(ctx context.Context, uuid string, delta int) (*Product, error) {
+	product, err := a.Get(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.pc.updater.adjustStock(ctx, product, delta); err != nil {
+		return nil, err
+	}
+
+	if err := a.pc.notifyStockChanged(ctx, product, product.Stock-delta); err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// Delete deletes the product.
+//
+// Returns storage.ErrNotFound, when the product does not exist.
+func (a *Admin) Delete(ctx context.Context, uuid string) error {
+	return a.pc.deleter.delete(ctx, &product{UUID: uuid})
+}
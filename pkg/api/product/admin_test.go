@@ -0,0 +1,69 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sellerAPI "coding-challenge-go/pkg/api/seller"
+	"coding-challenge-go/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAdmin_Get(t *testing.T) {
+	finder := new(FinderByUUIDMock)
+	finder.On("findByUUID", mock.Anything, "found").Return(&product{UUID: "found"}, nil)
+	finder.On("findByUUID", mock.Anything, "missing").Return(nil, nil)
+	finder.On("findByUUID", mock.Anything, "failing").Return(nil, errors.New("any sql error"))
+
+	admin := NewAdmin(NewController(nil, nil, nil, finder, nil, nil, nil, nil))
+
+	got, err := admin.Get(context.Background(), "found")
+	assert.NoError(t, err)
+	assert.Equal(t, &product{UUID: "found"}, got)
+
+	_, err = admin.Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+
+	_, err = admin.Get(context.Background(), "failing")
+	assert.Error(t, err)
+}
+
+func TestAdmin_Update(t *testing.T) {
+	const sellerUUID = "a223850e-d8ab-430a-9a1a-28628cfd52b0"
+
+	finder := new(FinderByUUIDMock)
+	finder.On("findByUUID", mock.Anything, "p1").Return(&product{UUID: "p1", Name: "shoes", Stock: 10, SellerUUID: sellerUUID}, nil)
+
+	updated := &product{UUID: "p1", Name: "sneakers", Stock: 4, SellerUUID: sellerUUID}
+
+	updater := new(UpdaterMock)
+	updater.On("update", mock.Anything, updated).Return(nil)
+
+	sellers := new(SellerFinderMock)
+	sellers.On("FindByUUID", mock.Anything, sellerUUID).Return(&sellerAPI.Seller{UUID: sellerUUID, Phone: "202-555-0143"}, nil)
+
+	sms := new(StockChangedNotifierMock)
+	sms.On("StockChanged", mock.Anything, sellerUUID, "202-555-0143", 10, 4, "sneakers")
+
+	admin := NewAdmin(NewController(nil, updater, nil, finder, nil, sellers, nil, sms))
+
+	assert.NoError(t, admin.Update(context.Background(), updated))
+	updater.AssertExpectations(t)
+	sms.AssertExpectations(t)
+}
+
+func TestAdmin_AdjustStock(t *testing.T) {
+	finder := new(FinderByUUIDMock)
+	finder.On("findByUUID", mock.Anything, "p1").Return(&product{UUID: "p1", Stock: 1}, nil)
+
+	updater := new(UpdaterMock)
+	updater.On("adjustStock", mock.Anything, mock.Anything, -2).Return(ErrInsufficientStock)
+
+	admin := NewAdmin(NewController(nil, updater, nil, finder, nil, nil, nil, nil))
+
+	_, err := admin.AdjustStock(context.Background(), "p1", -2)
+	assert.ErrorIs(t, err, ErrInsufficientStock)
+}
@@ -0,0 +1,350 @@
+package product
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"coding-challenge-go/pkg/api/apierror"
+	"coding-challenge-go/pkg/auth"
+	"coding-challenge-go/pkg/storage"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	batchModeAtomic     = "atomic"
+	batchModeBestEffort = "best_effort"
+
+	batchOpCreate      = "create"
+	batchOpUpdate      = "update"
+	batchOpDelete      = "delete"
+	batchOpAdjustStock = "adjust_stock"
+)
+
+// batchOperation is an operation of a batch, its fields are of the requests of the single operations.
+type batchOperation struct {
+	Op     string `json:"op"`
+	UUID   string `json:"uuid"`
+	Name   string `json:"name"`
+	Brand  string `json:"brand"`
+	Stock  int    `json:"stock"`
+	Seller string `json:"seller"`
+	Delta  int    `json:"delta"`
+}
+
+// batchResult is the result of an operation of a batch.
+type batchResult struct {
+	Index   int             `json:"index"`
+	Op      string          `json:"op"`
+	Status  int             `json:"status"`
+	Product json.RawMessage `json:"product,omitempty"`
+	Error   *batchError     `json:"error,omitempty"`
+}
+
+// batchError is the error of an operation of a batch.
+type batchError struct {
+	Code   apierror.Code `json:"code"`
+	Detail string        `json:"detail"`
+}
+
+// opError is the error of an operation caused by the operation itself, e.g. a missing field.
+type opError struct {
+	status int
+	code   apierror.Code
+	detail string
+}
+
+func (e *opError) Error() string {
+	return e.detail
+}
+
+// stockChange is a change of the stock of a product, the sellers are notified of it once the batch
+// is committed.
+type stockChange struct {
+	product  *product
+	oldStock int
+}
+
+// batchController is an HTTP controller handles the batches of operations on the products.
+type batchController struct {
+	pc         *controller
+	unitOfWork UnitOfWork
+}
+
+// NewBatchController builds the batch controller of the dependencies of the product controller, the
+// atomic batches run in the transactions of the unit of work.
+func NewBatchController(pc *controller, unitOfWork UnitOfWork) *batchController {
+	return &batchController{pc: pc, unitOfWork: unitOfWork}
+}
+
+// Post executes the operations of the batch: the creations, the updates, the deletions and the stock
+// adjustments of products. The atomic batch, by default, runs in a transaction and responds the error
+// of its first failing operation; the best-effort batch executes every operation in a transaction of
+// its own and responds the result of each. The sellers are notified of the stock changes after the commit.
+func (bc *batchController) Post(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	// NOTE - the operations are bounded, so that a transaction does not lock the products for long.
+	request := &struct {
+		Mode       string           `json:"mode" binding:"omitempty,oneof=atomic best_effort"`
+		Operations []batchOperation `json:"operations" binding:"required,min=1,max=1000"`
+	}{}
+
+	if err := c.ShouldBindJSON(request); err != nil {
+		apierror.RespondBinding(c, err, request)
+		return
+	}
+
+	if request.Mode == "" {
+		request.Mode = batchModeAtomic
+	}
+
+	principal := auth.PrincipalFrom(ctx)
+	results := make([]batchResult, len(request.Operations))
+
+	var changes []stockChange
+
+	execute := func(repository TxRepository, i int) error {
+		op := request.Operations[i]
+		results[i] = batchResult{Index: i, Op: op.Op}
+
+		product, oldStock, err := bc.execute(ctx, repository, principal, op)
+		if err != nil {
+			return err
+		}
+
+		results[i].Status = http.StatusOK
+		if op.Op == batchOpCreate {
+			results[i].Status = http.StatusCreated
+		}
+
+		if product == nil {
+			changes = forgetStockChanges(changes, op.UUID)
+			return nil
+		}
+
+		if results[i].Product, err = marshalJSON(c, product); err != nil {
+			return err
+		}
+
+		if op.Op != batchOpCreate && product.Stock != oldStock {
+			changes = append(changes, stockChange{product: product, oldStock: oldStock})
+		}
+
+		return nil
+	}
+
+	if request.Mode == batchModeBestEffort {
+		failed := 0
+
+		for i := range request.Operations {
+			// NOTE - the stock change of the operation is recorded once its transaction is committed.
+			recorded := changes
+
+			err := bc.pc.do(ctx, func(tx *Tx) error {
+				changes = recorded
+				return execute(tx.Products, i)
+			})
+
+			if err != nil {
+				changes = recorded
+				failed++
+				results[i].Product = nil
+				results[i].Status, results[i].Error = describeOpError(ctx, err)
+			}
+		}
+
+		bc.notify(ctx, changes)
+
+		c.JSON(http.StatusOK, gin.H{
+			"mode":      request.Mode,
+			"succeeded": len(results) - failed,
+			"failed":    failed,
+			"results":   results,
+		})
+
+		return
+	}
+
+	failing := -1
+
+	err := bc.unitOfWork.do(ctx, func(tx *Tx) error {
+		// NOTE - the transaction may be retried, the changes of the previous attempt are rolled back.
+		changes, failing = nil, -1
+
+		for i := range request.Operations {
+			if err := execute(tx.Products, i); err != nil {
+				failing = i
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil && failing < 0 {
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to commit batch")
+		respondError(c, err, "Fail to commit batch")
+		return
+	}
+
+	if err != nil {
+		status, batchErr := describeOpError(ctx, err)
+		detail := fmt.Sprintf("Operation %d failed, the batch is rolled back: %s", failing, batchErr.Detail)
+
+		apierror.Respond(c, status, batchErr.Code, detail, apierror.FieldError{
+			Field:  fmt.Sprintf("operations[%d]", failing),
+			Rule:   string(batchErr.Code),
+			Detail: batchErr.Detail,
+		})
+
+		return
+	}
+
+	bc.notify(ctx, changes)
+
+	c.JSON(http.StatusOK, gin.H{"mode": request.Mode, "succeeded": len(results), "failed": 0, "results": results})
+}
+
+// execute executes the operation and returns the written product, nil when it is deleted, with its
+// stock before the operation.
+func (bc *batchController) execute(
+	ctx context.Context,
+	repository TxRepository,
+	principal *auth.Principal,
+	op batchOperation,
+) (*product, int, error) {
+	if op.Op == batchOpCreate {
+		if op.Seller == "" {
+			return nil, 0, &opError{http.StatusBadRequest, apierror.CodeValidationFailed, "Seller is required"}
+		}
+
+		if err := authorizeOp(principal, op.Seller); err != nil {
+			return nil, 0, err
+		}
+
+		product, err := repository.insert(ctx, &product{Name: op.Name, Brand: op.Brand, Stock: op.Stock, SellerUUID: op.Seller})
+
+		return product, 0, err
+	}
+
+	switch op.Op {
+	case batchOpUpdate, batchOpDelete:
+	case batchOpAdjustStock:
+		if op.Delta == 0 {
+			return nil, 0, &opError{http.StatusBadRequest, apierror.CodeValidationFailed, "Delta is required"}
+		}
+	default:
+		return nil, 0, &opError{http.StatusBadRequest, apierror.CodeValidationFailed,
+			"Op must be one of create, update, delete and adjust_stock"}
+	}
+
+	if op.UUID == "" {
+		return nil, 0, &opError{http.StatusBadRequest, apierror.CodeValidationFailed, "UUID is required"}
+	}
+
+	product, err := repository.findByUUID(ctx, op.UUID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if product == nil {
+		return nil, 0, storage.ErrNotFound
+	}
+
+	if err := authorizeOp(principal, product.SellerUUID); err != nil {
+		return nil, 0, err
+	}
+
+	oldStock := product.Stock
+
+	switch op.Op {
+	case batchOpUpdate:
+		product.Name, product.Brand, product.Stock = op.Name, op.Brand, op.Stock
+		err = repository.update(ctx, product)
+	case batchOpAdjustStock:
+		err = repository.adjustStock(ctx, product, op.Delta)
+	case batchOpDelete:
+		return nil, oldStock, repository.delete(ctx, product)
+	}
+
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return product, oldStock, nil
+}
+
+// notify notifies the sellers of the stock changes, once per product from its stock before the batch.
+func (bc *batchController) notify(ctx context.Context, changes []stockChange) {
+	oldStocks := make(map[string]int, len(changes))
+	latest := make(map[string]*product, len(changes))
+
+	var order []string
+
+	for _, change := range changes {
+		uuid := change.product.UUID
+		if _, ok := oldStocks[uuid]; !ok {
+			oldStocks[uuid] = change.oldStock
+			order = append(order, uuid)
+		}
+
+		latest[uuid] = change.product
+	}
+
+	for _, uuid := range order {
+		// NOTE - the batch is committed already, a failing notification is not its failure.
+		if err := bc.pc.notifyStockChanged(ctx, latest[uuid], oldStocks[uuid]); err != nil {
+			log.Ctx(ctx).Error().Err(err).Str("product_uuid", uuid).Msg("Fail to notify stock change")
+		}
+	}
+}
+
+// forgetStockChanges drops the stock changes of the deleted product, whose seller is not notified.
+func forgetStockChanges(changes []stockChange, uuid string) []stockChange {
+	var kept []stockChange
+
+	for _, change := range changes {
+		if change.product.UUID != uuid {
+			kept = append(kept, change)
+		}
+	}
+
+	return kept
+}
+
+// authorizeOp returns the error of the operation on the products of the seller, when the principal
+// may not modify them. See authorizeSeller.
+func authorizeOp(principal *auth.Principal, sellerUUID string) error {
+	if principal == nil || principal.MayModify(sellerUUID) {
+		return nil
+	}
+
+	return &opError{http.StatusForbidden, apierror.CodeForbidden, "Products of the seller may not be modified"}
+}
+
+// describeOpError returns the status and the error of the failed operation, the errors which are not
+// caused by the operation are logged.
+func describeOpError(ctx context.Context, err error) (int, *batchError) {
+	var oe *opError
+
+	switch {
+	case errors.As(err, &oe):
+		return oe.status, &batchError{Code: oe.code, Detail: oe.detail}
+	case errors.Is(err, ErrInsufficientStock):
+		return http.StatusConflict, &batchError{Code: apierror.CodeInsufficientStock, Detail: "Stock is insufficient"}
+	}
+
+	status := apierror.StatusCode(err)
+	if status >= http.StatusInternalServerError {
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to execute batch operation")
+	}
+
+	code, message := describeError(err, "Fail to execute operation")
+
+	return status, &batchError{Code: code, Detail: message}
+}
@@ -0,0 +1,272 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"coding-challenge-go/pkg/api/middleware"
+	sellerAPI "coding-challenge-go/pkg/api/seller"
+	"coding-challenge-go/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// UnitOfWorkMock is an autogenerated mock type for the UnitOfWork type
+type UnitOfWorkMock struct {
+	mock.Mock
+}
+
+// do provides a mock function with given fields: ctx, fn
+func (_m *UnitOfWorkMock) do(ctx context.Context, fn func(*Tx) error) error {
+	ret := _m.Called(ctx, fn)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, func(*Tx) error) error); ok {
+		r0 = rf(ctx, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+const (
+	batchSellerUUID  = "a223850e-d8ab-430a-9a1a-28628cfd52b0"
+	batchProductUUID = "61981e52-e1ca-449e-b79f-01d5906b3435"
+	batchOtherUUID   = "36345687-e998-4359-a2ed-a9703fe39b5f"
+)
+
+// batchFixture is the repository of the products shoes (stock 10) and socks (stock 1), whose writes
+// are recorded by the mocks.
+type batchFixture struct {
+	finder   *FinderByUUIDMock
+	inserter *InserterMock
+	updater  *UpdaterMock
+	deleter  *DeleterMock
+	sms      *StockChangedNotifierMock
+}
+
+func newBatchFixture() *batchFixture {
+	f := &batchFixture{
+		finder:   new(FinderByUUIDMock),
+		inserter: new(InserterMock),
+		updater:  new(UpdaterMock),
+		deleter:  new(DeleterMock),
+		sms:      new(StockChangedNotifierMock),
+	}
+
+	// NOTE - the products are found as they are written by the previous operations.
+	f.finder.On("findByUUID", mock.Anything, batchProductUUID).
+		Return(&product{ProductID: 1, UUID: batchProductUUID, Name: "shoes", Brand: "nike", Stock: 10, SellerUUID: batchSellerUUID}, nil)
+	f.finder.On("findByUUID", mock.Anything, batchOtherUUID).
+		Return(&product{ProductID: 2, UUID: batchOtherUUID, Name: "socks", Brand: "adidas", Stock: 1, SellerUUID: batchSellerUUID}, nil)
+	f.finder.On("findByUUID", mock.Anything, mock.Anything).Return(nil, nil)
+
+	f.inserter.On("insert", mock.Anything, mock.Anything).Return(func(_ context.Context, p *product) *product {
+		p.UUID = "e943dc0a-98bb-47b4-9d1d-056b95d3f064"
+		return p
+	}, nil)
+
+	f.updater.On("update", mock.Anything, mock.Anything).Return(nil)
+	f.updater.On("adjustStock", mock.Anything, mock.Anything, mock.Anything).Return(func(_ context.Context, p *product, delta int) error {
+		if p.Stock+delta < 0 {
+			return ErrInsufficientStock
+		}
+
+		p.Stock += delta
+
+		return nil
+	})
+
+	f.deleter.On("delete", mock.Anything, mock.Anything).Return(nil)
+
+	return f
+}
+
+func (f *batchFixture) router(unitOfWork UnitOfWork, principal *auth.Principal) *gin.Engine {
+	sellers := new(SellerFinderMock)
+	sellers.On("FindByUUID", mock.Anything, batchSellerUUID).Return(&sellerAPI.Seller{UUID: batchSellerUUID, Phone: "202-555-0143"}, nil)
+
+	pc := NewController(f.deleter, f.updater, f.inserter, f.finder, nil, sellers, nil, f.sms)
+
+	r := gin.Default()
+	r.Use(middleware.APIVersionResolver, func(c *gin.Context) {
+		if principal != nil {
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		}
+	})
+	r.POST("/api/v2/products/batch", NewBatchController(pc, unitOfWork).Post)
+
+	return r
+}
+
+// committing is the UnitOfWork which commits the writes of fn unless it fails, on the repositories
+// of the fixture.
+func (f *batchFixture) committing(commitErr error) *UnitOfWorkMock {
+	unitOfWork := new(UnitOfWorkMock)
+	unitOfWork.On("do", mock.Anything, mock.Anything).Return(func(_ context.Context, fn func(*Tx) error) error {
+		products := &controllerRepository{Inserter: f.inserter, Updater: f.updater, Deleter: f.deleter, FinderByUUID: f.finder}
+		if err := fn(&Tx{Products: products}); err != nil {
+			return err
+		}
+
+		return commitErr
+	})
+
+	return unitOfWork
+}
+
+func postBatch(t *testing.T, r *gin.Engine, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+
+	req, err := http.NewRequest(http.MethodPost, "/api/v2/products/batch", strings.NewReader(body))
+	assert.NoError(t, err)
+
+	r.ServeHTTP(w, req)
+
+	return w
+}
+
+func Test_batchController_PostAtomic(t *testing.T) {
+	f := newBatchFixture()
+	f.sms.On("StockChanged", mock.Anything, batchSellerUUID, "202-555-0143", 10, 5, "shoes")
+
+	w := postBatch(t, f.router(f.committing(nil), nil), `{"operations":[
+		{"op":"create","name":"hat","brand":"gap","stock":3,"seller":"`+batchSellerUUID+`"},
+		{"op":"update","uuid":"`+batchProductUUID+`","name":"shoes","brand":"nike","stock":4},
+		{"op":"adjust_stock","uuid":"`+batchProductUUID+`","delta":1},
+		{"op":"delete","uuid":"`+batchOtherUUID+`"}
+	]}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"mode":"atomic","succeeded":4,"failed":0,"results":[
+		{"index":0,"op":"create","status":201,"product":{"uuid":"e943dc0a-98bb-47b4-9d1d-056b95d3f064","name":"hat","brand":"gap","stock":3,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"self":{"href":"/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0"}}}}},
+		{"index":1,"op":"update","status":200,"product":{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":4,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"self":{"href":"/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0"}}}}},
+		{"index":2,"op":"adjust_stock","status":200,"product":{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":5,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"self":{"href":"/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0"}}}}},
+		{"index":3,"op":"delete","status":200}
+	]}`, w.Body.String())
+
+	// the seller is notified once of the stock of shoes before and after the batch.
+	f.sms.AssertExpectations(t)
+	f.sms.AssertNumberOfCalls(t, "StockChanged", 1)
+	f.deleter.AssertCalled(t, "delete", mock.Anything, mock.MatchedBy(func(p *product) bool { return p.UUID == batchOtherUUID }))
+}
+
+func Test_batchController_PostAtomicFailure(t *testing.T) {
+	tests := []struct {
+		name       string
+		unitOfWork func(f *batchFixture) UnitOfWork
+		principal  *auth.Principal
+		body       string
+		expStatus  int
+		expBody    string
+	}{
+		{
+			name:       "Returns 404, when the product of an operation is not found",
+			unitOfWork: func(f *batchFixture) UnitOfWork { return f.committing(nil) },
+			body:       `{"operations":[{"op":"adjust_stock","uuid":"` + batchProductUUID + `","delta":-1},{"op":"delete","uuid":"missing"}]}`,
+			expStatus:  http.StatusNotFound,
+			expBody:    `{"type":"https://api.gfg.com/problems/product_not_found","title":"Not Found","status":404,"detail":"Operation 1 failed, the batch is rolled back: Product is not found","instance":"/api/v2/products/batch","code":"product_not_found","errors":[{"field":"operations[1]","rule":"product_not_found","detail":"Product is not found"}]}`,
+		},
+		{
+			name:       "Returns 409, when the stock is insufficient",
+			unitOfWork: func(f *batchFixture) UnitOfWork { return f.committing(nil) },
+			body:       `{"mode":"atomic","operations":[{"op":"adjust_stock","uuid":"` + batchOtherUUID + `","delta":-2}]}`,
+			expStatus:  http.StatusConflict,
+			expBody:    `{"type":"https://api.gfg.com/problems/insufficient_stock","title":"Conflict","status":409,"detail":"Operation 0 failed, the batch is rolled back: Stock is insufficient","instance":"/api/v2/products/batch","code":"insufficient_stock","errors":[{"field":"operations[0]","rule":"insufficient_stock","detail":"Stock is insufficient"}]}`,
+		},
+		{
+			name:       "Returns 403, when the principal may not modify the seller",
+			unitOfWork: func(f *batchFixture) UnitOfWork { return f.committing(nil) },
+			principal:  &auth.Principal{Name: "shop", SellerUUID: "b223850e-d8ab-430a-9a1a-28628cfd52b0"},
+			body:       `{"operations":[{"op":"update","uuid":"` + batchProductUUID + `","name":"shoes","brand":"nike","stock":0}]}`,
+			expStatus:  http.StatusForbidden,
+			expBody:    `{"type":"https://api.gfg.com/problems/forbidden","title":"Forbidden","status":403,"detail":"Operation 0 failed, the batch is rolled back: Products of the seller may not be modified","instance":"/api/v2/products/batch","code":"forbidden","errors":[{"field":"operations[0]","rule":"forbidden","detail":"Products of the seller may not be modified"}]}`,
+		},
+		{
+			name:       "Returns 500, when the commit fails",
+			unitOfWork: func(f *batchFixture) UnitOfWork { return f.committing(errors.New("any sql error")) },
+			body:       `{"operations":[{"op":"adjust_stock","uuid":"` + batchProductUUID + `","delta":-1}]}`,
+			expStatus:  http.StatusInternalServerError,
+			expBody:    `{"type":"https://api.gfg.com/problems/internal_server_error","title":"Internal Server Error","status":500,"detail":"Fail to commit batch","instance":"/api/v2/products/batch","code":"internal_server_error"}`,
+		},
+		{
+			name:      "Returns 400, when there is no operation",
+			body:      `{"operations":[]}`,
+			expStatus: http.StatusBadRequest,
+			expBody:   `{"type":"https://api.gfg.com/problems/validation_failed","title":"Bad Request","status":400,"detail":"Key: 'Operations' Error:Field validation for 'Operations' failed on the 'min' tag","instance":"/api/v2/products/batch","code":"validation_failed","errors":[{"field":"operations","rule":"min","detail":"Key: 'Operations' Error:Field validation for 'Operations' failed on the 'min' tag"}]}`,
+		},
+		{
+			name:      "Returns 400, when the mode is unknown",
+			body:      `{"mode":"eventually","operations":[{"op":"delete","uuid":"` + batchProductUUID + `"}]}`,
+			expStatus: http.StatusBadRequest,
+			expBody:   `{"type":"https://api.gfg.com/problems/validation_failed","title":"Bad Request","status":400,"detail":"Key: 'Mode' Error:Field validation for 'Mode' failed on the 'oneof' tag","instance":"/api/v2/products/batch","code":"validation_failed","errors":[{"field":"mode","rule":"oneof","detail":"Key: 'Mode' Error:Field validation for 'Mode' failed on the 'oneof' tag"}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newBatchFixture()
+
+			var unitOfWork UnitOfWork
+			if tt.unitOfWork != nil {
+				unitOfWork = tt.unitOfWork(f)
+			}
+
+			w := postBatch(t, f.router(unitOfWork, tt.principal), tt.body)
+
+			assert.Equal(t, tt.expStatus, w.Code)
+			assert.JSONEq(t, tt.expBody, w.Body.String())
+
+			// the sellers are not notified of the rolled back changes.
+			f.sms.AssertNotCalled(t, "StockChanged", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func Test_batchController_PostBestEffort(t *testing.T) {
+	f := newBatchFixture()
+	f.sms.On("StockChanged", mock.Anything, batchSellerUUID, "202-555-0143", 10, 7, "shoes")
+
+	// the batch is not a unit of work in the best-effort mode, its operations are.
+	unitOfWork := new(UnitOfWorkMock)
+
+	w := postBatch(t, f.router(unitOfWork, nil), `{"mode":"best_effort","operations":[
+		{"op":"adjust_stock","uuid":"`+batchProductUUID+`","delta":-3},
+		{"op":"adjust_stock","uuid":"`+batchOtherUUID+`","delta":-2},
+		{"op":"delete","uuid":"missing"},
+		{"op":"rename","uuid":"`+batchProductUUID+`"},
+		{"op":"create","name":"hat","brand":"gap"},
+		{"op":"adjust_stock","uuid":"`+batchOtherUUID+`"}
+	]}`)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"mode":"best_effort","succeeded":1,"failed":5,"results":[
+		{"index":0,"op":"adjust_stock","status":200,"product":{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":7,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"self":{"href":"/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0"}}}}},
+		{"index":1,"op":"adjust_stock","status":409,"error":{"code":"insufficient_stock","detail":"Stock is insufficient"}},
+		{"index":2,"op":"delete","status":404,"error":{"code":"product_not_found","detail":"Product is not found"}},
+		{"index":3,"op":"rename","status":400,"error":{"code":"validation_failed","detail":"Op must be one of create, update, delete and adjust_stock"}},
+		{"index":4,"op":"create","status":400,"error":{"code":"validation_failed","detail":"Seller is required"}},
+		{"index":5,"op":"adjust_stock","status":400,"error":{"code":"validation_failed","detail":"Delta is required"}}
+	]}`, w.Body.String())
+
+	f.sms.AssertExpectations(t)
+	unitOfWork.AssertNotCalled(t, "do", mock.Anything, mock.Anything)
+}
+
+func Test_forgetStockChanges(t *testing.T) {
+	changes := []stockChange{
+		{product: &product{UUID: batchProductUUID}, oldStock: 1},
+		{product: &product{UUID: batchOtherUUID}, oldStock: 2},
+		{product: &product{UUID: batchProductUUID}, oldStock: 3},
+	}
+
+	assert.Equal(t, []stockChange{{product: &product{UUID: batchOtherUUID}, oldStock: 2}}, forgetStockChanges(changes, batchProductUUID))
+	assert.Empty(t, forgetStockChanges(nil, batchProductUUID))
+}
@@ -0,0 +1,320 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	sellerAPI "coding-challenge-go/pkg/api/seller"
+	"coding-challenge-go/pkg/storage"
+	"coding-challenge-go/pkg/storage/memory"
+	"coding-challenge-go/pkg/storage/storagetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backend is a storage backend of the conformance tests, its repositories share an empty DB.
+type backend struct {
+	products   Store
+	sellers    sellerAPI.Store
+	unitOfWork UnitOfWork
+}
+
+func TestMemoryStore_conformance(t *testing.T) {
+	testStoreConformance(t, func(t *testing.T) backend {
+		db := memory.New()
+
+		return backend{
+			products:   NewMemoryRepository(db),
+			sellers:    sellerAPI.NewMemoryRepository(db),
+			unitOfWork: NewMemoryUnitOfWork(db),
+		}
+	})
+}
+
+func TestMySQLStore_conformance(t *testing.T) {
+	testStoreConformance(t, sqlBackend(storagetest.OpenMySQL))
+}
+
+func TestPostgresStore_conformance(t *testing.T) {
+	testStoreConformance(t, sqlBackend(storagetest.OpenPostgres))
+}
+
+func TestSQLiteStore_conformance(t *testing.T) {
+	testStoreConformance(t, sqlBackend(storagetest.OpenSQLite))
+}
+
+// sqlBackend returns the function building the backend of the DBs opened by open.
+func sqlBackend(open func(t *testing.T) *sql.DB) func(t *testing.T) backend {
+	return func(t *testing.T) backend {
+		db := open(t)
+
+		products, sellers := NewRepository(db, 0), sellerAPI.NewRepository(db, 0)
+
+		return backend{
+			products:   products,
+			sellers:    sellers,
+			unitOfWork: NewUnitOfWork(storage.NewTxManager(db, 0), products, sellers),
+		}
+	}
+}
+
+// testStoreConformance runs the cases of the repositories of the products against the backends, which
+// must behave the same.
+func testStoreConformance(t *testing.T, newBackend func(t *testing.T) backend) {
+	ctx := context.Background()
+
+	// setup inserts the sellers and their products of the names, in order.
+	setup := func(t *testing.T, sellers int, names ...string) (backend, []*sellerAPI.Seller, []*product) {
+		b := newBackend(t)
+
+		var (
+			inserted []*sellerAPI.Seller
+			products []*product
+		)
+
+		for i := 0; i < sellers; i++ {
+			seller, err := b.sellers.Insert(ctx, &sellerAPI.Seller{Name: "seller", Email: "s@example.com", Phone: "202-555-0143"})
+			require.NoError(t, err)
+
+			inserted = append(inserted, seller)
+		}
+
+		for i, name := range names {
+			p, err := b.products.insert(ctx, &product{Name: name, Brand: "nike", Stock: i, SellerUUID: inserted[i%sellers].UUID})
+			require.NoError(t, err)
+
+			products = append(products, p)
+		}
+
+		return b, inserted, products
+	}
+
+	names := func(products []*product) []string {
+		var names []string
+		for _, p := range products {
+			names = append(names, p.Name)
+		}
+
+		return names
+	}
+
+	t.Run("inserts and finds the product", func(t *testing.T) {
+		b, sellers, products := setup(t, 1, "shoes")
+
+		found, err := b.products.findByUUID(ctx, products[0].UUID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+
+		assert.NotZero(t, found.ProductID)
+		assert.Equal(t, products[0].UUID, found.UUID)
+		assert.Equal(t, "shoes", found.Name)
+		assert.Equal(t, "nike", found.Brand)
+		assert.Equal(t, 0, found.Stock)
+		assert.Equal(t, sellers[0].UUID, found.SellerUUID)
+	})
+
+	t.Run("does not find the missing product", func(t *testing.T) {
+		b, _, _ := setup(t, 1)
+
+		found, err := b.products.findByUUID(ctx, "61981e52-e1ca-449e-b79f-01d5906b3435")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("does not insert the product of a missing seller", func(t *testing.T) {
+		b, _, _ := setup(t, 1)
+
+		_, err := b.products.insert(ctx, &product{Name: "shoes", Brand: "nike", SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0"})
+		assert.ErrorIs(t, err, storage.ErrForeignKey)
+	})
+
+	t.Run("updates the product", func(t *testing.T) {
+		b, _, products := setup(t, 1, "shoes")
+
+		p := products[0]
+		p.Name, p.Brand, p.Stock = "socks", "adidas", 7
+		require.NoError(t, b.products.update(ctx, p))
+
+		found, err := b.products.findByUUID(ctx, p.UUID)
+		require.NoError(t, err)
+		assert.Equal(t, "socks", found.Name)
+		assert.Equal(t, "adidas", found.Brand)
+		assert.Equal(t, 7, found.Stock)
+
+		err = b.products.update(ctx, &product{UUID: "61981e52-e1ca-449e-b79f-01d5906b3435", Name: "hat"})
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("adjusts the stock", func(t *testing.T) {
+		b, _, products := setup(t, 1, "shoes", "socks")
+
+		p := products[1]
+		require.NoError(t, b.products.adjustStock(ctx, p, 4))
+		assert.Equal(t, 5, p.Stock)
+
+		require.NoError(t, b.products.adjustStock(ctx, p, -5))
+		assert.Equal(t, 0, p.Stock)
+
+		assert.ErrorIs(t, b.products.adjustStock(ctx, p, -1), ErrInsufficientStock)
+
+		found, err := b.products.findByUUID(ctx, p.UUID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, found.Stock)
+
+		err = b.products.adjustStock(ctx, &product{UUID: "61981e52-e1ca-449e-b79f-01d5906b3435"}, 1)
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("deletes the product", func(t *testing.T) {
+		b, _, products := setup(t, 1, "shoes")
+
+		require.NoError(t, b.products.delete(ctx, products[0]))
+
+		found, err := b.products.findByUUID(ctx, products[0].UUID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		assert.ErrorIs(t, b.products.delete(ctx, products[0]), storage.ErrNotFound)
+	})
+
+	t.Run("upserts the product by its external ID", func(t *testing.T) {
+		b, sellers, _ := setup(t, 2)
+
+		p := &product{Name: "shoes", Brand: "nike", Stock: 1, SellerUUID: sellers[0].UUID, ExternalID: "sku-1"}
+		inserted, err := b.products.upsert(ctx, p)
+		require.NoError(t, err)
+		assert.True(t, inserted)
+
+		// the external IDs are of the sellers.
+		other := &product{Name: "socks", Brand: "nike", Stock: 1, SellerUUID: sellers[1].UUID, ExternalID: "sku-1"}
+		inserted, err = b.products.upsert(ctx, other)
+		require.NoError(t, err)
+		assert.True(t, inserted)
+
+		updated := &product{Name: "boots", Brand: "puma", Stock: 3, SellerUUID: sellers[0].UUID, ExternalID: "sku-1"}
+		inserted, err = b.products.upsert(ctx, updated)
+		require.NoError(t, err)
+		assert.False(t, inserted)
+		assert.Equal(t, p.UUID, updated.UUID)
+
+		found, err := b.products.findByUUID(ctx, p.UUID)
+		require.NoError(t, err)
+		assert.Equal(t, "boots", found.Name)
+		assert.Equal(t, 3, found.Stock)
+
+		_, err = b.products.upsert(ctx, &product{Name: "hat", Brand: "gap", SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0", ExternalID: "sku-2"})
+		assert.ErrorIs(t, err, storage.ErrForeignKey)
+	})
+
+	t.Run("lists the pages of the products in order", func(t *testing.T) {
+		b, sellers, _ := setup(t, 2, "a", "b", "c", "d", "e")
+
+		page, err := b.products.list(ctx, 0, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b"}, names(page))
+
+		page, err = b.products.list(ctx, 4, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"e"}, names(page))
+
+		page, err = b.products.list(ctx, 6, 2)
+		require.NoError(t, err)
+		assert.Empty(t, page)
+
+		page, err = b.products.listBySeller(ctx, sellers[0].UUID, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c", "e"}, names(page))
+
+		page, err = b.products.listBySeller(ctx, "a223850e-d8ab-430a-9a1a-28628cfd52b0", 0, 10)
+		require.NoError(t, err)
+		assert.Empty(t, page)
+	})
+
+	t.Run("exports the products of the filter in order", func(t *testing.T) {
+		b, sellers, products := setup(t, 2, "a", "b", "c", "d")
+
+		products[3].Brand = "adidas"
+		require.NoError(t, b.products.update(ctx, products[3]))
+
+		export := func(filter exportFilter) []string {
+			var exported []*product
+
+			require.NoError(t, b.products.export(ctx, filter, func(p *product) error {
+				exported = append(exported, p)
+				return nil
+			}))
+
+			return names(exported)
+		}
+
+		assert.Equal(t, []string{"a", "b", "c", "d"}, export(exportFilter{}))
+		assert.Equal(t, []string{"b", "d"}, export(exportFilter{SellerUUID: sellers[1].UUID}))
+		assert.Equal(t, []string{"a", "b", "c"}, export(exportFilter{Brand: "nike"}))
+		assert.Equal(t, []string{"b", "c", "d"}, export(exportFilter{InStock: true}))
+		assert.Equal(t, []string{"d"}, export(exportFilter{SellerUUID: sellers[1].UUID, Brand: "adidas", InStock: true}))
+
+		errStop := errors.New("stop")
+		calls := 0
+
+		err := b.products.export(ctx, exportFilter{}, func(*product) error {
+			calls++
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("commits the unit of work", func(t *testing.T) {
+		b, sellers, _ := setup(t, 1)
+
+		var inserted *product
+
+		err := b.unitOfWork.do(ctx, func(tx *Tx) error {
+			seller, err := tx.Sellers.FindByUUID(ctx, sellers[0].UUID)
+			if err != nil {
+				return err
+			}
+
+			inserted, err = tx.Products.insert(ctx, &product{Name: "shoes", Brand: "nike", SellerUUID: seller.UUID})
+
+			return err
+		})
+		require.NoError(t, err)
+
+		found, err := b.products.findByUUID(ctx, inserted.UUID)
+		require.NoError(t, err)
+		assert.NotNil(t, found)
+	})
+
+	t.Run("rolls back the unit of work", func(t *testing.T) {
+		b, sellers, products := setup(t, 1, "shoes")
+
+		errRollback := errors.New("rollback")
+
+		var inserted *product
+
+		err := b.unitOfWork.do(ctx, func(tx *Tx) (err error) {
+			if err := tx.Products.adjustStock(ctx, products[0], 5); err != nil {
+				return err
+			}
+
+			if inserted, err = tx.Products.insert(ctx, &product{Name: "socks", Brand: "nike", SellerUUID: sellers[0].UUID}); err != nil {
+				return err
+			}
+
+			return errRollback
+		})
+		assert.ErrorIs(t, err, errRollback)
+
+		found, err := b.products.findByUUID(ctx, inserted.UUID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		found, err = b.products.findByUUID(ctx, products[0].UUID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, found.Stock)
+	})
+}
@@ -1,9 +1,16 @@
 package product
 
 import (
+	"context"
+	"errors"
 	"net/http"
+	"strings"
 
+	"coding-challenge-go/pkg/api/apierror"
 	sellerAPI "coding-challenge-go/pkg/api/seller"
+	"coding-challenge-go/pkg/auth"
+	"coding-challenge-go/pkg/logging"
+	"coding-challenge-go/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -13,36 +20,81 @@ const (
 	listPageSize = 10
 	versionV1    = "v1"
 	versionV2    = "v2"
+	versionV3    = "v3"
 )
 
 // SellerFinder is a Finder for Seller.
 type SellerFinder interface {
-	FindByUUID(uuid string) (*sellerAPI.Seller, error)
+	FindByUUID(ctx context.Context, uuid string) (*sellerAPI.Seller, error)
+	FindByUUIDs(ctx context.Context, uuids []string) ([]*sellerAPI.Seller, error)
 }
 
 // FinderByUUID is a Finder for Product by UUID.
 type FinderByUUID interface {
-	findByUUID(uuid string) (*product, error)
+	findByUUID(ctx context.Context, uuid string) (*product, error)
 }
 
 // ManyFinder is a Finder for many Products with paging.
 type ManyFinder interface {
-	list(offset int, limit int) ([]*product, error)
+	list(ctx context.Context, offset int, limit int) ([]*product, error)
+	listBySeller(ctx context.Context, sellerUUID string, offset int, limit int) ([]*product, error)
+	// export passes all the products of the filter to each one by one, it stops at the first error of each.
+	export(ctx context.Context, filter exportFilter, each func(*product) error) error
+}
+
+// exportFilter filters the exported products, its empty fields do not filter.
+type exportFilter struct {
+	SellerUUID string
+	Brand      string
+	InStock    bool
 }
 
 // Updater is a updater which updates the Product to repository.
 type Updater interface {
-	update(product *product) error
+	update(ctx context.Context, product *product) error
+	adjustStock(ctx context.Context, product *product, delta int) error
 }
 
+// ErrInsufficientStock is returned by the Updater when the adjusted stock would be negative.
+var ErrInsufficientStock = errors.New("product: insufficient stock")
+
 // Inserter inserts the Product to underlying repository.
 type Inserter interface {
-	insert(product *product) (*product, error)
+	insert(ctx context.Context, product *product) (*product, error)
+	upsert(ctx context.Context, product *product) (bool, error)
 }
 
 // Deletes the Product from underlying repository
 type Deleter interface {
-	delete(product *product) error
+	delete(ctx context.Context, product *product) error
+}
+
+// TxRepository is the repository of the products bound to a transaction.
+type TxRepository interface {
+	Inserter
+	Updater
+	Deleter
+	FinderByUUID
+}
+
+// Store is the repository of the products of a storage backend, the DB or the memory.
+type Store interface {
+	TxRepository
+	ManyFinder
+}
+
+// Tx is the repositories bound to the transaction of a unit of work.
+type Tx struct {
+	Products TxRepository
+	Sellers  SellerFinder
+}
+
+// UnitOfWork runs the steps of an operation in a transaction, e.g. looking up the seller and
+// inserting its product.
+type UnitOfWork interface {
+	// do runs fn with the repositories bound to a transaction, which is committed when fn returns
+	// nil and rolled back otherwise. fn runs again when the transaction is retried.
+	do(ctx context.Context, fn func(tx *Tx) error) error
 }
 
 // controller is an HTTP controller handles HTTP requests for Product APIs.
@@ -55,6 +107,9 @@ type controller struct {
 	sellerRepository SellerFinder
 	emailProvider    StockChangedNotifier
 	smsProvider      StockChangedNotifier
+	// unitOfWork runs the steps of the writes in transactions, they run on the repositories above
+	// without a transaction when it is nil.
+	unitOfWork UnitOfWork
 }
 
 // NewController builds the Product controller.
@@ -80,58 +135,327 @@ func NewController(
 	}
 }
 
+// WithUnitOfWork returns the controller running the steps of its writes in the transactions of
+// the unit of work.
+func (pc *controller) WithUnitOfWork(unitOfWork UnitOfWork) *controller {
+	pc.unitOfWork = unitOfWork
+
+	return pc
+}
+
+// do runs fn in a transaction of the unit of work, or on the repositories of the controller when
+// there is none.
+func (pc *controller) do(ctx context.Context, fn func(tx *Tx) error) error {
+	if pc.unitOfWork != nil {
+		return pc.unitOfWork.do(ctx, fn)
+	}
+
+	return fn(&Tx{
+		Products: &controllerRepository{
+			Inserter:     pc.inserter,
+			Updater:      pc.updater,
+			Deleter:      pc.deleter,
+			FinderByUUID: pc.finderByUUID,
+		},
+		Sellers: pc.sellerRepository,
+	})
+}
+
+// respondError responds the error of the repositories, the storage errors caused
+// by the request are described, the others are responded with the fallback message.
+func respondError(c *gin.Context, err error, fallback string) {
+	code, message := describeError(err, fallback)
+
+	apierror.Respond(c, apierror.StatusCode(err), code, message)
+}
+
+// describeError returns the code and the message of the error of the repositories, see respondError.
+func describeError(err error, fallback string) (apierror.Code, string) {
+	code, message := apierror.CodeOf(err), fallback
+
+	switch {
+	case errors.Is(err, storage.ErrNotFound):
+		code, message = apierror.CodeProductNotFound, "Product is not found"
+	case errors.Is(err, storage.ErrForeignKey):
+		code, message = apierror.CodeSellerNotFound, "Seller is not found"
+	case errors.Is(err, storage.ErrDuplicateUUID):
+		message = "Product already exists"
+	case errors.Is(err, storage.ErrConflict):
+		message = "Product is in conflict with its current state"
+	}
+
+	return code, message
+}
+
+// isV1 reports whether the request is for v1, whose inconsistent responses are kept
+// for backward compatibility.
+func isV1(c *gin.Context) bool {
+	return c.MustGet("version").(string) == versionV1
+}
+
+// isV3 reports whether the request is for v3, which identifies the products by the path.
+func isV3(c *gin.Context) bool {
+	return c.MustGet("version").(string) == versionV3
+}
+
+// bindProductUUID returns the uuid of the requested Product, v3 takes it from the path and the
+// previous versions from the id query param. The invalid request is responded.
+func bindProductUUID(c *gin.Context) (string, bool) {
+	if uuid := c.Param("uuid"); uuid != "" {
+		return uuid, true
+	}
+
+	request := &struct {
+		UUID string `form:"id" binding:"required"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		apierror.RespondBinding(c, err, request)
+		return "", false
+	}
+
+	return request.UUID, true
+}
+
+// authorizeSeller reports whether the principal of the request may modify the products of the seller,
+// the forbidden request is responded. The requests are not authenticated when the authentication is disabled.
+func authorizeSeller(c *gin.Context, sellerUUID string) bool {
+	principal := auth.PrincipalFrom(c.Request.Context())
+	if principal == nil || principal.MayModify(sellerUUID) {
+		return true
+	}
+
+	log.Ctx(c.Request.Context()).Warn().
+		Str("principal", principal.Name).
+		Str("seller_uuid", sellerUUID).
+		Msg("Principal may not modify the products of the seller")
+
+	apierror.Respond(c, http.StatusForbidden, apierror.CodeForbidden, "Products of the seller may not be modified")
+
+	return false
+}
+
+// bindView binds the requested view of the products: the sparse fieldset (?fields=uuid,name)
+// and the embedded resources (?embed=seller), whose Sellers are queried at once for all the
+// products. v1 does not support them. The invalid request is responded.
+func (pc *controller) bindView(ctx context.Context, c *gin.Context, products ...*product) bool {
+	if isV1(c) {
+		return true
+	}
+
+	request := &struct {
+		Fields string `form:"fields"`
+		Embed  string `form:"embed" binding:"omitempty,oneof=seller"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		apierror.RespondBinding(c, err, request)
+		return false
+	}
+
+	if request.Fields != "" {
+		fields := strings.Split(request.Fields, ",")
+
+		for _, field := range fields {
+			if !selectableFields[field] {
+				apierror.Respond(c, http.StatusBadRequest, apierror.CodeValidationFailed, "Unknown field "+field,
+					apierror.FieldError{Field: "fields", Rule: "oneof", Detail: "Unknown field " + field})
+				return false
+			}
+		}
+
+		c.Set(keyFields, fields)
+	}
+
+	if request.Embed != embedSeller {
+		return true
+	}
+
+	uuids := make([]string, 0, len(products))
+	seen := make(map[string]bool, len(products))
+
+	for _, p := range products {
+		if !seen[p.SellerUUID] {
+			seen[p.SellerUUID] = true
+			uuids = append(uuids, p.SellerUUID)
+		}
+	}
+
+	sellers, err := pc.sellerRepository.FindByUUIDs(ctx, uuids)
+
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to query sellers by UUIDs")
+		respondError(c, err, "Fail to query sellers by UUIDs")
+		return false
+	}
+
+	embedded := make(map[string]*sellerAPI.Seller, len(sellers))
+	for _, s := range sellers {
+		embedded[s.UUID] = s
+	}
+
+	c.Set(keyEmbeddedSellers, embedded)
+
+	return true
+}
+
 // List returns many products as per page and number of results.
 func (pc *controller) List(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	request := &struct {
 		Page int `form:"page,default=1"`
 	}{}
 
 	if err := c.ShouldBindQuery(request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.RespondBinding(c, err, request)
 		return
 	}
 
-	products, err := pc.finder.list((request.Page-1)*listPageSize, listPageSize)
+	products, err := pc.finder.list(ctx, (request.Page-1)*listPageSize, listPageSize)
 
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to query product list")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query product list"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to query product list")
+		respondError(c, err, "Fail to query product list")
+		return
+	}
+
+	if !pc.bindView(ctx, c, products...) {
 		return
 	}
 
 	productsJson, err := marshalJSON(c, products)
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to marshal products")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal products"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to marshal products")
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternalServerError, "Fail to marshal products")
 		return
 	}
 
 	c.Data(http.StatusOK, "application/json; charset=utf-8", productsJson)
 }
 
-// Get returns the Product by id.
-func (pc *controller) Get(c *gin.Context) {
+// ListBySeller returns many products of the Seller as per page and number of results.
+func (pc *controller) ListBySeller(c *gin.Context) {
 	request := &struct {
-		UUID string `form:"id" binding:"required"`
+		Page int `form:"page,default=1"`
+	}{}
+
+	if err := c.ShouldBindQuery(request); err != nil {
+		apierror.RespondBinding(c, err, request)
+		return
+	}
+
+	sellerUUID := c.Param("uuid")
+	ctx := logging.WithStr(c.Request.Context(), "seller_uuid", sellerUUID)
+
+	seller, err := pc.sellerRepository.FindByUUID(ctx, sellerUUID)
+
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to query seller by UUID")
+		respondError(c, err, "Fail to query seller by UUID")
+		return
+	}
+
+	if seller == nil {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeSellerNotFound, "Seller is not found")
+		return
+	}
+
+	products, err := pc.finder.listBySeller(ctx, seller.UUID, (request.Page-1)*listPageSize, listPageSize)
+
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to query product list of seller")
+		respondError(c, err, "Fail to query product list of seller")
+		return
+	}
+
+	if !pc.bindView(ctx, c, products...) {
+		return
+	}
+
+	productsJson, err := marshalJSON(c, products)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to marshal products")
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternalServerError, "Fail to marshal products")
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", productsJson)
+}
+
+// Export streams all the products, optionally filtered, as NDJSON or CSV in the v1 or v2 representation,
+// gzipped when the client accepts it. The products are read by a cursor and written as they are read,
+// so that the memory does not grow with the catalog.
+func (pc *controller) Export(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	request := &struct {
+		Format         string `form:"format,default=ndjson" binding:"oneof=ndjson csv"`
+		Representation string `form:"representation,default=v2" binding:"oneof=v1 v2"`
+		Seller         string `form:"seller"`
+		Brand          string `form:"brand"`
+		InStock        bool   `form:"in_stock"`
 	}{}
 
 	if err := c.ShouldBindQuery(request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.RespondBinding(c, err, request)
+		return
+	}
+
+	w := newExportWriter(c, request.Format, request.Representation, acceptsGzip(c.Request))
+
+	err := pc.finder.export(ctx, exportFilter{SellerUUID: request.Seller, Brand: request.Brand, InStock: request.InStock}, w.write)
+	if err == nil {
+		err = w.close()
+	}
+
+	if err == nil {
+		return
+	}
+
+	log.Ctx(ctx).Error().Err(err).Int("exported", w.count).Msg("Fail to export products")
+
+	if !w.started {
+		respondError(c, err, "Fail to export products")
 		return
 	}
 
-	product, err := pc.finderByUUID.findByUUID(request.UUID)
+	// NOTE - the status is sent already, the connection is aborted so that the client does not take
+	// the truncated export for the complete one.
+	panic(http.ErrAbortHandler)
+}
+
+// Get returns the Product by id.
+func (pc *controller) Get(c *gin.Context) {
+	uuid, ok := bindProductUUID(c)
+	if !ok {
+		return
+	}
+
+	ctx := logging.WithStr(c.Request.Context(), "product_uuid", uuid)
+
+	product, err := pc.finderByUUID.findByUUID(ctx, uuid)
 
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to query product by uuid")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query product by uuid"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to query product by uuid")
+		respondError(c, err, "Fail to query product by uuid")
+		return
+	}
+
+	// NOTE - v1 responds null for the missing product.
+	if product == nil && !isV1(c) {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound, "Product is not found")
+		return
+	}
+
+	if !pc.bindView(ctx, c, product) {
 		return
 	}
 
 	jsonData, err := marshalJSON(c, product)
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to marshal product")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal product"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to marshal product")
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternalServerError, "Fail to marshal product")
 		return
 	}
 
@@ -140,6 +464,8 @@ func (pc *controller) Get(c *gin.Context) {
 
 // Post creates and returns the Product.
 func (pc *controller) Post(c *gin.Context) {
+	ctx := c.Request.Context()
+
 	request := &struct {
 		Name   string `form:"name"`
 		Brand  string `form:"brand"`
@@ -148,45 +474,74 @@ func (pc *controller) Post(c *gin.Context) {
 	}{}
 
 	if err := c.ShouldBindJSON(request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.RespondBinding(c, err, request)
 		return
 	}
 
-	seller, err := pc.sellerRepository.FindByUUID(request.Seller)
-
-	if err != nil {
-		log.Error().Err(err).Msg("Fail to query seller by UUID")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query seller by UUID"})
+	if !authorizeSeller(c, request.Seller) {
 		return
 	}
 
-	if seller == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Seller is not found"})
-		return
-	}
+	var (
+		seller   *sellerAPI.Seller
+		inserted *product
+		fallback string
+	)
+
+	// NOTE - the seller is looked up and its product inserted in a transaction, which is retried as a
+	// whole on deadlock. The seller is not locked: when it is deleted in between, the insert fails by
+	// the foreign key of the product and responds seller_not_found, as the missing seller does.
+	err := pc.do(ctx, func(tx *Tx) (err error) {
+		fallback = "Fail to query seller by UUID"
+
+		seller, err = tx.Sellers.FindByUUID(ctx, request.Seller)
+		if err != nil || seller == nil {
+			return err
+		}
+
+		fallback = "Fail to insert product"
 
-	product := &product{
 		// NOTE - removing UUID generation from controller, as it is a responsibility of
 		// repository, and will make the controller also testable.
-		Name:       request.Name,
-		Brand:      request.Brand,
-		Stock:      request.Stock,
-		SellerUUID: seller.UUID,
-	}
+		inserted, err = tx.Products.insert(ctx, &product{
+			Name:       request.Name,
+			Brand:      request.Brand,
+			Stock:      request.Stock,
+			SellerUUID: seller.UUID,
+		})
 
-	product, err = pc.inserter.insert(product)
+		return err
+	})
 
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to insert product")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to insert product"})
+		log.Ctx(ctx).Error().Err(err).Msg(fallback)
+		respondError(c, err, fallback)
 		return
 	}
 
-	jsonData, err := marshalJSON(c, product)
+	if seller == nil {
+		// NOTE - v1 responds 400 for the missing seller.
+		status := http.StatusUnprocessableEntity
+		if isV1(c) {
+			status = http.StatusBadRequest
+		}
+
+		apierror.Respond(c, status, apierror.CodeSellerNotFound, "Seller is not found")
+		return
+	}
+
+	jsonData, err := marshalJSON(c, inserted)
 
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to marshal product")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal product"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to marshal product")
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternalServerError, "Fail to marshal product")
+		return
+	}
+
+	// NOTE - v3 responds 201 with the location of the created product.
+	if isV3(c) {
+		c.Header("Location", productPath(inserted.UUID))
+		c.Data(http.StatusCreated, "application/json; charset=utf-8", jsonData)
 		return
 	}
 
@@ -194,21 +549,31 @@ func (pc *controller) Post(c *gin.Context) {
 }
 
 // Put updates the Product.
+//
+// NOTE - unlike Post it does not run in the unit of work: the product is written by a single UPDATE,
+// the last write wins, and the sellers are notified after it, which must not hold a transaction open.
 func (pc *controller) Put(c *gin.Context) {
-	queryRequest := &struct {
-		UUID string `form:"id" binding:"required"`
-	}{}
-
-	if err := c.ShouldBindQuery(queryRequest); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	uuid, ok := bindProductUUID(c)
+	if !ok {
 		return
 	}
 
-	product, err := pc.finderByUUID.findByUUID(queryRequest.UUID)
+	ctx := logging.WithStr(c.Request.Context(), "product_uuid", uuid)
+
+	product, err := pc.finderByUUID.findByUUID(ctx, uuid)
 
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to query product by uuid")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query product by uuid"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to query product by uuid")
+		respondError(c, err, "Fail to query product by uuid")
+		return
+	}
+
+	if product == nil {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound, "Product is not found")
+		return
+	}
+
+	if !authorizeSeller(c, product.SellerUUID) {
 		return
 	}
 
@@ -219,7 +584,7 @@ func (pc *controller) Put(c *gin.Context) {
 	}{}
 
 	if err := c.ShouldBindJSON(request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		apierror.RespondBinding(c, err, request)
 		return
 	}
 
@@ -229,74 +594,168 @@ func (pc *controller) Put(c *gin.Context) {
 	product.Brand = request.Brand
 	product.Stock = request.Stock
 
-	err = pc.updater.update(product)
+	err = pc.updater.update(ctx, product)
 
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to insert product")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to insert product"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to update product")
+		respondError(c, err, "Fail to update product")
 		return
 	}
 
-	if oldStock != product.Stock {
-		seller, err := pc.sellerRepository.FindByUUID(product.SellerUUID)
+	if err := pc.notifyStockChanged(ctx, product, oldStock); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to query seller by UUID")
+		respondError(c, err, "Fail to query seller by UUID")
+		return
+	}
 
-		if err != nil {
-			log.Error().Err(err).Msg("Fail to query seller by UUID")
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query seller by UUID"})
-			return
-		}
+	jsonData, err := marshalJSON(c, product)
 
-		// Note - The StockChanged signature seems to me wrong, it was expecting product name and it was sending
-		// email, so i changed it to incorporate the logging the correct information.
-		if pc.emailProvider != nil {
-			pc.emailProvider.StockChanged(seller.UUID, seller.Email, oldStock, product.Stock, product.Name)
-		}
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to marshal product")
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternalServerError, "Fail to marshal product")
+		return
+	}
 
-		if pc.smsProvider != nil {
-			pc.smsProvider.StockChanged(seller.UUID, seller.Phone, oldStock, product.Stock, product.Name)
-		}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
+}
+
+// AdjustStock adjusts the stock of the Product by the delta, e.g. -1 for a sold item. Unlike Put,
+// the concurrent adjustments are not lost, and the stock never becomes negative.
+//
+// NOTE - it does not run in the unit of work either, as the adjustment is a single conditional
+// UPDATE, which is atomic by itself.
+func (pc *controller) AdjustStock(c *gin.Context) {
+	uuid, ok := bindProductUUID(c)
+	if !ok {
+		return
+	}
+
+	ctx := logging.WithStr(c.Request.Context(), "product_uuid", uuid)
+
+	product, err := pc.finderByUUID.findByUUID(ctx, uuid)
+
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to query product by uuid")
+		respondError(c, err, "Fail to query product by uuid")
+		return
+	}
+
+	if product == nil {
+		apierror.Respond(c, http.StatusNotFound, apierror.CodeProductNotFound, "Product is not found")
+		return
+	}
+
+	if !authorizeSeller(c, product.SellerUUID) {
+		return
+	}
+
+	request := &struct {
+		Delta int `form:"delta" binding:"required"`
+	}{}
+
+	if err := c.ShouldBindJSON(request); err != nil {
+		apierror.RespondBinding(c, err, request)
+		return
+	}
+
+	err = pc.updater.adjustStock(ctx, product, request.Delta)
+
+	if errors.Is(err, ErrInsufficientStock) {
+		apierror.Respond(c, http.StatusConflict, apierror.CodeInsufficientStock, "Stock is insufficient")
+		return
+	}
+
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to adjust stock of product")
+		respondError(c, err, "Fail to adjust stock of product")
+		return
+	}
+
+	if err := pc.notifyStockChanged(ctx, product, product.Stock-request.Delta); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to query seller by UUID")
+		respondError(c, err, "Fail to query seller by UUID")
+		return
 	}
 
 	jsonData, err := marshalJSON(c, product)
 
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to marshal product")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal product"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to marshal product")
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternalServerError, "Fail to marshal product")
 		return
 	}
 
 	c.Data(http.StatusOK, "application/json; charset=utf-8", jsonData)
 }
 
+// notifyStockChanged notifies the seller of the product when its stock is changed from the old stock.
+func (pc *controller) notifyStockChanged(ctx context.Context, product *product, oldStock int) error {
+	if oldStock == product.Stock {
+		return nil
+	}
+
+	seller, err := pc.sellerRepository.FindByUUID(ctx, product.SellerUUID)
+
+	if err != nil {
+		return err
+	}
+
+	// Note - The StockChanged signature seems to me wrong, it was expecting product name and it was sending
+	// email, so i changed it to incorporate the logging the correct information.
+	if pc.emailProvider != nil {
+		pc.emailProvider.StockChanged(ctx, seller.UUID, seller.Email, oldStock, product.Stock, product.Name)
+	}
+
+	if pc.smsProvider != nil {
+		pc.smsProvider.StockChanged(ctx, seller.UUID, seller.Phone, oldStock, product.Stock, product.Name)
+	}
+
+	return nil
+}
+
 // Delete deletes the product.
 func (pc *controller) Delete(c *gin.Context) {
-	request := &struct {
-		UUID string `form:"id" binding:"required"`
-	}{}
-
-	if err := c.ShouldBindQuery(request); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	uuid, ok := bindProductUUID(c)
+	if !ok {
 		return
 	}
 
-	product, err := pc.finderByUUID.findByUUID(request.UUID)
+	ctx := logging.WithStr(c.Request.Context(), "product_uuid", uuid)
+
+	product, err := pc.finderByUUID.findByUUID(ctx, uuid)
 
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to query product by uuid")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query product by uuid"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to query product by uuid")
+		respondError(c, err, "Fail to query product by uuid")
 		return
 	}
 
 	if product == nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Product is not found"})
+		// NOTE - v1 responds 400 for the missing product.
+		status := http.StatusNotFound
+		if isV1(c) {
+			status = http.StatusBadRequest
+		}
+
+		apierror.Respond(c, status, apierror.CodeProductNotFound, "Product is not found")
+		return
+	}
+
+	if !authorizeSeller(c, product.SellerUUID) {
 		return
 	}
 
-	err = pc.deleter.delete(product)
+	err = pc.deleter.delete(ctx, product)
 
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to delete product")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to delete product"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to delete product")
+		respondError(c, err, "Fail to delete product")
+		return
+	}
+
+	// NOTE - v3 responds 204 without body.
+	if isV3(c) {
+		c.Status(http.StatusNoContent)
 		return
 	}
 
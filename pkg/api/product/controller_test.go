@@ -2,13 +2,18 @@ package product
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"coding-challenge-go/pkg/api/middleware"
 	sellerAPI "coding-challenge-go/pkg/api/seller"
+	"coding-challenge-go/pkg/auth"
+	"coding-challenge-go/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -20,13 +25,13 @@ type DeleterMock struct {
 	mock.Mock
 }
 
-// delete provides a mock function with given fields: _a0
-func (_m *DeleterMock) delete(_a0 *product) error {
-	ret := _m.Called(_a0)
+// delete provides a mock function with given fields: ctx, _a1
+func (_m *DeleterMock) delete(ctx context.Context, _a1 *product) error {
+	ret := _m.Called(ctx, _a1)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*product) error); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, *product) error); ok {
+		r0 = rf(ctx, _a1)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -39,13 +44,13 @@ type FinderByUUIDMock struct {
 	mock.Mock
 }
 
-// findByUUID provides a mock function with given fields: uuid
-func (_m *FinderByUUIDMock) findByUUID(uuid string) (*product, error) {
-	ret := _m.Called(uuid)
+// findByUUID provides a mock function with given fields: ctx, uuid
+func (_m *FinderByUUIDMock) findByUUID(ctx context.Context, uuid string) (*product, error) {
+	ret := _m.Called(ctx, uuid)
 
 	var r0 *product
-	if rf, ok := ret.Get(0).(func(string) *product); ok {
-		r0 = rf(uuid)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *product); ok {
+		r0 = rf(ctx, uuid)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*product)
@@ -53,8 +58,8 @@ func (_m *FinderByUUIDMock) findByUUID(uuid string) (*product, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(uuid)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, uuid)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -67,13 +72,13 @@ type InserterMock struct {
 	mock.Mock
 }
 
-// insert provides a mock function with given fields: _a0
-func (_m *InserterMock) insert(_a0 *product) (*product, error) {
-	ret := _m.Called(_a0)
+// insert provides a mock function with given fields: ctx, _a1
+func (_m *InserterMock) insert(ctx context.Context, _a1 *product) (*product, error) {
+	ret := _m.Called(ctx, _a1)
 
 	var r0 *product
-	if rf, ok := ret.Get(0).(func(*product) *product); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, *product) *product); ok {
+		r0 = rf(ctx, _a1)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*product)
@@ -81,8 +86,29 @@ func (_m *InserterMock) insert(_a0 *product) (*product, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*product) error); ok {
-		r1 = rf(_a0)
+	if rf, ok := ret.Get(1).(func(context.Context, *product) error); ok {
+		r1 = rf(ctx, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// upsert provides a mock function with given fields: ctx, _a1
+func (_m *InserterMock) upsert(ctx context.Context, _a1 *product) (bool, error) {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, *product) bool); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Bool(0)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, *product) error); ok {
+		r1 = rf(ctx, _a1)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -95,13 +121,36 @@ type ManyFinderMock struct {
 	mock.Mock
 }
 
-// list provides a mock function with given fields: offset, limit
-func (_m *ManyFinderMock) list(offset int, limit int) ([]*product, error) {
-	ret := _m.Called(offset, limit)
+// list provides a mock function with given fields: ctx, offset, limit
+func (_m *ManyFinderMock) list(ctx context.Context, offset int, limit int) ([]*product, error) {
+	ret := _m.Called(ctx, offset, limit)
+
+	var r0 []*product
+	if rf, ok := ret.Get(0).(func(context.Context, int, int) []*product); ok {
+		r0 = rf(ctx, offset, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*product)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, int, int) error); ok {
+		r1 = rf(ctx, offset, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// listBySeller provides a mock function with given fields: ctx, sellerUUID, offset, limit
+func (_m *ManyFinderMock) listBySeller(ctx context.Context, sellerUUID string, offset int, limit int) ([]*product, error) {
+	ret := _m.Called(ctx, sellerUUID, offset, limit)
 
 	var r0 []*product
-	if rf, ok := ret.Get(0).(func(int, int) []*product); ok {
-		r0 = rf(offset, limit)
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, int) []*product); ok {
+		r0 = rf(ctx, sellerUUID, offset, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*product)
@@ -109,8 +158,8 @@ func (_m *ManyFinderMock) list(offset int, limit int) ([]*product, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int, int) error); ok {
-		r1 = rf(offset, limit)
+	if rf, ok := ret.Get(1).(func(context.Context, string, int, int) error); ok {
+		r1 = rf(ctx, sellerUUID, offset, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -118,18 +167,32 @@ func (_m *ManyFinderMock) list(offset int, limit int) ([]*product, error) {
 	return r0, r1
 }
 
+// export provides a mock function with given fields: ctx, filter, each
+func (_m *ManyFinderMock) export(ctx context.Context, filter exportFilter, each func(*product) error) error {
+	ret := _m.Called(ctx, filter, each)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, exportFilter, func(*product) error) error); ok {
+		r0 = rf(ctx, filter, each)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SellerFinderMock is an autogenerated mock type for the SellerFinder type
 type SellerFinderMock struct {
 	mock.Mock
 }
 
-// FindByUUID provides a mock function with given fields: uuid
-func (_m *SellerFinderMock) FindByUUID(uuid string) (*sellerAPI.Seller, error) {
-	ret := _m.Called(uuid)
+// FindByUUID provides a mock function with given fields: ctx, uuid
+func (_m *SellerFinderMock) FindByUUID(ctx context.Context, uuid string) (*sellerAPI.Seller, error) {
+	ret := _m.Called(ctx, uuid)
 
 	var r0 *sellerAPI.Seller
-	if rf, ok := ret.Get(0).(func(string) *sellerAPI.Seller); ok {
-		r0 = rf(uuid)
+	if rf, ok := ret.Get(0).(func(context.Context, string) *sellerAPI.Seller); ok {
+		r0 = rf(ctx, uuid)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*sellerAPI.Seller)
@@ -137,8 +200,31 @@ func (_m *SellerFinderMock) FindByUUID(uuid string) (*sellerAPI.Seller, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(uuid)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, uuid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByUUIDs provides a mock function with given fields: ctx, uuids
+func (_m *SellerFinderMock) FindByUUIDs(ctx context.Context, uuids []string) ([]*sellerAPI.Seller, error) {
+	ret := _m.Called(ctx, uuids)
+
+	var r0 []*sellerAPI.Seller
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []*sellerAPI.Seller); ok {
+		r0 = rf(ctx, uuids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*sellerAPI.Seller)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, uuids)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -151,9 +237,9 @@ type StockChangedNotifierMock struct {
 	mock.Mock
 }
 
-// StockChanged provides a mock function with given fields: sellerUUID, sellerReceiverID, oldStock, newStock, _a4
-func (_m *StockChangedNotifierMock) StockChanged(sellerUUID string, sellerReceiverID string, oldStock int, newStock int, _a4 string) {
-	_m.Called(sellerUUID, sellerReceiverID, oldStock, newStock, _a4)
+// StockChanged provides a mock function with given fields: ctx, sellerUUID, sellerReceiverID, oldStock, newStock, _a5
+func (_m *StockChangedNotifierMock) StockChanged(ctx context.Context, sellerUUID string, sellerReceiverID string, oldStock int, newStock int, _a5 string) {
+	_m.Called(ctx, sellerUUID, sellerReceiverID, oldStock, newStock, _a5)
 }
 
 // UpdaterMock is an autogenerated mock type for the Updater type
@@ -161,13 +247,27 @@ type UpdaterMock struct {
 	mock.Mock
 }
 
-// update provides a mock function with given fields: _a0
-func (_m *UpdaterMock) update(_a0 *product) error {
-	ret := _m.Called(_a0)
+// update provides a mock function with given fields: ctx, _a1
+func (_m *UpdaterMock) update(ctx context.Context, _a1 *product) error {
+	ret := _m.Called(ctx, _a1)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, *product) error); ok {
+		r0 = rf(ctx, _a1)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// adjustStock provides a mock function with given fields: ctx, _a1, delta
+func (_m *UpdaterMock) adjustStock(ctx context.Context, _a1 *product, delta int) error {
+	ret := _m.Called(ctx, _a1, delta)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*product) error); ok {
-		r0 = rf(_a0)
+	if rf, ok := ret.Get(0).(func(context.Context, *product, int) error); ok {
+		r0 = rf(ctx, _a1, delta)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -216,7 +316,7 @@ func Test_controller_List(t *testing.T) {
 							SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
 						},
 					}
-					m.On("list", 0, 10).Return(p, nil)
+					m.On("list", mock.Anything, 0, 10).Return(p, nil)
 					return m
 				}(),
 			},
@@ -224,6 +324,110 @@ func Test_controller_List(t *testing.T) {
 			path:      "/api/v2/products",
 			expBody:   `[{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":10,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"self":{"href":"/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0"}}}},{"uuid":"36345687-e998-4359-a2ed-a9703fe39b5f","name":"socks","brand":"adidas","stock":15,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"self":{"href":"/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0"}}}}]`,
 		},
+		{
+			name: "v2: Returns the selected fields with the embedded Sellers queried at once",
+			fields: fields{
+				finder: func() ManyFinder {
+					m := new(ManyFinderMock)
+					p := []*product{
+						{
+							ProductID:  1,
+							Name:       "shoes",
+							UUID:       "61981e52-e1ca-449e-b79f-01d5906b3435",
+							Brand:      "nike",
+							Stock:      10,
+							SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+						},
+						{
+							ProductID:  2,
+							Name:       "socks",
+							UUID:       "36345687-e998-4359-a2ed-a9703fe39b5f",
+							Brand:      "adidas",
+							Stock:      15,
+							SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+						},
+					}
+					m.On("list", mock.Anything, 0, 10).Return(p, nil)
+					return m
+				}(),
+				sellerRepository: func() SellerFinder {
+					m := new(SellerFinderMock)
+					s := []*sellerAPI.Seller{{
+						UUID:  "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+						Name:  "david",
+						Email: "d@example.com",
+						Phone: "324-3243-32",
+					}}
+					m.On("FindByUUIDs", mock.Anything, []string{"a223850e-d8ab-430a-9a1a-28628cfd52b0"}).Return(s, nil).Once()
+					return m
+				}(),
+			},
+			expStatus: http.StatusOK,
+			path:      "/api/v2/products?fields=uuid,name&embed=seller",
+			expBody:   `[{"_embedded":{"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","name":"david"}},"name":"shoes","uuid":"61981e52-e1ca-449e-b79f-01d5906b3435"},{"_embedded":{"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","name":"david"}},"name":"socks","uuid":"36345687-e998-4359-a2ed-a9703fe39b5f"}]`,
+		},
+		{
+			name: "v2: Returns 400, when unknown field is selected",
+			fields: fields{
+				finder: func() ManyFinder {
+					m := new(ManyFinderMock)
+					p := []*product{
+						{
+							ProductID:  1,
+							Name:       "shoes",
+							UUID:       "61981e52-e1ca-449e-b79f-01d5906b3435",
+							Brand:      "nike",
+							Stock:      10,
+							SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+						},
+						{
+							ProductID:  2,
+							Name:       "socks",
+							UUID:       "36345687-e998-4359-a2ed-a9703fe39b5f",
+							Brand:      "adidas",
+							Stock:      15,
+							SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+						},
+					}
+					m.On("list", mock.Anything, 0, 10).Return(p, nil)
+					return m
+				}(),
+			},
+			expStatus: http.StatusBadRequest,
+			path:      "/api/v2/products?fields=uuid,price",
+			expBody:   `{"type":"https://api.gfg.com/problems/validation_failed","title":"Bad Request","status":400,"detail":"Unknown field price","instance":"/api/v2/products?fields=uuid,price","code":"validation_failed","errors":[{"field":"fields","rule":"oneof","detail":"Unknown field price"}]}`,
+		},
+		{
+			name: "v2: Returns 400, when unknown resource is embedded",
+			fields: fields{
+				finder: func() ManyFinder {
+					m := new(ManyFinderMock)
+					p := []*product{
+						{
+							ProductID:  1,
+							Name:       "shoes",
+							UUID:       "61981e52-e1ca-449e-b79f-01d5906b3435",
+							Brand:      "nike",
+							Stock:      10,
+							SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+						},
+						{
+							ProductID:  2,
+							Name:       "socks",
+							UUID:       "36345687-e998-4359-a2ed-a9703fe39b5f",
+							Brand:      "adidas",
+							Stock:      15,
+							SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+						},
+					}
+					m.On("list", mock.Anything, 0, 10).Return(p, nil)
+					return m
+				}(),
+			},
+			expStatus: http.StatusBadRequest,
+			path:      "/api/v2/products?embed=brand",
+			expBody:   `{"type":"https://api.gfg.com/problems/validation_failed","title":"Bad Request","status":400,"detail":"Key: 'Embed' Error:Field validation for 'Embed' failed on the 'oneof' tag","instance":"/api/v2/products?embed=brand","code":"validation_failed","errors":[{"field":"embed","rule":"oneof","detail":"Key: 'Embed' Error:Field validation for 'Embed' failed on the 'oneof' tag"}]}`,
+		},
 		{
 			name: "v1: Returns 200OK",
 			fields: fields{
@@ -247,7 +451,7 @@ func Test_controller_List(t *testing.T) {
 							SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
 						},
 					}
-					m.On("list", 0, 10).Return(p, nil)
+					m.On("list", mock.Anything, 0, 10).Return(p, nil)
 					return m
 				}(),
 			},
@@ -260,7 +464,7 @@ func Test_controller_List(t *testing.T) {
 			fields: fields{
 				finder: func() ManyFinder {
 					m := new(ManyFinderMock)
-					m.On("list", -20, 10).Return(nil, errors.New("any error"))
+					m.On("list", mock.Anything, -20, 10).Return(nil, errors.New("any error"))
 					return m
 				}(),
 			},
@@ -273,7 +477,7 @@ func Test_controller_List(t *testing.T) {
 			fields: fields{
 				finder: func() ManyFinder {
 					m := new(ManyFinderMock)
-					m.On("list", 0, 10).Return(nil, errors.New("any error"))
+					m.On("list", mock.Anything, 0, 10).Return(nil, errors.New("any error"))
 					return m
 				}(),
 			},
@@ -281,18 +485,31 @@ func Test_controller_List(t *testing.T) {
 			path:      "/api/v1/products",
 			expBody:   `{"error":"Fail to query product list"}`,
 		},
+		{
+			name: "v1: Returns 504, when repository query times out",
+			fields: fields{
+				finder: func() ManyFinder {
+					m := new(ManyFinderMock)
+					m.On("list", mock.Anything, 0, 10).Return(nil, context.DeadlineExceeded)
+					return m
+				}(),
+			},
+			expStatus: http.StatusGatewayTimeout,
+			path:      "/api/v1/products",
+			expBody:   `{"error":"Fail to query product list"}`,
+		},
 		{
 			name: "v2: Returns 500",
 			fields: fields{
 				finder: func() ManyFinder {
 					m := new(ManyFinderMock)
-					m.On("list", 0, 10).Return(nil, errors.New("any error"))
+					m.On("list", mock.Anything, 0, 10).Return(nil, errors.New("any error"))
 					return m
 				}(),
 			},
 			expStatus: http.StatusInternalServerError,
 			path:      "/api/v2/products",
-			expBody:   `{"error":"Fail to query product list"}`,
+			expBody:   `{"type":"https://api.gfg.com/problems/internal_server_error","title":"Internal Server Error","status":500,"detail":"Fail to query product list","instance":"/api/v2/products","code":"internal_server_error"}`,
 		},
 	}
 	for _, tt := range tests {
@@ -359,7 +576,7 @@ func Test_controller_Get(t *testing.T) {
 						Stock:      10,
 						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
 					}
-					m.On("findByUUID", "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
 					return m
 				}(),
 			},
@@ -380,7 +597,7 @@ func Test_controller_Get(t *testing.T) {
 						Stock:      10,
 						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
 					}
-					m.On("findByUUID", "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
 					return m
 				}(),
 			},
@@ -388,11 +605,71 @@ func Test_controller_Get(t *testing.T) {
 			path:      "/api/v1/product?id=61981e52-e1ca-449e-b79f-01d5906b3435",
 			expBody:   `{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":10,"seller_uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0"}`,
 		},
+		{
+			name: "v2: Returns 404, when Product is not found",
+			fields: fields{
+				finderByUUID: func() FinderByUUID {
+					m := new(FinderByUUIDMock)
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(nil, nil)
+					return m
+				}(),
+			},
+			expStatus: http.StatusNotFound,
+			path:      "/api/v2/product?id=61981e52-e1ca-449e-b79f-01d5906b3435",
+			expBody:   `{"type":"https://api.gfg.com/problems/product_not_found","title":"Not Found","status":404,"detail":"Product is not found","instance":"/api/v2/product?id=61981e52-e1ca-449e-b79f-01d5906b3435","code":"product_not_found"}`,
+		},
+		{
+			name: "v3: Returns 200OK",
+			fields: fields{
+				finderByUUID: func() FinderByUUID {
+					m := new(FinderByUUIDMock)
+					p := &product{
+						ProductID:  1,
+						Name:       "shoes",
+						UUID:       "61981e52-e1ca-449e-b79f-01d5906b3435",
+						Brand:      "nike",
+						Stock:      10,
+						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+					}
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
+					return m
+				}(),
+			},
+			expStatus: http.StatusOK,
+			path:      "/api/v3/products/61981e52-e1ca-449e-b79f-01d5906b3435",
+			expBody:   `{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":10,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"products":{"href":"/api/v3/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0/products"}}},"_links":{"self":{"href":"/api/v3/products/61981e52-e1ca-449e-b79f-01d5906b3435"}}}`,
+		},
+		{
+			name: "v3: Returns 404, when Product is not found",
+			fields: fields{
+				finderByUUID: func() FinderByUUID {
+					m := new(FinderByUUIDMock)
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(nil, nil)
+					return m
+				}(),
+			},
+			expStatus: http.StatusNotFound,
+			path:      "/api/v3/products/61981e52-e1ca-449e-b79f-01d5906b3435",
+			expBody:   `{"type":"https://api.gfg.com/problems/product_not_found","title":"Not Found","status":404,"detail":"Product is not found","instance":"/api/v3/products/61981e52-e1ca-449e-b79f-01d5906b3435","code":"product_not_found"}`,
+		},
+		{
+			name: "v1: Returns null, when Product is not found",
+			fields: fields{
+				finderByUUID: func() FinderByUUID {
+					m := new(FinderByUUIDMock)
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(nil, nil)
+					return m
+				}(),
+			},
+			expStatus: http.StatusOK,
+			path:      "/api/v1/product?id=61981e52-e1ca-449e-b79f-01d5906b3435",
+			expBody:   `null`,
+		},
 		{
 			name:      "v2: Returns 400, when UUID is not sent",
 			expStatus: http.StatusBadRequest,
 			path:      "/api/v2/product",
-			expBody:   `{"error":"Key: 'UUID' Error:Field validation for 'UUID' failed on the 'required' tag"}`,
+			expBody:   `{"type":"https://api.gfg.com/problems/validation_failed","title":"Bad Request","status":400,"detail":"Key: 'UUID' Error:Field validation for 'UUID' failed on the 'required' tag","instance":"/api/v2/product","code":"validation_failed","errors":[{"field":"id","rule":"required","detail":"Key: 'UUID' Error:Field validation for 'UUID' failed on the 'required' tag"}]}`,
 		},
 		{
 			name:      "v1: Returns 400, when UUID is not sent",
@@ -405,7 +682,7 @@ func Test_controller_Get(t *testing.T) {
 			fields: fields{
 				finderByUUID: func() FinderByUUID {
 					m := new(FinderByUUIDMock)
-					m.On("findByUUID", "somethingwrong").Return(nil, errors.New("any error"))
+					m.On("findByUUID", mock.Anything, "somethingwrong").Return(nil, errors.New("any error"))
 					return m
 				}(),
 			},
@@ -430,6 +707,7 @@ func Test_controller_Get(t *testing.T) {
 			r.Use(middleware.APIVersionResolver)
 			r.GET("/api/v1/product", pc.Get)
 			r.GET("/api/v2/product", pc.Get)
+			r.GET("/api/v3/products/:uuid", pc.Get)
 
 			t.Run(tt.name, func(t *testing.T) {
 				w := httptest.NewRecorder()
@@ -459,12 +737,13 @@ func Test_controller_Insert(t *testing.T) {
 		smsProvider      StockChangedNotifier
 	}
 	tests := []struct {
-		name      string
-		fields    fields
-		expStatus int
-		path      string
-		body      string
-		expBody   string
+		name        string
+		fields      fields
+		expStatus   int
+		path        string
+		body        string
+		expBody     string
+		expLocation string
 	}{
 		{
 			name: "v1: inserts Product, returns 200",
@@ -477,7 +756,7 @@ func Test_controller_Insert(t *testing.T) {
 						Email: "d@example.com",
 						Phone: "324-3243-32",
 					}
-					m.On("FindByUUID", "a223850e-d8ab-430a-9a1a-28628cfd52b0").
+					m.On("FindByUUID", mock.Anything, "a223850e-d8ab-430a-9a1a-28628cfd52b0").
 						Return(s, nil)
 					return m
 				}(),
@@ -496,7 +775,7 @@ func Test_controller_Insert(t *testing.T) {
 						Stock:      10,
 						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
 					}
-					m.On("insert", p).Return(pWithUUID, nil)
+					m.On("insert", mock.Anything, p).Return(pWithUUID, nil)
 					return m
 				}(),
 			},
@@ -505,6 +784,40 @@ func Test_controller_Insert(t *testing.T) {
 			path:      "/api/v1/product",
 			expBody:   `{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":10,"seller_uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0"}`,
 		},
+		{
+			name: "v3: inserts Product, returns 201 with its location",
+			fields: fields{
+				sellerRepository: func() SellerFinder {
+					m := new(SellerFinderMock)
+					s := &sellerAPI.Seller{
+						UUID:  "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+						Name:  "david",
+						Email: "d@example.com",
+						Phone: "324-3243-32",
+					}
+					m.On("FindByUUID", mock.Anything, "a223850e-d8ab-430a-9a1a-28628cfd52b0").
+						Return(s, nil)
+					return m
+				}(),
+				inserter: func() Inserter {
+					m := new(InserterMock)
+					pWithUUID := &product{
+						Name:       "shoes",
+						UUID:       "61981e52-e1ca-449e-b79f-01d5906b3435",
+						Brand:      "nike",
+						Stock:      10,
+						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+					}
+					m.On("insert", mock.Anything, mock.Anything).Return(pWithUUID, nil)
+					return m
+				}(),
+			},
+			body:        `{"name":"shoes","brand":"nike","stock":10,"seller":"a223850e-d8ab-430a-9a1a-28628cfd52b0"}`,
+			expStatus:   http.StatusCreated,
+			path:        "/api/v3/products",
+			expBody:     `{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":10,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"products":{"href":"/api/v3/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0/products"}}},"_links":{"self":{"href":"/api/v3/products/61981e52-e1ca-449e-b79f-01d5906b3435"}}}`,
+			expLocation: "/api/v3/products/61981e52-e1ca-449e-b79f-01d5906b3435",
+		},
 		{
 			name: "v2: inserts Product, returns 200",
 			fields: fields{
@@ -516,7 +829,7 @@ func Test_controller_Insert(t *testing.T) {
 						Email: "d@example.com",
 						Phone: "324-3243-32",
 					}
-					m.On("FindByUUID", "a223850e-d8ab-430a-9a1a-28628cfd52b0").
+					m.On("FindByUUID", mock.Anything, "a223850e-d8ab-430a-9a1a-28628cfd52b0").
 						Return(s, nil)
 					return m
 				}(),
@@ -535,7 +848,7 @@ func Test_controller_Insert(t *testing.T) {
 						Stock:      10,
 						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
 					}
-					m.On("insert", p).Return(pWithUUID, nil)
+					m.On("insert", mock.Anything, p).Return(pWithUUID, nil)
 					return m
 				}(),
 			},
@@ -544,6 +857,27 @@ func Test_controller_Insert(t *testing.T) {
 			path:      "/api/v2/product",
 			expBody:   `{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":10,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"self":{"href":"/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0"}}}}`,
 		},
+		{
+			name: "v1: Seller deleted meanwhile, returns 422",
+			fields: fields{
+				sellerRepository: func() SellerFinder {
+					m := new(SellerFinderMock)
+					s := &sellerAPI.Seller{UUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0"}
+					m.On("FindByUUID", mock.Anything, "a223850e-d8ab-430a-9a1a-28628cfd52b0").
+						Return(s, nil)
+					return m
+				}(),
+				inserter: func() Inserter {
+					m := new(InserterMock)
+					m.On("insert", mock.Anything, mock.Anything).Return(nil, storage.ErrForeignKey)
+					return m
+				}(),
+			},
+			body:      `{"name":"shoes","brand":"nike","stock":10,"seller":"a223850e-d8ab-430a-9a1a-28628cfd52b0"}`,
+			expStatus: http.StatusUnprocessableEntity,
+			path:      "/api/v1/product",
+			expBody:   `{"error":"Seller is not found"}`,
+		},
 		{
 			name:      "v1: Product, returns 400 with empty body",
 			body:      "",
@@ -556,7 +890,7 @@ func Test_controller_Insert(t *testing.T) {
 			body:      "",
 			expStatus: http.StatusBadRequest,
 			path:      "/api/v2/product",
-			expBody:   `{"error":"EOF"}`,
+			expBody:   `{"type":"https://api.gfg.com/problems/invalid_request","title":"Bad Request","status":400,"detail":"EOF","instance":"/api/v2/product","code":"invalid_request"}`,
 		},
 	}
 	for _, tt := range tests {
@@ -575,6 +909,7 @@ func Test_controller_Insert(t *testing.T) {
 			r.Use(middleware.APIVersionResolver)
 			r.POST("/api/v2/product", pc.Post)
 			r.POST("/api/v1/product", pc.Post)
+			r.POST("/api/v3/products", pc.Post)
 
 			t.Run(tt.name, func(t *testing.T) {
 				w := httptest.NewRecorder()
@@ -586,15 +921,68 @@ func Test_controller_Insert(t *testing.T) {
 
 				assert.Equal(t, tt.expStatus, w.Code)
 				assert.Equal(t, tt.expBody, w.Body.String())
+				assert.Equal(t, tt.expLocation, w.Header().Get("Location"))
 			})
 
 		})
 	}
 }
 
-func Test_controller_Update(t *testing.T) {
-	type fields struct {
-		deleter          Deleter
+func Test_controller_InsertUnitOfWork(t *testing.T) {
+	sellerUUID := "a223850e-d8ab-430a-9a1a-28628cfd52b0"
+
+	tests := []struct {
+		name      string
+		commitErr error
+		expStatus int
+	}{
+		{name: "inserts the Product in the transaction", expStatus: http.StatusOK},
+		{name: "Returns 500, when the transaction fails", commitErr: errors.New("any sql error"), expStatus: http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sellers := new(SellerFinderMock)
+			sellers.On("FindByUUID", mock.Anything, sellerUUID).Return(&sellerAPI.Seller{UUID: sellerUUID}, nil)
+
+			inserter := new(InserterMock)
+			inserter.On("insert", mock.Anything, mock.Anything).Return(func(_ context.Context, p *product) *product {
+				p.UUID = "e943dc0a-98bb-47b4-9d1d-056b95d3f064"
+				return p
+			}, nil)
+
+			// the repositories of the controller are not used, the ones of the transaction are.
+			unitOfWork := new(UnitOfWorkMock)
+			unitOfWork.On("do", mock.Anything, mock.Anything).Return(func(_ context.Context, fn func(*Tx) error) error {
+				if err := fn(&Tx{Products: &controllerRepository{Inserter: inserter}, Sellers: sellers}); err != nil {
+					return err
+				}
+
+				return tt.commitErr
+			})
+
+			pc := NewController(nil, nil, nil, nil, nil, nil, nil, nil).WithUnitOfWork(unitOfWork)
+
+			r := gin.Default()
+			r.Use(middleware.APIVersionResolver)
+			r.POST("/api/v2/product", pc.Post)
+
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPost, "/api/v2/product", bytes.NewBufferString(`{"name":"shoes","brand":"nike","stock":10,"seller":"`+sellerUUID+`"}`))
+			assert.NoError(t, err)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expStatus, w.Code)
+			unitOfWork.AssertNumberOfCalls(t, "do", 1)
+			inserter.AssertNumberOfCalls(t, "insert", 1)
+		})
+	}
+}
+
+func Test_controller_Update(t *testing.T) {
+	type fields struct {
+		deleter          Deleter
 		updater          Updater
 		inserter         Inserter
 		finderByUUID     FinderByUUID
@@ -624,7 +1012,7 @@ func Test_controller_Update(t *testing.T) {
 						Stock:      10,
 						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
 					}
-					m.On("findByUUID", "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
 					return m
 				}(),
 				sellerRepository: func() SellerFinder {
@@ -635,7 +1023,7 @@ func Test_controller_Update(t *testing.T) {
 						Email: "d@example.com",
 						Phone: "324-3243-32",
 					}
-					m.On("FindByUUID", "a223850e-d8ab-430a-9a1a-28628cfd52b0").
+					m.On("FindByUUID", mock.Anything, "a223850e-d8ab-430a-9a1a-28628cfd52b0").
 						Return(s, nil)
 					return m
 				}(),
@@ -649,17 +1037,17 @@ func Test_controller_Update(t *testing.T) {
 						Stock:      20,
 						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
 					}
-					m.On("update", p).Return(nil)
+					m.On("update", mock.Anything, p).Return(nil)
 					return m
 				}(),
 				emailProvider: func() StockChangedNotifier {
 					m := new(StockChangedNotifierMock)
-					m.On("StockChanged", "a223850e-d8ab-430a-9a1a-28628cfd52b0", "d@example.com", 10, 20, "shoes")
+					m.On("StockChanged", mock.Anything, "a223850e-d8ab-430a-9a1a-28628cfd52b0", "d@example.com", 10, 20, "shoes")
 					return m
 				}(),
 				smsProvider: func() StockChangedNotifier {
 					m := new(StockChangedNotifierMock)
-					m.On("StockChanged", "a223850e-d8ab-430a-9a1a-28628cfd52b0", "324-3243-32", 10, 20, "shoes")
+					m.On("StockChanged", mock.Anything, "a223850e-d8ab-430a-9a1a-28628cfd52b0", "324-3243-32", 10, 20, "shoes")
 					return m
 				}(),
 			},
@@ -668,6 +1056,47 @@ func Test_controller_Update(t *testing.T) {
 			path:      "/api/v2/product?id=61981e52-e1ca-449e-b79f-01d5906b3435",
 			expBody:   `{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":20,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"self":{"href":"/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0"}}}}`,
 		},
+		{
+			name: "v1: Product not found, returns 404",
+			fields: fields{
+				finderByUUID: func() FinderByUUID {
+					m := new(FinderByUUIDMock)
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(nil, nil)
+					return m
+				}(),
+			},
+			body:      `{"name":"shoes","brand":"nike","stock":20}`,
+			expStatus: http.StatusNotFound,
+			path:      "/api/v1/product?id=61981e52-e1ca-449e-b79f-01d5906b3435",
+			expBody:   `{"error":"Product is not found"}`,
+		},
+		{
+			name: "v1: Product deleted meanwhile, returns 404",
+			fields: fields{
+				finderByUUID: func() FinderByUUID {
+					m := new(FinderByUUIDMock)
+					p := &product{
+						ProductID:  1,
+						Name:       "shoes",
+						UUID:       "61981e52-e1ca-449e-b79f-01d5906b3435",
+						Brand:      "nike",
+						Stock:      10,
+						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+					}
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
+					return m
+				}(),
+				updater: func() Updater {
+					m := new(UpdaterMock)
+					m.On("update", mock.Anything, mock.Anything).Return(storage.ErrNotFound)
+					return m
+				}(),
+			},
+			body:      `{"name":"shoes","brand":"nike","stock":20}`,
+			expStatus: http.StatusNotFound,
+			path:      "/api/v1/product?id=61981e52-e1ca-449e-b79f-01d5906b3435",
+			expBody:   `{"error":"Product is not found"}`,
+		},
 		{
 			name:      "v1: Returns 400, no id passed",
 			expStatus: http.StatusBadRequest,
@@ -678,7 +1107,7 @@ func Test_controller_Update(t *testing.T) {
 			name:      "v2: Returns 400, no id passed",
 			expStatus: http.StatusBadRequest,
 			path:      "/api/v2/product",
-			expBody:   `{"error":"Key: 'UUID' Error:Field validation for 'UUID' failed on the 'required' tag"}`,
+			expBody:   `{"type":"https://api.gfg.com/problems/validation_failed","title":"Bad Request","status":400,"detail":"Key: 'UUID' Error:Field validation for 'UUID' failed on the 'required' tag","instance":"/api/v2/product","code":"validation_failed","errors":[{"field":"id","rule":"required","detail":"Key: 'UUID' Error:Field validation for 'UUID' failed on the 'required' tag"}]}`,
 		},
 	}
 	for _, tt := range tests {
@@ -714,6 +1143,145 @@ func Test_controller_Update(t *testing.T) {
 	}
 }
 
+func Test_controller_AdjustStock(t *testing.T) {
+	const (
+		productUUID = "61981e52-e1ca-449e-b79f-01d5906b3435"
+		sellerUUID  = "a223850e-d8ab-430a-9a1a-28628cfd52b0"
+	)
+
+	finderByUUID := func() FinderByUUID {
+		m := new(FinderByUUIDMock)
+		m.On("findByUUID", mock.Anything, productUUID).Return(&product{
+			ProductID:  1,
+			Name:       "shoes",
+			UUID:       productUUID,
+			Brand:      "nike",
+			Stock:      10,
+			SellerUUID: sellerUUID,
+		}, nil)
+		return m
+	}
+
+	type fields struct {
+		updater          Updater
+		finderByUUID     FinderByUUID
+		sellerRepository SellerFinder
+		emailProvider    StockChangedNotifier
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		body      string
+		path      string
+		expStatus int
+		expBody   string
+	}{
+		{
+			name: "v2: adjusts the stock, notifies the seller and returns 200",
+			fields: fields{
+				finderByUUID: finderByUUID(),
+				updater: func() Updater {
+					m := new(UpdaterMock)
+					m.On("adjustStock", mock.Anything, mock.Anything, -3).Return(nil).Run(func(args mock.Arguments) {
+						args.Get(1).(*product).Stock = 6
+					})
+					return m
+				}(),
+				sellerRepository: func() SellerFinder {
+					m := new(SellerFinderMock)
+					m.On("FindByUUID", mock.Anything, sellerUUID).Return(&sellerAPI.Seller{UUID: sellerUUID, Email: "d@example.com"}, nil)
+					return m
+				}(),
+				emailProvider: func() StockChangedNotifier {
+					// NOTE - the old stock is the one adjusted, not the one found before a concurrent adjustment.
+					m := new(StockChangedNotifierMock)
+					m.On("StockChanged", mock.Anything, sellerUUID, "d@example.com", 9, 6, "shoes")
+					return m
+				}(),
+			},
+			body:      `{"delta":-3}`,
+			path:      "/api/v2/product/stock?id=" + productUUID,
+			expStatus: http.StatusOK,
+			expBody:   `{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":6,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"self":{"href":"/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0"}}}}`,
+		},
+		{
+			name: "v3: returns 409, when the stock is insufficient",
+			fields: fields{
+				finderByUUID: finderByUUID(),
+				updater: func() Updater {
+					m := new(UpdaterMock)
+					m.On("adjustStock", mock.Anything, mock.Anything, -20).Return(ErrInsufficientStock)
+					return m
+				}(),
+			},
+			body:      `{"delta":-20}`,
+			path:      "/api/v3/products/" + productUUID + "/stock",
+			expStatus: http.StatusConflict,
+			expBody:   `{"type":"https://api.gfg.com/problems/insufficient_stock","title":"Conflict","status":409,"detail":"Stock is insufficient","instance":"/api/v3/products/61981e52-e1ca-449e-b79f-01d5906b3435/stock","code":"insufficient_stock"}`,
+		},
+		{
+			name: "v3: returns 404, when the product is deleted meanwhile",
+			fields: fields{
+				finderByUUID: finderByUUID(),
+				updater: func() Updater {
+					m := new(UpdaterMock)
+					m.On("adjustStock", mock.Anything, mock.Anything, 1).Return(storage.ErrNotFound)
+					return m
+				}(),
+			},
+			body:      `{"delta":1}`,
+			path:      "/api/v3/products/" + productUUID + "/stock",
+			expStatus: http.StatusNotFound,
+			expBody:   `{"type":"https://api.gfg.com/problems/product_not_found","title":"Not Found","status":404,"detail":"Product is not found","instance":"/api/v3/products/61981e52-e1ca-449e-b79f-01d5906b3435/stock","code":"product_not_found"}`,
+		},
+		{
+			name: "v3: returns 404, when the product is not found",
+			fields: fields{
+				finderByUUID: func() FinderByUUID {
+					m := new(FinderByUUIDMock)
+					m.On("findByUUID", mock.Anything, productUUID).Return(nil, nil)
+					return m
+				}(),
+			},
+			body:      `{"delta":1}`,
+			path:      "/api/v3/products/" + productUUID + "/stock",
+			expStatus: http.StatusNotFound,
+			expBody:   `{"type":"https://api.gfg.com/problems/product_not_found","title":"Not Found","status":404,"detail":"Product is not found","instance":"/api/v3/products/61981e52-e1ca-449e-b79f-01d5906b3435/stock","code":"product_not_found"}`,
+		},
+		{
+			name:      "v3: returns 400, when the delta is missing",
+			fields:    fields{finderByUUID: finderByUUID()},
+			body:      `{}`,
+			path:      "/api/v3/products/" + productUUID + "/stock",
+			expStatus: http.StatusBadRequest,
+			expBody:   `{"type":"https://api.gfg.com/problems/validation_failed","title":"Bad Request","status":400,"detail":"Key: 'Delta' Error:Field validation for 'Delta' failed on the 'required' tag","instance":"/api/v3/products/61981e52-e1ca-449e-b79f-01d5906b3435/stock","code":"validation_failed","errors":[{"field":"delta","rule":"required","detail":"Key: 'Delta' Error:Field validation for 'Delta' failed on the 'required' tag"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc := NewController(nil, tt.fields.updater, nil, tt.fields.finderByUUID, nil, tt.fields.sellerRepository, tt.fields.emailProvider, nil)
+			r := gin.Default()
+			r.Use(middleware.APIVersionResolver)
+			r.PATCH("/api/v2/product/stock", pc.AdjustStock)
+			r.PATCH("/api/v3/products/:uuid/stock", pc.AdjustStock)
+
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodPatch, tt.path, bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expStatus, w.Code)
+			assert.Equal(t, tt.expBody, w.Body.String())
+
+			if m, ok := tt.fields.emailProvider.(*StockChangedNotifierMock); ok {
+				m.AssertExpectations(t)
+			}
+		})
+	}
+}
+
 func Test_controller_Delete(t *testing.T) {
 	type fields struct {
 		deleter          Deleter
@@ -746,7 +1314,7 @@ func Test_controller_Delete(t *testing.T) {
 						Stock:      10,
 						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
 					}
-					m.On("findByUUID", "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
 					return m
 				}(),
 				deleter: func() Deleter {
@@ -759,7 +1327,7 @@ func Test_controller_Delete(t *testing.T) {
 						Stock:      10,
 						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
 					}
-					m.On("delete", p).Return(nil)
+					m.On("delete", mock.Anything, p).Return(nil)
 					return m
 				}(),
 			},
@@ -767,6 +1335,32 @@ func Test_controller_Delete(t *testing.T) {
 			path:      "/api/v2/product?id=61981e52-e1ca-449e-b79f-01d5906b3435",
 			expBody:   `{}`,
 		},
+		{
+			name: "v3: Delete Product returns 204",
+			fields: fields{
+				finderByUUID: func() FinderByUUID {
+					m := new(FinderByUUIDMock)
+					p := &product{
+						ProductID:  1,
+						Name:       "shoes",
+						UUID:       "61981e52-e1ca-449e-b79f-01d5906b3435",
+						Brand:      "nike",
+						Stock:      10,
+						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+					}
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
+					return m
+				}(),
+				deleter: func() Deleter {
+					m := new(DeleterMock)
+					m.On("delete", mock.Anything, mock.Anything).Return(nil)
+					return m
+				}(),
+			},
+			expStatus: http.StatusNoContent,
+			path:      "/api/v3/products/61981e52-e1ca-449e-b79f-01d5906b3435",
+			expBody:   ``,
+		},
 		{
 			name: "v2: fail to delete, returns 500",
 			fields: fields{
@@ -780,7 +1374,7 @@ func Test_controller_Delete(t *testing.T) {
 						Stock:      10,
 						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
 					}
-					m.On("findByUUID", "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
 					return m
 				}(),
 				deleter: func() Deleter {
@@ -793,40 +1387,66 @@ func Test_controller_Delete(t *testing.T) {
 						Stock:      10,
 						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
 					}
-					m.On("delete", p).Return(errors.New("any error"))
+					m.On("delete", mock.Anything, p).Return(errors.New("any error"))
 					return m
 				}(),
 			},
 			expStatus: http.StatusInternalServerError,
 			path:      "/api/v2/product?id=61981e52-e1ca-449e-b79f-01d5906b3435",
-			expBody:   `{"error":"Fail to delete product"}`,
+			expBody:   `{"type":"https://api.gfg.com/problems/internal_server_error","title":"Internal Server Error","status":500,"detail":"Fail to delete product","instance":"/api/v2/product?id=61981e52-e1ca-449e-b79f-01d5906b3435","code":"internal_server_error"}`,
 		},
 
 		{
-			name: "v2: Product not found returns 400",
+			name: "v2: Product deleted meanwhile, returns 404",
+			fields: fields{
+				finderByUUID: func() FinderByUUID {
+					m := new(FinderByUUIDMock)
+					p := &product{
+						ProductID:  1,
+						Name:       "shoes",
+						UUID:       "61981e52-e1ca-449e-b79f-01d5906b3435",
+						Brand:      "nike",
+						Stock:      10,
+						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+					}
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(p, nil)
+					return m
+				}(),
+				deleter: func() Deleter {
+					m := new(DeleterMock)
+					m.On("delete", mock.Anything, mock.Anything).Return(storage.ErrNotFound)
+					return m
+				}(),
+			},
+			expStatus: http.StatusNotFound,
+			path:      "/api/v2/product?id=61981e52-e1ca-449e-b79f-01d5906b3435",
+			expBody:   `{"type":"https://api.gfg.com/problems/product_not_found","title":"Not Found","status":404,"detail":"Product is not found","instance":"/api/v2/product?id=61981e52-e1ca-449e-b79f-01d5906b3435","code":"product_not_found"}`,
+		},
+		{
+			name: "v2: Product not found returns 404",
 			fields: fields{
 				finderByUUID: func() FinderByUUID {
 					m := new(FinderByUUIDMock)
-					m.On("findByUUID", "61981e52-e1ca-449e-b79f-01d5906b3435").Return(nil, nil)
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(nil, nil)
 					return m
 				}(),
 			},
-			expStatus: http.StatusBadRequest,
+			expStatus: http.StatusNotFound,
 			path:      "/api/v2/product?id=61981e52-e1ca-449e-b79f-01d5906b3435",
-			expBody:   `{"error":"Product is not found"}`,
+			expBody:   `{"type":"https://api.gfg.com/problems/product_not_found","title":"Not Found","status":404,"detail":"Product is not found","instance":"/api/v2/product?id=61981e52-e1ca-449e-b79f-01d5906b3435","code":"product_not_found"}`,
 		},
 		{
 			name: "v2: Repository returns error, returns 500",
 			fields: fields{
 				finderByUUID: func() FinderByUUID {
 					m := new(FinderByUUIDMock)
-					m.On("findByUUID", "61981e52-e1ca-449e-b79f-01d5906b3435").Return(nil, errors.New("any error"))
+					m.On("findByUUID", mock.Anything, "61981e52-e1ca-449e-b79f-01d5906b3435").Return(nil, errors.New("any error"))
 					return m
 				}(),
 			},
 			expStatus: http.StatusInternalServerError,
 			path:      "/api/v2/product?id=61981e52-e1ca-449e-b79f-01d5906b3435",
-			expBody:   `{"error":"Fail to query product by uuid"}`,
+			expBody:   `{"type":"https://api.gfg.com/problems/internal_server_error","title":"Internal Server Error","status":500,"detail":"Fail to query product by uuid","instance":"/api/v2/product?id=61981e52-e1ca-449e-b79f-01d5906b3435","code":"internal_server_error"}`,
 		},
 
 		{
@@ -839,7 +1459,7 @@ func Test_controller_Delete(t *testing.T) {
 			name:      "v2: Returns 400, no id passed",
 			expStatus: http.StatusBadRequest,
 			path:      "/api/v2/product",
-			expBody:   `{"error":"Key: 'UUID' Error:Field validation for 'UUID' failed on the 'required' tag"}`,
+			expBody:   `{"type":"https://api.gfg.com/problems/validation_failed","title":"Bad Request","status":400,"detail":"Key: 'UUID' Error:Field validation for 'UUID' failed on the 'required' tag","instance":"/api/v2/product","code":"validation_failed","errors":[{"field":"id","rule":"required","detail":"Key: 'UUID' Error:Field validation for 'UUID' failed on the 'required' tag"}]}`,
 		},
 	}
 	for _, tt := range tests {
@@ -858,6 +1478,7 @@ func Test_controller_Delete(t *testing.T) {
 			r.Use(middleware.APIVersionResolver)
 			r.DELETE("/api/v1/product", pc.Delete)
 			r.DELETE("/api/v2/product", pc.Delete)
+			r.DELETE("/api/v3/products/:uuid", pc.Delete)
 
 			t.Run(tt.name, func(t *testing.T) {
 				w := httptest.NewRecorder()
@@ -874,3 +1495,322 @@ func Test_controller_Delete(t *testing.T) {
 		})
 	}
 }
+
+func Test_controller_ListBySeller(t *testing.T) {
+	type fields struct {
+		finder           ManyFinder
+		sellerRepository SellerFinder
+	}
+	tests := []struct {
+		name      string
+		fields    fields
+		expStatus int
+		path      string
+		expBody   string
+	}{
+		{
+			name: "v3: Returns the Products of the Seller",
+			fields: fields{
+				sellerRepository: func() SellerFinder {
+					m := new(SellerFinderMock)
+					s := &sellerAPI.Seller{UUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0", Name: "david"}
+					m.On("FindByUUID", mock.Anything, "a223850e-d8ab-430a-9a1a-28628cfd52b0").Return(s, nil)
+					return m
+				}(),
+				finder: func() ManyFinder {
+					m := new(ManyFinderMock)
+					products := []*product{{
+						ProductID:  1,
+						Name:       "shoes",
+						UUID:       "61981e52-e1ca-449e-b79f-01d5906b3435",
+						Brand:      "nike",
+						Stock:      10,
+						SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+					}}
+					m.On("listBySeller", mock.Anything, "a223850e-d8ab-430a-9a1a-28628cfd52b0", 10, 10).Return(products, nil)
+					return m
+				}(),
+			},
+			expStatus: http.StatusOK,
+			path:      "/api/v3/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0/products?page=2",
+			expBody:   `[{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":10,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"products":{"href":"/api/v3/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0/products"}}},"_links":{"self":{"href":"/api/v3/products/61981e52-e1ca-449e-b79f-01d5906b3435"}}}]`,
+		},
+		{
+			name: "v3: Returns 404, when Seller is not found",
+			fields: fields{
+				sellerRepository: func() SellerFinder {
+					m := new(SellerFinderMock)
+					m.On("FindByUUID", mock.Anything, "a223850e-d8ab-430a-9a1a-28628cfd52b0").Return(nil, nil)
+					return m
+				}(),
+			},
+			expStatus: http.StatusNotFound,
+			path:      "/api/v3/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0/products",
+			expBody:   `{"type":"https://api.gfg.com/problems/seller_not_found","title":"Not Found","status":404,"detail":"Seller is not found","instance":"/api/v3/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0/products","code":"seller_not_found"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc := NewController(nil, nil, nil, nil, tt.fields.finder, tt.fields.sellerRepository, nil, nil)
+			r := gin.Default()
+			r.Use(middleware.APIVersionResolver)
+			r.GET("/api/v3/sellers/:uuid/products", pc.ListBySeller)
+
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, tt.path, nil)
+			assert.NoError(t, err)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expStatus, w.Code)
+			assert.Equal(t, tt.expBody, w.Body.String())
+		})
+	}
+}
+
+func Test_controller_sellerOwnership(t *testing.T) {
+	const (
+		productUUID = "61981e52-e1ca-449e-b79f-01d5906b3435"
+		sellerUUID  = "a223850e-d8ab-430a-9a1a-28628cfd52b0"
+	)
+
+	seller := &auth.Principal{Name: "seller", Scopes: []auth.Scope{auth.ScopeProductsWrite}, SellerUUID: sellerUUID}
+	other := &auth.Principal{Name: "other", Scopes: []auth.Scope{auth.ScopeProductsWrite}, SellerUUID: "otherUUID"}
+	admin := &auth.Principal{Name: "admin", Scopes: []auth.Scope{auth.ScopeSellersAdmin}, SellerUUID: "otherUUID"}
+
+	finderByUUID := func() FinderByUUID {
+		m := new(FinderByUUIDMock)
+		m.On("findByUUID", mock.Anything, productUUID).Return(&product{
+			ProductID:  1,
+			Name:       "shoes",
+			UUID:       productUUID,
+			Brand:      "nike",
+			Stock:      10,
+			SellerUUID: sellerUUID,
+		}, nil)
+		return m
+	}
+
+	forbidden := func(instance string) string {
+		return `{"type":"https://api.gfg.com/problems/forbidden","title":"Forbidden","status":403,` +
+			`"detail":"Products of the seller may not be modified","instance":"` + instance + `","code":"forbidden"}`
+	}
+
+	tests := []struct {
+		name         string
+		principal    *auth.Principal
+		method       string
+		path         string
+		body         string
+		finderByUUID FinderByUUID
+		deleter      Deleter
+		updater      Updater
+		expStatus    int
+		expBody      string
+	}{
+		{
+			name:      "POST: returns 403, when the seller of the product is not the one of the principal",
+			principal: other,
+			method:    http.MethodPost,
+			path:      "/api/v3/products",
+			body:      `{"name":"shoes","brand":"nike","stock":10,"seller":"` + sellerUUID + `"}`,
+			expStatus: http.StatusForbidden,
+			expBody:   forbidden("/api/v3/products"),
+		},
+		{
+			name:         "PUT: returns 403, when the product is of another seller",
+			principal:    other,
+			method:       http.MethodPut,
+			path:         "/api/v3/products/" + productUUID,
+			body:         `{"name":"shoes","brand":"nike","stock":10}`,
+			finderByUUID: finderByUUID(),
+			expStatus:    http.StatusForbidden,
+			expBody:      forbidden("/api/v3/products/" + productUUID),
+		},
+		{
+			name:         "PUT: updates the product of the seller",
+			principal:    seller,
+			method:       http.MethodPut,
+			path:         "/api/v3/products/" + productUUID,
+			body:         `{"name":"shoes","brand":"nike","stock":10}`,
+			finderByUUID: finderByUUID(),
+			updater: func() Updater {
+				m := new(UpdaterMock)
+				m.On("update", mock.Anything, mock.Anything).Return(nil)
+				return m
+			}(),
+			expStatus: http.StatusOK,
+		},
+		{
+			name:         "DELETE: returns 403, when the product is of another seller",
+			principal:    other,
+			method:       http.MethodDelete,
+			path:         "/api/v3/products/" + productUUID,
+			finderByUUID: finderByUUID(),
+			expStatus:    http.StatusForbidden,
+			expBody:      forbidden("/api/v3/products/" + productUUID),
+		},
+		{
+			name:         "DELETE: admin deletes the product of another seller",
+			principal:    admin,
+			method:       http.MethodDelete,
+			path:         "/api/v3/products/" + productUUID,
+			finderByUUID: finderByUUID(),
+			deleter: func() Deleter {
+				m := new(DeleterMock)
+				m.On("delete", mock.Anything, mock.Anything).Return(nil)
+				return m
+			}(),
+			expStatus: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pc := NewController(tt.deleter, tt.updater, nil, tt.finderByUUID, nil, nil, nil, nil)
+			r := gin.Default()
+			r.Use(middleware.APIVersionResolver)
+			r.Use(func(c *gin.Context) {
+				c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), tt.principal))
+			})
+			r.POST("/api/v3/products", pc.Post)
+			r.PUT("/api/v3/products/:uuid", pc.Put)
+			r.DELETE("/api/v3/products/:uuid", pc.Delete)
+
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			assert.NoError(t, err)
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expStatus, w.Code)
+			if tt.expBody != "" {
+				assert.Equal(t, tt.expBody, w.Body.String())
+			}
+		})
+	}
+}
+
+func Test_controller_Export(t *testing.T) {
+	const sellerUUID = "a223850e-d8ab-430a-9a1a-28628cfd52b0"
+
+	products := []*product{
+		{ProductID: 1, UUID: "61981e52-e1ca-449e-b79f-01d5906b3435", Name: "shoes", Brand: "nike", Stock: 10, SellerUUID: sellerUUID},
+		{ProductID: 2, UUID: "36345687-e998-4359-a2ed-a9703fe39b5f", Name: "socks, white", Brand: "adidas", Stock: 0, SellerUUID: sellerUUID},
+	}
+
+	exporting := func(products ...*product) func(context.Context, exportFilter, func(*product) error) error {
+		return func(_ context.Context, _ exportFilter, each func(*product) error) error {
+			for _, p := range products {
+				if err := each(p); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		filter     exportFilter
+		export     interface{}
+		expStatus  int
+		expHeaders map[string]string
+		expBody    string
+	}{
+		{
+			name:       "Returns 200 with NDJSON of v2 products",
+			path:       "/api/v2/products/export",
+			export:     exporting(products...),
+			expStatus:  http.StatusOK,
+			expHeaders: map[string]string{"Content-Type": "application/x-ndjson", "Content-Disposition": "attachment; filename=products.ndjson"},
+			expBody: `{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":10,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"self":{"href":"/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0"}}}}` + "\n" +
+				`{"uuid":"36345687-e998-4359-a2ed-a9703fe39b5f","name":"socks, white","brand":"adidas","stock":0,"seller":{"uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0","_links":{"self":{"href":"/sellers/a223850e-d8ab-430a-9a1a-28628cfd52b0"}}}}` + "\n",
+		},
+		{
+			name:      "Returns 200 with NDJSON of v1 products of the filter",
+			path:      "/api/v2/products/export?representation=v1&seller=" + sellerUUID + "&brand=nike&in_stock=true",
+			filter:    exportFilter{SellerUUID: sellerUUID, Brand: "nike", InStock: true},
+			export:    exporting(products[0]),
+			expStatus: http.StatusOK,
+			expBody:   `{"uuid":"61981e52-e1ca-449e-b79f-01d5906b3435","name":"shoes","brand":"nike","stock":10,"seller_uuid":"a223850e-d8ab-430a-9a1a-28628cfd52b0"}` + "\n",
+		},
+		{
+			name:       "Returns 200 with CSV",
+			path:       "/api/v2/products/export?format=csv",
+			export:     exporting(products...),
+			expStatus:  http.StatusOK,
+			expHeaders: map[string]string{"Content-Type": "text/csv; charset=utf-8"},
+			expBody: "uuid,name,brand,stock,seller\n" +
+				"61981e52-e1ca-449e-b79f-01d5906b3435,shoes,nike,10,a223850e-d8ab-430a-9a1a-28628cfd52b0\n" +
+				"36345687-e998-4359-a2ed-a9703fe39b5f,\"socks, white\",adidas,0,a223850e-d8ab-430a-9a1a-28628cfd52b0\n",
+		},
+		{
+			name:       "Returns 200 with the header of the empty CSV of v1, gzipped",
+			path:       "/api/v2/products/export?format=csv&representation=v1",
+			export:     exporting(),
+			expStatus:  http.StatusOK,
+			expHeaders: map[string]string{"Content-Encoding": "gzip", "Vary": "Accept-Encoding"},
+			expBody:    "uuid,name,brand,stock,seller_uuid\n",
+		},
+		{
+			name:      "Returns 400, when format is unknown",
+			path:      "/api/v2/products/export?format=xml",
+			expStatus: http.StatusBadRequest,
+			expBody:   `{"type":"https://api.gfg.com/problems/validation_failed","title":"Bad Request","status":400,"detail":"Key: 'Format' Error:Field validation for 'Format' failed on the 'oneof' tag","instance":"/api/v2/products/export?format=xml","code":"validation_failed","errors":[{"field":"format","rule":"oneof","detail":"Key: 'Format' Error:Field validation for 'Format' failed on the 'oneof' tag"}]}`,
+		},
+		{
+			name:      "Returns 500, when the export fails before the first product",
+			path:      "/api/v2/products/export",
+			export:    errors.New("any error"),
+			expStatus: http.StatusInternalServerError,
+			expBody:   `{"type":"https://api.gfg.com/problems/internal_server_error","title":"Internal Server Error","status":500,"detail":"Fail to export products","instance":"/api/v2/products/export","code":"internal_server_error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			finder := new(ManyFinderMock)
+			finder.On("export", mock.Anything, tt.filter, mock.Anything).Return(tt.export)
+
+			pc := NewController(nil, nil, nil, nil, finder, nil, nil, nil)
+			r := gin.Default()
+			r.Use(middleware.APIVersionResolver)
+			r.GET("/api/v2/products/export", pc.Export)
+
+			w := httptest.NewRecorder()
+
+			req, err := http.NewRequest(http.MethodGet, tt.path, nil)
+			assert.NoError(t, err)
+
+			if tt.expHeaders["Content-Encoding"] == "gzip" {
+				req.Header.Set("Accept-Encoding", "gzip")
+			}
+
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expStatus, w.Code)
+
+			for header, value := range tt.expHeaders {
+				assert.Equal(t, value, w.Header().Get(header), header)
+			}
+
+			body := w.Body.String()
+
+			if w.Header().Get("Content-Encoding") == "gzip" {
+				gz, err := gzip.NewReader(w.Body)
+				assert.NoError(t, err)
+
+				data, err := io.ReadAll(gz)
+				assert.NoError(t, err)
+
+				body = string(data)
+			}
+
+			assert.Equal(t, tt.expBody, body)
+		})
+	}
+}
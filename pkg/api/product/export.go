@@ -0,0 +1,182 @@
+package product
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	exportFormatNDJSON = "ndjson"
+	exportFormatCSV    = "csv"
+
+	// exportFlushEvery is the number of the exported products after which the response is flushed,
+	// so that the client receives the export as it is read.
+	exportFlushEvery = 500
+)
+
+// exportCSVHeaders are the CSV columns of the representations, named as the fields of their JSON.
+var exportCSVHeaders = map[string][]string{
+	versionV1: {"uuid", "name", "brand", "stock", "seller_uuid"},
+	versionV2: {"uuid", "name", "brand", "stock", "seller"},
+}
+
+// exportWriter writes the exported products to the response, whose headers are sent along with
+// the first product so that the errors before it are still responded as such.
+type exportWriter struct {
+	c       *gin.Context
+	format  string
+	version string
+	gzip    bool
+
+	// started reports whether the headers are sent.
+	started bool
+	// count is the number of the written products.
+	count int
+
+	gz  *gzip.Writer
+	buf *bufio.Writer
+	csv *csv.Writer
+}
+
+func newExportWriter(c *gin.Context, format, version string, gzip bool) *exportWriter {
+	return &exportWriter{c: c, format: format, version: version, gzip: gzip}
+}
+
+func (w *exportWriter) start() error {
+	w.started = true
+
+	header := w.c.Writer.Header()
+	header.Set("Content-Disposition", "attachment; filename=products."+w.format)
+	header.Add("Vary", "Accept-Encoding")
+
+	if w.format == exportFormatCSV {
+		header.Set("Content-Type", "text/csv; charset=utf-8")
+	} else {
+		header.Set("Content-Type", "application/x-ndjson")
+	}
+
+	var out io.Writer = w.c.Writer
+
+	if w.gzip {
+		header.Set("Content-Encoding", "gzip")
+		w.gz = gzip.NewWriter(w.c.Writer)
+		out = w.gz
+	}
+
+	w.c.Status(http.StatusOK)
+	w.c.Writer.WriteHeaderNow()
+
+	w.buf = bufio.NewWriter(out)
+
+	if w.format == exportFormatCSV {
+		w.csv = csv.NewWriter(w.buf)
+		return w.csv.Write(exportCSVHeaders[w.version])
+	}
+
+	return nil
+}
+
+// write writes the product, it is passed to the ManyFinder.
+func (w *exportWriter) write(p *product) error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	if w.csv != nil {
+		err := w.csv.Write([]string{p.UUID, p.Name, p.Brand, strconv.Itoa(p.Stock), p.SellerUUID})
+		if err != nil {
+			return err
+		}
+	} else {
+		data, err := marshalVersion(w.c, w.version, p)
+		if err != nil {
+			return err
+		}
+
+		if _, err := w.buf.Write(append(data, '\n')); err != nil {
+			return err
+		}
+	}
+
+	w.count++
+
+	if w.count%exportFlushEvery == 0 {
+		return w.flush()
+	}
+
+	return nil
+}
+
+// flush sends the written products to the client.
+func (w *exportWriter) flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+
+	if err := w.buf.Flush(); err != nil {
+		return err
+	}
+
+	if w.gz != nil {
+		if err := w.gz.Flush(); err != nil {
+			return err
+		}
+	}
+
+	w.c.Writer.Flush()
+
+	return nil
+}
+
+// close completes the export, an empty one is responded too.
+func (w *exportWriter) close() error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	if err := w.flush(); err != nil {
+		return err
+	}
+
+	if w.gz != nil {
+		return w.gz.Close()
+	}
+
+	return nil
+}
+
+// acceptsGzip reports whether the client accepts the gzip content encoding, unless it is weighted by q=0.
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(encoding, ";")
+		if name = strings.TrimSpace(name); name != "gzip" && name != "*" {
+			continue
+		}
+
+		q, ok := strings.CutPrefix(strings.TrimSpace(params), "q=")
+		if !ok {
+			return true
+		}
+
+		weight, err := strconv.ParseFloat(q, 64)
+
+		return err == nil && weight > 0
+	}
+
+	return false
+}
@@ -0,0 +1,30 @@
+package product
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_acceptsGzip(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		want           bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"deflate, gzip;q=0.8", true},
+		{"br, *", true},
+		{"gzip;q=0", false},
+		{"gzip; q=0.0", false},
+		{"identity", false},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, "/api/v2/products/export", nil)
+		req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+
+		assert.Equal(t, tt.want, acceptsGzip(req), tt.acceptEncoding)
+	}
+}
@@ -1,63 +1,198 @@
 package product
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
 
+	sellerAPI "coding-challenge-go/pkg/api/seller"
+
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	// keyFields is the context key of the requested sparse fieldset, see controller.bindView.
+	keyFields = "fields"
+	// keyEmbeddedSellers is the context key of the Sellers embedded in the products by uuid.
+	keyEmbeddedSellers = "embedded_sellers"
+
+	embedSeller = "seller"
+)
+
+// selectableFields are the fields of v2 and v3 products which can be requested by ?fields=,
+// the links and the embedded resources are always represented.
+var selectableFields = map[string]bool{
+	"uuid":   true,
+	"name":   true,
+	"brand":  true,
+	"stock":  true,
+	"seller": true,
+}
+
 // marshalJSON marshals products as per the API version.
 // this layer represents views, which differs for different version.
 func marshalJSON(c *gin.Context, products interface{}) ([]byte, error) {
-	switch c.MustGet("version").(string) {
+	return marshalVersion(c, c.MustGet("version").(string), products)
+}
+
+// marshalVersion marshals products in the representation of the version, e.g. of the exports
+// which choose it regardless of the version of the request.
+func marshalVersion(c *gin.Context, version string, products interface{}) ([]byte, error) {
+	var sellers map[string]*sellerAPI.Seller
+	if embedded, ok := c.Get(keyEmbeddedSellers); ok {
+		sellers = embedded.(map[string]*sellerAPI.Seller)
+	}
+
+	var (
+		data []byte
+		err  error
+	)
+
+	switch version {
 	case versionV1:
 		return json.Marshal(products)
 	case versionV2:
-		return json.Marshal(hydrateProductsToV2(c, products))
+		data, err = json.Marshal(hydrateProductsToV2(c, products, sellers))
+	case versionV3:
+		data, err = json.Marshal(hydrateProductsToV3(products, sellers))
+	default:
+		return nil, errors.New("invalid API version")
+	}
+
+	if fields, ok := c.Get(keyFields); ok && err == nil {
+		return selectFields(data, fields.([]string))
+	}
+
+	return data, err
+}
+
+// selectFields keeps only the given fields, the links and the embedded resources
+// of the marshalled product/products.
+func selectFields(data []byte, fields []string) ([]byte, error) {
+	keep := map[string]bool{"_links": true, "_embedded": true}
+	for _, field := range fields {
+		keep[field] = true
+	}
+
+	filter := func(product map[string]json.RawMessage) {
+		for field := range product {
+			if !keep[field] {
+				delete(product, field)
+			}
+		}
+	}
+
+	if bytes.HasPrefix(data, []byte("[")) {
+		var products []map[string]json.RawMessage
+		if err := json.Unmarshal(data, &products); err != nil {
+			return nil, err
+		}
+
+		for _, product := range products {
+			filter(product)
+		}
+
+		return json.Marshal(products)
+	}
+
+	var product map[string]json.RawMessage
+	if err := json.Unmarshal(data, &product); err != nil {
+		return nil, err
+	}
+
+	if product == nil {
+		return data, nil
 	}
 
-	return nil, errors.New("invalid API version")
+	filter(product)
+
+	return json.Marshal(product)
 }
 
 // hydrateProductsToV2 transforms product/products to its version V2.
-func hydrateProductsToV2(c *gin.Context, products interface{}) interface{} {
+func hydrateProductsToV2(c *gin.Context, products interface{}, sellers map[string]*sellerAPI.Seller) interface{} {
 	switch v := products.(type) {
 	case []*product:
 		productsV2 := make([]productV2, 0)
 		for _, p := range v {
-			productsV2 = append(productsV2, productV2{
-				UUID:  p.UUID,
-				Name:  p.Name,
-				Brand: p.Brand,
-				Stock: p.Stock,
-				Seller: seller{
-					UUID: p.SellerUUID,
-					Links: links{
-						self{
-							HRef: fmt.Sprintf("%s/%s/%s", c.Request.Host, "sellers", p.SellerUUID),
-						}},
-				},
-			})
+			productsV2 = append(productsV2, toV2(c, p, sellers))
 		}
 
 		return productsV2
 	case *product:
-		return productV2{
-			UUID:  v.UUID,
-			Name:  v.Name,
-			Brand: v.Brand,
-			Stock: v.Stock,
-			Seller: seller{
-				UUID: v.SellerUUID,
-				Links: links{
-					self{
-						HRef: fmt.Sprintf("%s/%s/%s", c.Request.Host, "sellers", v.SellerUUID),
-					}},
-			},
+		return toV2(c, v, sellers)
+	}
+
+	return nil
+}
+
+// toV2 transforms the product to its version V2.
+func toV2(c *gin.Context, p *product, sellers map[string]*sellerAPI.Seller) productV2 {
+	return productV2{
+		UUID:  p.UUID,
+		Name:  p.Name,
+		Brand: p.Brand,
+		Stock: p.Stock,
+		Seller: seller{
+			UUID: p.SellerUUID,
+			Links: links{
+				self{
+					HRef: fmt.Sprintf("%s/%s/%s", c.Request.Host, "sellers", p.SellerUUID),
+				}},
+		},
+		Embedded: embed(p, sellers),
+	}
+}
+
+// productPath is the path of the product resource in v3.
+func productPath(uuid string) string {
+	return "/api/v3/products/" + uuid
+}
+
+// hydrateProductsToV3 transforms product/products to its version V3.
+func hydrateProductsToV3(products interface{}, sellers map[string]*sellerAPI.Seller) interface{} {
+	switch v := products.(type) {
+	case []*product:
+		productsV3 := make([]productV3, 0, len(v))
+		for _, p := range v {
+			productsV3 = append(productsV3, toV3(p, sellers))
 		}
+
+		return productsV3
+	case *product:
+		return toV3(v, sellers)
 	}
 
 	return nil
 }
+
+// toV3 transforms the product to its version V3.
+func toV3(p *product, sellers map[string]*sellerAPI.Seller) productV3 {
+	return productV3{
+		UUID:  p.UUID,
+		Name:  p.Name,
+		Brand: p.Brand,
+		Stock: p.Stock,
+		Seller: sellerV3{
+			UUID: p.SellerUUID,
+			Links: sellerLinksV3{
+				Products: link{HRef: "/api/v3/sellers/" + p.SellerUUID + "/products"},
+			},
+		},
+		Links: productLinksV3{
+			Self: link{HRef: productPath(p.UUID)},
+		},
+		Embedded: embed(p, sellers),
+	}
+}
+
+// embed returns the resources embedded in the product, nil when none is requested or found.
+func embed(p *product, sellers map[string]*sellerAPI.Seller) *embedded {
+	s, ok := sellers[p.SellerUUID]
+	if !ok {
+		return nil
+	}
+
+	return &embedded{Seller: &embeddedSeller{UUID: s.UUID, Name: s.Name}}
+}
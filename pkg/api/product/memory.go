@@ -0,0 +1,256 @@
+package product
+
+import (
+	"context"
+
+	sellerAPI "coding-challenge-go/pkg/api/seller"
+	"coding-challenge-go/pkg/storage"
+	"coding-challenge-go/pkg/storage/memory"
+
+	"github.com/google/uuid"
+)
+
+// NewMemoryRepository builds the in-memory repository of the products of the DB.
+func NewMemoryRepository(db *memory.DB) *memoryRepository {
+	return &memoryRepository{db: db}
+}
+
+// memoryRepository is the in-memory implementation of the repositories of the products, it behaves
+// like the DB repository.
+type memoryRepository struct {
+	db *memory.DB
+}
+
+// toProduct returns the product of the row, nil when its seller does not exist.
+func toProduct(t *memory.Tables, row *memory.Product) *product {
+	seller := t.SellerByID(row.SellerID)
+	if seller == nil {
+		return nil
+	}
+
+	return &product{
+		ProductID:  row.ID,
+		UUID:       row.UUID,
+		Name:       row.Name,
+		Brand:      row.Brand,
+		Stock:      row.Stock,
+		SellerUUID: seller.UUID,
+	}
+}
+
+// delete is the in-memory implementation for the Deleter.
+func (r *memoryRepository) delete(ctx context.Context, product *product) error {
+	return r.db.Write(ctx, func(t *memory.Tables) error {
+		return t.DeleteProduct(product.UUID)
+	})
+}
+
+// insert is the in-memory implementation for the Inserter.
+func (r *memoryRepository) insert(ctx context.Context, product *product) (*product, error) {
+	err := r.db.Write(ctx, func(t *memory.Tables) error {
+		seller := t.SellerByUUID(product.SellerUUID)
+		if seller == nil {
+			return storage.ErrForeignKey
+		}
+
+		product.UUID = uuid.New().String()
+
+		return t.InsertProduct(&memory.Product{
+			ID:         product.ProductID,
+			UUID:       product.UUID,
+			Name:       product.Name,
+			Brand:      product.Brand,
+			Stock:      product.Stock,
+			SellerID:   seller.ID,
+			ExternalID: product.ExternalID,
+		})
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return product, nil
+}
+
+// upsert is the in-memory implementation for the Inserter of the imports.
+func (r *memoryRepository) upsert(ctx context.Context, product *product) (bool, error) {
+	inserted := false
+
+	err := r.db.Write(ctx, func(t *memory.Tables) error {
+		seller := t.SellerByUUID(product.SellerUUID)
+		if seller == nil {
+			return storage.ErrForeignKey
+		}
+
+		for _, row := range t.Products() {
+			if row.SellerID == seller.ID && row.ExternalID == product.ExternalID {
+				product.ProductID, product.UUID = row.ID, row.UUID
+				row.Name, row.Brand, row.Stock = product.Name, product.Brand, product.Stock
+
+				return nil
+			}
+		}
+
+		product.UUID = uuid.New().String()
+		inserted = true
+
+		return t.InsertProduct(&memory.Product{
+			ID:         product.ProductID,
+			UUID:       product.UUID,
+			Name:       product.Name,
+			Brand:      product.Brand,
+			Stock:      product.Stock,
+			SellerID:   seller.ID,
+			ExternalID: product.ExternalID,
+		})
+	})
+
+	return inserted && err == nil, err
+}
+
+// update is the in-memory implementation for the Updater.
+func (r *memoryRepository) update(ctx context.Context, product *product) error {
+	return r.db.Write(ctx, func(t *memory.Tables) error {
+		row := t.ProductByUUID(product.UUID)
+		if row == nil {
+			return storage.ErrNotFound
+		}
+
+		row.Name, row.Brand, row.Stock = product.Name, product.Brand, product.Stock
+
+		return nil
+	})
+}
+
+// adjustStock is the in-memory implementation for the Updater.
+func (r *memoryRepository) adjustStock(ctx context.Context, product *product, delta int) error {
+	return r.db.Write(ctx, func(t *memory.Tables) error {
+		row := t.ProductByUUID(product.UUID)
+		if row == nil {
+			return storage.ErrNotFound
+		}
+
+		if row.Stock+delta < 0 {
+			return ErrInsufficientStock
+		}
+
+		row.Stock += delta
+		product.Stock = row.Stock
+
+		return nil
+	})
+}
+
+// list is the in-memory implementation for the ManyFinder.
+func (r *memoryRepository) list(ctx context.Context, offset int, limit int) ([]*product, error) {
+	return r.page(ctx, offset, limit, func(*product) bool { return true })
+}
+
+// listBySeller is the in-memory implementation for the ManyFinder of the Seller's products.
+func (r *memoryRepository) listBySeller(ctx context.Context, sellerUUID string, offset int, limit int) ([]*product, error) {
+	return r.page(ctx, offset, limit, func(p *product) bool { return p.SellerUUID == sellerUUID })
+}
+
+// page returns the page of the products matching the filter, in the order of their IDs.
+func (r *memoryRepository) page(ctx context.Context, offset, limit int, match func(*product) bool) ([]*product, error) {
+	var products []*product
+
+	err := r.db.Read(ctx, func(t *memory.Tables) error {
+		for _, row := range t.Products() {
+			product := toProduct(t, row)
+			if product == nil || !match(product) {
+				continue
+			}
+
+			if offset > 0 {
+				offset--
+				continue
+			}
+
+			if len(products) == limit {
+				break
+			}
+
+			products = append(products, product)
+		}
+
+		return nil
+	})
+
+	return products, err
+}
+
+// export is the in-memory implementation for the ManyFinder of all the products. The products are
+// read at once, each is called after the read, so that it does not block the writes.
+func (r *memoryRepository) export(ctx context.Context, filter exportFilter, each func(*product) error) error {
+	var products []*product
+
+	err := r.db.Read(ctx, func(t *memory.Tables) error {
+		for _, row := range t.Products() {
+			product := toProduct(t, row)
+
+			switch {
+			case product == nil,
+				filter.SellerUUID != "" && product.SellerUUID != filter.SellerUUID,
+				filter.Brand != "" && product.Brand != filter.Brand,
+				filter.InStock && product.Stock <= 0:
+				continue
+			}
+
+			products = append(products, product)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return err
+	}
+
+	for _, product := range products {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := each(product); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findByUUID is the in-memory implementation for the FinderByUUID.
+func (r *memoryRepository) findByUUID(ctx context.Context, uuid string) (*product, error) {
+	var product *product
+
+	err := r.db.Read(ctx, func(t *memory.Tables) error {
+		if row := t.ProductByUUID(uuid); row != nil {
+			product = toProduct(t, row)
+		}
+
+		return nil
+	})
+
+	return product, err
+}
+
+// NewMemoryUnitOfWork builds the in-memory UnitOfWork, its steps run on the repositories bound to the
+// transactions of the DB.
+func NewMemoryUnitOfWork(db *memory.DB) *memoryUnitOfWork {
+	return &memoryUnitOfWork{db: db}
+}
+
+// memoryUnitOfWork is the in-memory implementation for the UnitOfWork.
+type memoryUnitOfWork struct {
+	db *memory.DB
+}
+
+// do is the in-memory implementation for the UnitOfWork, the transactions are serialized so they
+// never deadlock.
+func (u *memoryUnitOfWork) do(ctx context.Context, fn func(tx *Tx) error) error {
+	return u.db.Transact(ctx, func(tx *memory.DB) error {
+		return fn(&Tx{Products: NewMemoryRepository(tx), Sellers: sellerAPI.NewMemoryRepository(tx)})
+	})
+}
@@ -7,16 +7,19 @@ type product struct {
 	Brand      string `json:"brand"`
 	Stock      int    `json:"stock"`
 	SellerUUID string `json:"seller_uuid"`
+	// ExternalID is the ID of the product in the catalog of the seller, it is set by the imports.
+	ExternalID string `json:"-"`
 }
 
 // productV2 is the v2 representation of product
 type productV2 struct {
-	ProductID int    `json:"-"`
-	UUID      string `json:"uuid"`
-	Name      string `json:"name"`
-	Brand     string `json:"brand"`
-	Stock     int    `json:"stock"`
-	Seller    seller `json:"seller"`
+	ProductID int       `json:"-"`
+	UUID      string    `json:"uuid"`
+	Name      string    `json:"name"`
+	Brand     string    `json:"brand"`
+	Stock     int       `json:"stock"`
+	Seller    seller    `json:"seller"`
+	Embedded  *embedded `json:"_embedded,omitempty"`
 }
 
 // seller represents seller used by productV2
@@ -32,3 +35,43 @@ type links struct {
 type self struct {
 	HRef string `json:"href"`
 }
+
+// productV3 is the v3 representation of product
+type productV3 struct {
+	UUID     string         `json:"uuid"`
+	Name     string         `json:"name"`
+	Brand    string         `json:"brand"`
+	Stock    int            `json:"stock"`
+	Seller   sellerV3       `json:"seller"`
+	Links    productLinksV3 `json:"_links"`
+	Embedded *embedded      `json:"_embedded,omitempty"`
+}
+
+// sellerV3 represents seller used by productV3
+type sellerV3 struct {
+	UUID  string        `json:"uuid"`
+	Links sellerLinksV3 `json:"_links"`
+}
+
+type productLinksV3 struct {
+	Self link `json:"self"`
+}
+
+type sellerLinksV3 struct {
+	Products link `json:"products"`
+}
+
+type link struct {
+	HRef string `json:"href"`
+}
+
+// embedded are the resources embedded in the product by ?embed=
+type embedded struct {
+	Seller *embeddedSeller `json:"seller,omitempty"`
+}
+
+// embeddedSeller is the Seller embedded in the product, without its contact details.
+type embeddedSeller struct {
+	UUID string `json:"uuid"`
+	Name string `json:"name"`
+}
@@ -1,76 +1,274 @@
 package product
 
 import (
+	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	"coding-challenge-go/pkg/storage"
+	"coding-challenge-go/pkg/tracing"
 
 	"github.com/google/uuid"
 )
 
 // New Repository build new DB repo.
-func NewRepository(db *sql.DB) *repository {
-	return &repository{db: db}
+func NewRepository(db *sql.DB, queryTimeout time.Duration) *repository {
+	dialect := storage.DialectOf(db)
+
+	return &repository{db: dialect.Bind(db), dialect: dialect, queryTimeout: queryTimeout}
 }
 
 // repository is the new DB repo.
 type repository struct {
-	db *sql.DB
+	// db is the DB, or the transaction of the repository bound to it by withTx.
+	db storage.DBTX
+	// dialect is the dialect of the DB, its queries are adapted to it.
+	dialect *storage.Dialect
+	// queryTimeout bounds every query, it is not bounded when it is not positive.
+	queryTimeout time.Duration
+}
+
+// withTx returns the repository bound to the transaction, its queries run on the transaction.
+func (r *repository) withTx(tx storage.DBTX) *repository {
+	return &repository{db: r.dialect.Bind(tx), dialect: r.dialect, queryTimeout: r.queryTimeout}
 }
 
 // delete is the DB implementation for the Deleter.
-func (r *repository) delete(product *product) error {
-	rows, err := r.db.Query("DELETE FROM product WHERE uuid = ?", product.UUID)
+//
+// Returns storage.ErrNotFound, when the product does not exist anymore.
+func (r *repository) delete(ctx context.Context, product *product) (err error) {
+	ctx, span := tracing.Start(ctx, "product.repository.delete", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM product WHERE uuid = ?", product.UUID)
 
 	if err != nil {
-		return err
+		return storage.FromDB(err)
 	}
 
-	defer rows.Close()
-
-	return nil
+	return storage.ExpectAffected(result)
 }
 
 // insert is the DB implementation for the Inserter.
 // NOTE - as uuid is created in repository now, contract has to be changed to let controller
 // know the created product with UUID.
-func (r *repository) insert(product *product) (*product, error) {
+//
+// Returns storage.ErrForeignKey, when the seller of the product does not exist.
+func (r *repository) insert(ctx context.Context, product *product) (_ *product, err error) {
+	ctx, span := tracing.Start(ctx, "product.repository.insert", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
 	product.UUID = uuid.New().String()
 
-	rows, err := r.db.Query(
-		"INSERT INTO product (id_product, name, brand, stock, fk_seller, uuid) VALUES(?,?,?,?,(SELECT id_seller FROM seller WHERE uuid = ?),?)",
-		product.ProductID, product.Name, product.Brand, product.Stock, product.SellerUUID, product.UUID,
+	columns, values := "", ""
+	args := []interface{}{product.Name, product.Brand, product.Stock, product.SellerUUID, product.UUID, product.ExternalID}
+
+	// NOTE - the ID is generated unless the product is restored with its ID, PostgreSQL would insert
+	// the zero ID as it is.
+	if product.ProductID != 0 {
+		columns, values, args = "id_product, ", "?,", append([]interface{}{product.ProductID}, args...)
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		"INSERT INTO product ("+columns+"name, brand, stock, fk_seller, uuid, external_id) "+
+			"VALUES("+values+"?,?,?,(SELECT id_seller FROM seller WHERE uuid = ?),?,NULLIF(?, ''))",
+		args...,
+	)
+
+	if err != nil {
+		return nil, storage.FromDB(err)
+	}
+
+	return product, nil
+}
+
+// upsert is the DB implementation for the Inserter of the imports, it updates the product of the seller
+// which has the same external ID, or inserts the product when there is none. It reports whether the
+// product is inserted.
+//
+// Returns storage.ErrForeignKey, when the seller of the product does not exist. Returns
+// storage.ErrConflict, when a product of the external ID is inserted concurrently.
+func (r *repository) upsert(ctx context.Context, product *product) (_ bool, err error) {
+	ctx, span := tracing.Start(ctx, "product.repository.upsert", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	found, err := r.findByExternalID(ctx, product.SellerUUID, product.ExternalID)
+	if err != nil {
+		return false, err
+	}
+
+	if found == nil {
+		_, err = r.insert(ctx, product)
+
+		return err == nil, err
+	}
+
+	product.ProductID, product.UUID = found.ProductID, found.UUID
+
+	return false, r.update(ctx, product)
+}
+
+// findByExternalID returns the product of the seller by its external ID, nil when there is none.
+func (r *repository) findByExternalID(ctx context.Context, sellerUUID, externalID string) (*product, error) {
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT p.id_product, p.name, p.brand, p.stock, s.uuid, p.uuid FROM product p "+
+			"INNER JOIN seller s ON(s.id_seller = p.fk_seller) WHERE s.uuid = ? AND p.external_id = ?",
+		sellerUUID, externalID,
 	)
 
 	if err != nil {
 		return nil, err
 	}
 
-	defer rows.Close()
+	products, err := scanProducts(rows, "product.Repository.findByExternalID")
+	if err != nil || len(products) == 0 {
+		return nil, err
+	}
 
-	return product, nil
+	products[0].ExternalID = externalID
+
+	return products[0], nil
 }
 
 // update is the DB implementation for the Updater.
-func (r *repository) update(product *product) error {
-	rows, err := r.db.Query(
+//
+// Returns storage.ErrNotFound, when the product does not exist anymore.
+func (r *repository) update(ctx context.Context, product *product) (err error) {
+	ctx, span := tracing.Start(ctx, "product.repository.update", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(
+		ctx,
 		"UPDATE product SET name = ?, brand = ?, stock = ? WHERE uuid = ?",
 		product.Name, product.Brand, product.Stock, product.UUID,
 	)
 
 	if err != nil {
-		return err
+		return storage.FromDB(err)
 	}
 
-	defer rows.Close()
+	return storage.ExpectAffected(result)
+}
+
+// adjustStock is the DB implementation for the Updater, it adjusts the stock atomically and sets
+// the adjusted stock to the product.
+//
+// Returns ErrInsufficientStock, when the stock would be negative. Returns storage.ErrNotFound,
+// when the product does not exist anymore.
+func (r *repository) adjustStock(ctx context.Context, product *product, delta int) (err error) {
+	ctx, span := tracing.Start(ctx, "product.repository.adjustStock", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	stock, updated, err := r.addStock(ctx, product.UUID, delta)
+	if err != nil {
+		return storage.FromDB(err)
+	}
+
+	if !updated {
+		found, err := r.findByUUID(ctx, product.UUID)
+		if err != nil {
+			return err
+		}
+
+		if found == nil {
+			return storage.ErrNotFound
+		}
+
+		return ErrInsufficientStock
+	}
+
+	product.Stock = stock
 
 	return nil
 }
 
-// list is the DB implementation for the ManyFinder.
-func (r *repository) list(offset int, limit int) ([]*product, error) {
-	rows, err := r.db.Query(
+// addStock adds the delta to the stock of the product, unless it would be negative, and returns the
+// adjusted stock. It reports whether the stock is updated.
+//
+// NOTE - the adjusted stock is returned along the update, so that it does not need another query
+// which could read the stock of a concurrent adjustment.
+func (r *repository) addStock(ctx context.Context, uuid string, delta int) (int, bool, error) {
+	if r.dialect.Returning {
+		rows, err := r.db.QueryContext(
+			ctx,
+			"UPDATE product SET stock = stock + ? WHERE uuid = ? AND stock + ? >= 0 RETURNING stock",
+			delta, uuid, delta,
+		)
+
+		if err != nil {
+			return 0, false, err
+		}
+
+		defer rows.Close()
+
+		if !rows.Next() {
+			return 0, false, rows.Err()
+		}
+
+		var stock int
+		if err := rows.Scan(&stock); err != nil {
+			return 0, false, err
+		}
+
+		return stock, true, rows.Close()
+	}
+
+	// LAST_INSERT_ID(expr) returns the adjusted stock by LastInsertId on MySQL.
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE product SET stock = LAST_INSERT_ID(stock + ?) WHERE uuid = ? AND stock + ? >= 0",
+		delta, uuid, delta,
+	)
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	if err = storage.ExpectAffected(result); errors.Is(err, storage.ErrNotFound) {
+		return 0, false, nil
+	}
+
+	if err != nil {
+		return 0, false, err
+	}
+
+	stock, err := result.LastInsertId()
+
+	return int(stock), err == nil, err
+}
+
+// list is the DB implementation for the ManyFinder, the pages are in the order of the IDs.
+func (r *repository) list(ctx context.Context, offset int, limit int) (_ []*product, err error) {
+	ctx, span := tracing.Start(ctx, "product.repository.list", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
 		"SELECT p.id_product, p.name, p.brand, p.stock, s.uuid, p.uuid FROM product p "+
-			"INNER JOIN seller s ON(s.id_seller = p.fk_seller) LIMIT ? OFFSET ?",
+			"INNER JOIN seller s ON(s.id_seller = p.fk_seller) ORDER BY p.id_product LIMIT ? OFFSET ?",
 		limit, offset,
 	)
 
@@ -78,6 +276,92 @@ func (r *repository) list(offset int, limit int) ([]*product, error) {
 		return nil, err
 	}
 
+	return scanProducts(rows, "product.Repository.list")
+}
+
+// listBySeller is the DB implementation for the ManyFinder of the Seller's products.
+func (r *repository) listBySeller(ctx context.Context, sellerUUID string, offset int, limit int) (_ []*product, err error) {
+	ctx, span := tracing.Start(ctx, "product.repository.listBySeller", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT p.id_product, p.name, p.brand, p.stock, s.uuid, p.uuid FROM product p "+
+			"INNER JOIN seller s ON(s.id_seller = p.fk_seller) WHERE s.uuid = ? ORDER BY p.id_product LIMIT ? OFFSET ?",
+		sellerUUID, limit, offset,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanProducts(rows, "product.Repository.listBySeller")
+}
+
+// export is the DB implementation for the ManyFinder of all the products, which are read by a cursor
+// in the order of their IDs.
+func (r *repository) export(ctx context.Context, filter exportFilter, each func(*product) error) (err error) {
+	ctx, span := tracing.Start(ctx, "product.repository.export", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	// NOTE - the export is not bounded by the query timeout, it lasts as long as the client reads it
+	// and is canceled along with the request.
+	query := "SELECT p.id_product, p.name, p.brand, p.stock, s.uuid, p.uuid FROM product p " +
+		"INNER JOIN seller s ON(s.id_seller = p.fk_seller)"
+
+	var (
+		conditions []string
+		args       []interface{}
+	)
+
+	if filter.SellerUUID != "" {
+		conditions, args = append(conditions, "s.uuid = ?"), append(args, filter.SellerUUID)
+	}
+
+	if filter.Brand != "" {
+		conditions, args = append(conditions, "p.brand = ?"), append(args, filter.Brand)
+	}
+
+	if filter.InStock {
+		conditions = append(conditions, "p.stock > 0")
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	rows, err := r.db.QueryContext(ctx, query+" ORDER BY p.id_product", args...)
+	if err != nil {
+		return err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		product := &product{}
+
+		err := rows.Scan(&product.ProductID, &product.Name, &product.Brand, &product.Stock, &product.SellerUUID, &product.UUID)
+		if err != nil {
+			return err
+		}
+
+		if err := each(product); err != nil {
+			return err
+		}
+	}
+
+	if rows.Err() != nil {
+		return fmt.Errorf("product.Repository.export: failed to read sql.Rows: %w", rows.Err())
+	}
+
+	return nil
+}
+
+// scanProducts reads the products of the rows and closes them.
+func scanProducts(rows *sql.Rows, op string) ([]*product, error) {
 	defer rows.Close()
 
 	var products []*product
@@ -85,7 +369,7 @@ func (r *repository) list(offset int, limit int) ([]*product, error) {
 	for rows.Next() {
 		product := &product{}
 
-		err = rows.Scan(&product.ProductID, &product.Name, &product.Brand, &product.Stock, &product.SellerUUID, &product.UUID)
+		err := rows.Scan(&product.ProductID, &product.Name, &product.Brand, &product.Stock, &product.SellerUUID, &product.UUID)
 
 		if err != nil {
 			return nil, err
@@ -95,15 +379,22 @@ func (r *repository) list(offset int, limit int) ([]*product, error) {
 	}
 
 	if rows.Err() != nil {
-		return nil, fmt.Errorf("product.Repository.list: failed to read sql.Rows: %w", rows.Err())
+		return nil, fmt.Errorf("%s: failed to read sql.Rows: %w", op, rows.Err())
 	}
 
 	return products, nil
 }
 
 // findByUUID is the DB implementation for the FinderByUUID.
-func (r *repository) findByUUID(uuid string) (*product, error) {
-	rows, err := r.db.Query(
+func (r *repository) findByUUID(ctx context.Context, uuid string) (_ *product, err error) {
+	ctx, span := tracing.Start(ctx, "product.repository.findByUUID", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
 		"SELECT p.id_product, p.name, p.brand, p.stock, s.uuid, p.uuid FROM product p "+
 			"INNER JOIN seller s ON(s.id_seller = p.fk_seller) WHERE p.uuid = ?",
 		uuid,
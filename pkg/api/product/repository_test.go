@@ -1,14 +1,21 @@
 package product
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
+
+	"coding-challenge-go/pkg/storage"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
 )
 
+var errAnySQL = errors.New("any sql error")
+
 func TestRepository_list(t *testing.T) {
 	type fields struct {
 		db *sql.DB
@@ -62,21 +69,88 @@ func TestRepository_list(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &repository{
-				db: tt.fields.db,
+				db:      tt.fields.db,
+				dialect: storage.MySQL,
 			}
 
-			defer r.db.Close()
+			defer tt.fields.db.Close()
 
-			got, err := r.list(tt.args.offset, tt.args.limit)
+			got, err := r.list(context.Background(), tt.args.offset, tt.args.limit)
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.EqualValues(t, tt.want, got)
 		})
 	}
 }
 
+func TestRepository_listBySeller(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id_product", "name", "brand", "stock", "uuid", "uuid"}).
+		AddRow(1, "shoes", "nike", 10, "c943dc0a-98bb-47b4-9d1d-056b95d3f064", "e943dc0a-98bb-47b4-9d1d-056b95d3f064")
+	m.ExpectQuery("SELECT .* WHERE s.uuid = \\? ORDER BY p.id_product LIMIT \\? OFFSET \\?").
+		WithArgs("c943dc0a-98bb-47b4-9d1d-056b95d3f064", 10, 0).
+		WillReturnRows(rows)
+
+	r := &repository{db: db, dialect: storage.MySQL}
+
+	got, err := r.listBySeller(context.Background(), "c943dc0a-98bb-47b4-9d1d-056b95d3f064", 0, 10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*product{
+		{ProductID: 1, UUID: "e943dc0a-98bb-47b4-9d1d-056b95d3f064", Name: "shoes", Brand: "nike", Stock: 10, SellerUUID: "c943dc0a-98bb-47b4-9d1d-056b95d3f064"},
+	}, got)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRepository_export(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id_product", "name", "brand", "stock", "uuid", "uuid"}).
+		AddRow(1, "shoes", "nike", 10, "c943dc0a-98bb-47b4-9d1d-056b95d3f064", "e943dc0a-98bb-47b4-9d1d-056b95d3f064").
+		AddRow(2, "socks", "nike", 5, "c943dc0a-98bb-47b4-9d1d-056b95d3f064", "f943dc0a-98bb-47b4-9d1d-056b95d3f064")
+	m.ExpectQuery("SELECT .* FROM product p INNER JOIN seller s ON\\(s.id_seller = p.fk_seller\\) ORDER BY p.id_product").
+		WillReturnRows(rows)
+	m.ExpectQuery("SELECT .* WHERE s.uuid = \\? AND p.brand = \\? AND p.stock > 0 ORDER BY p.id_product").
+		WithArgs("c943dc0a-98bb-47b4-9d1d-056b95d3f064", "nike").
+		WillReturnRows(sqlmock.NewRows([]string{"id_product", "name", "brand", "stock", "uuid", "uuid"}).
+			AddRow(1, "shoes", "nike", 10, "c943dc0a-98bb-47b4-9d1d-056b95d3f064", "e943dc0a-98bb-47b4-9d1d-056b95d3f064").
+			AddRow(2, "socks", "nike", 5, "c943dc0a-98bb-47b4-9d1d-056b95d3f064", "f943dc0a-98bb-47b4-9d1d-056b95d3f064"))
+	m.ExpectQuery("SELECT .* WHERE p.brand = \\? ORDER BY p.id_product").WithArgs("adidas").WillReturnError(errAnySQL)
+
+	r := &repository{db: db, dialect: storage.MySQL}
+
+	var got []*product
+
+	err := r.export(context.Background(), exportFilter{}, func(p *product) error {
+		got = append(got, p)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*product{
+		{ProductID: 1, UUID: "e943dc0a-98bb-47b4-9d1d-056b95d3f064", Name: "shoes", Brand: "nike", Stock: 10, SellerUUID: "c943dc0a-98bb-47b4-9d1d-056b95d3f064"},
+		{ProductID: 2, UUID: "f943dc0a-98bb-47b4-9d1d-056b95d3f064", Name: "socks", Brand: "nike", Stock: 5, SellerUUID: "c943dc0a-98bb-47b4-9d1d-056b95d3f064"},
+	}, got)
+
+	// the export stops at the first error of each.
+	calls := 0
+	err = r.export(context.Background(), exportFilter{SellerUUID: "c943dc0a-98bb-47b4-9d1d-056b95d3f064", Brand: "nike", InStock: true},
+		func(p *product) error {
+			calls++
+			return errors.New("broken pipe")
+		})
+	assert.EqualError(t, err, "broken pipe")
+	assert.Equal(t, 1, calls)
+
+	err = r.export(context.Background(), exportFilter{Brand: "adidas"}, func(p *product) error { return nil })
+	assert.ErrorIs(t, err, errAnySQL)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
 func TestRepository_findByUUID(t *testing.T) {
 	type fields struct {
-		db *sql.DB
+		db           *sql.DB
+		queryTimeout time.Duration
 	}
 	type args struct {
 		uuid string
@@ -119,16 +193,35 @@ func TestRepository_findByUUID(t *testing.T) {
 			want:    nil,
 			wantErr: false,
 		},
+		{
+			name: "Returns error, when query times out",
+			fields: fields{
+				db: func() *sql.DB {
+					db, m, _ := sqlmock.New()
+					rows := sqlmock.NewRows([]string{"id_product", "name", "brand", "stock", "uuid", "uuid"}).
+						AddRow(1, "shoes", "nike", 10, "c943dc0a-98bb-47b4-9d1d-056b95d3f064", "e943dc0a-98bb-47b4-9d1d-056b95d3f064")
+					m.ExpectQuery("SELECT").WillDelayFor(time.Second).WillReturnRows(rows)
+
+					return db
+				}(),
+				queryTimeout: 10 * time.Millisecond,
+			},
+			args:    args{uuid: "c943dc0a-98bb-47b4-9d1d-056b95d3f064"},
+			want:    nil,
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &repository{
-				db: tt.fields.db,
+				db:           tt.fields.db,
+				dialect:      storage.MySQL,
+				queryTimeout: tt.fields.queryTimeout,
 			}
 
-			defer r.db.Close()
+			defer tt.fields.db.Close()
 
-			got, err := r.findByUUID(tt.args.uuid)
+			got, err := r.findByUUID(context.Background(), tt.args.uuid)
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.EqualValues(t, tt.want, got)
 		})
@@ -146,48 +239,58 @@ func TestRepository_delete(t *testing.T) {
 		name    string
 		fields  fields
 		args    args
-		wantErr bool
+		wantErr error
 	}{
 		{
 			name: "deletes the Product",
 			fields: fields{
 				db: func() *sql.DB {
 					db, m, _ := sqlmock.New()
-					rows := sqlmock.NewRows([]string{"id_product", "name", "brand", "stock", "uuid", "uuid"}).
-						AddRow(1, "shoes", "nike", 10, "c943dc0a-98bb-47b4-9d1d-056b95d3f064", "e943dc0a-98bb-47b4-9d1d-056b95d3f064")
-					m.ExpectQuery("DELETE").WillReturnRows(rows)
+					m.ExpectExec("DELETE").
+						WithArgs("e943dc0a-98bb-47b4-9d1d-056b95d3f064").
+						WillReturnResult(sqlmock.NewResult(0, 1))
 
 					return db
 				}()},
 			args:    args{p: &product{ProductID: 1, UUID: "e943dc0a-98bb-47b4-9d1d-056b95d3f064", Name: "shoes", Brand: "nike", Stock: 10, SellerUUID: "c943dc0a-98bb-47b4-9d1d-056b95d3f064"}},
-			wantErr: false,
+			wantErr: nil,
+		},
+		{
+			name: "Returns not found, when Product is already deleted",
+			fields: fields{
+				db: func() *sql.DB {
+					db, m, _ := sqlmock.New()
+					m.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 0))
+
+					return db
+				}()},
+			args:    args{p: &product{ProductID: 1, UUID: "e943dc0a-98bb-47b4-9d1d-056b95d3f064", Name: "shoes", Brand: "nike", Stock: 10, SellerUUID: "c943dc0a-98bb-47b4-9d1d-056b95d3f064"}},
+			wantErr: storage.ErrNotFound,
 		},
 		{
 			name: "Returns error",
 			fields: fields{
 				db: func() *sql.DB {
 					db, m, _ := sqlmock.New()
-					rows := sqlmock.NewRows([]string{"id_product", "name", "brand", "stock", "uuid", "uuid"}).
-						AddRow(1, "shoes", "nike", 10, "c943dc0a-98bb-47b4-9d1d-056b95d3f064", "e943dc0a-98bb-47b4-9d1d-056b95d3f064").
-						RowError(0, errors.New("any sql error"))
-					m.ExpectQuery("DELETE").WillReturnRows(rows)
+					m.ExpectExec("DELETE").WillReturnError(errAnySQL)
 
 					return db
 				}()},
 			args:    args{p: &product{ProductID: 1, UUID: "e943dc0a-98bb-47b4-9d1d-056b95d3f064", Name: "shoes", Brand: "nike", Stock: 10, SellerUUID: "c943dc0a-98bb-47b4-9d1d-056b95d3f064"}},
-			wantErr: false,
+			wantErr: errAnySQL,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &repository{
-				db: tt.fields.db,
+				db:      tt.fields.db,
+				dialect: storage.MySQL,
 			}
 
-			defer r.db.Close()
+			defer tt.fields.db.Close()
 
-			err := r.delete(tt.args.p)
-			assert.Equal(t, tt.wantErr, err != nil)
+			err := r.delete(context.Background(), tt.args.p)
+			assert.ErrorIs(t, err, tt.wantErr)
 		})
 	}
 
@@ -204,33 +307,239 @@ func TestRepository_update(t *testing.T) {
 		name    string
 		fields  fields
 		args    args
-		wantErr bool
+		wantErr error
 	}{
 		{
 			name: "updates the Product, stocks changed",
 			fields: fields{
 				db: func() *sql.DB {
 					db, m, _ := sqlmock.New()
-					rows := sqlmock.NewRows([]string{"id_product", "name", "brand", "stock", "uuid", "uuid"}).
-						AddRow(1, "shoes", "nike", 20, "c943dc0a-98bb-47b4-9d1d-056b95d3f064", "e943dc0a-98bb-47b4-9d1d-056b95d3f064")
-					m.ExpectQuery("UPDATE").WillReturnRows(rows)
+					m.ExpectExec("UPDATE").
+						WithArgs("shoes", "nike", 20, "e943dc0a-98bb-47b4-9d1d-056b95d3f064").
+						WillReturnResult(sqlmock.NewResult(0, 1))
 
 					return db
 				}()},
 			args:    args{p: &product{ProductID: 1, UUID: "e943dc0a-98bb-47b4-9d1d-056b95d3f064", Name: "shoes", Brand: "nike", Stock: 20, SellerUUID: "c943dc0a-98bb-47b4-9d1d-056b95d3f064"}},
-			wantErr: false,
+			wantErr: nil,
+		},
+		{
+			name: "Returns not found, when Product is deleted meanwhile",
+			fields: fields{
+				db: func() *sql.DB {
+					db, m, _ := sqlmock.New()
+					m.ExpectExec("UPDATE").WillReturnResult(sqlmock.NewResult(0, 0))
+
+					return db
+				}()},
+			args:    args{p: &product{ProductID: 1, UUID: "e943dc0a-98bb-47b4-9d1d-056b95d3f064", Name: "shoes", Brand: "nike", Stock: 20, SellerUUID: "c943dc0a-98bb-47b4-9d1d-056b95d3f064"}},
+			wantErr: storage.ErrNotFound,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &repository{
-				db: tt.fields.db,
+				db:      tt.fields.db,
+				dialect: storage.MySQL,
 			}
 
-			defer r.db.Close()
+			defer tt.fields.db.Close()
 
-			err := r.update(tt.args.p)
-			assert.Equal(t, tt.wantErr, err != nil)
+			err := r.update(context.Background(), tt.args.p)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRepository_adjustStock(t *testing.T) {
+	const uuid = "e943dc0a-98bb-47b4-9d1d-056b95d3f064"
+
+	tests := []struct {
+		name      string
+		db        func() *sql.DB
+		wantStock int
+		wantErr   error
+	}{
+		{
+			name: "adjusts the stock, sets the adjusted stock",
+			db: func() *sql.DB {
+				db, m, _ := sqlmock.New()
+				m.ExpectExec("UPDATE product SET stock = LAST_INSERT_ID\\(stock \\+ \\?\\) WHERE uuid = \\? AND stock \\+ \\? >= 0").
+					WithArgs(-3, uuid, -3).
+					WillReturnResult(sqlmock.NewResult(4, 1))
+
+				return db
+			},
+			wantStock: 4,
+		},
+		{
+			name: "returns insufficient stock, when the stock would be negative",
+			db: func() *sql.DB {
+				db, m, _ := sqlmock.New()
+				m.ExpectExec("UPDATE product SET stock").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectQuery("SELECT (.+) FROM product p").WithArgs(uuid).WillReturnRows(
+					sqlmock.NewRows([]string{"id_product", "name", "brand", "stock", "uuid", "uuid"}).
+						AddRow(1, "shoes", "nike", 7, "c943dc0a-98bb-47b4-9d1d-056b95d3f064", uuid))
+
+				return db
+			},
+			wantStock: 7,
+			wantErr:   ErrInsufficientStock,
+		},
+		{
+			name: "returns not found, when the product is deleted meanwhile",
+			db: func() *sql.DB {
+				db, m, _ := sqlmock.New()
+				m.ExpectExec("UPDATE product SET stock").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectQuery("SELECT (.+) FROM product p").WithArgs(uuid).WillReturnRows(
+					sqlmock.NewRows([]string{"id_product", "name", "brand", "stock", "uuid", "uuid"}))
+
+				return db
+			},
+			wantStock: 7,
+			wantErr:   storage.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := tt.db()
+			defer db.Close()
+
+			r := &repository{db: db, dialect: storage.MySQL}
+
+			p := &product{ProductID: 1, UUID: uuid, Name: "shoes", Brand: "nike", Stock: 7}
+
+			err := r.adjustStock(context.Background(), p, -3)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantStock, p.Stock)
+		})
+	}
+}
+
+func TestRepository_insert(t *testing.T) {
+	type fields struct {
+		db *sql.DB
+	}
+	type args struct {
+		p *product
+	}
+	tests := []struct {
+		name    string
+		fields  fields
+		args    args
+		wantErr error
+	}{
+		{
+			name: "inserts the Product",
+			fields: fields{
+				db: func() *sql.DB {
+					db, m, _ := sqlmock.New()
+					m.ExpectExec("INSERT").WillReturnResult(sqlmock.NewResult(1, 1))
+
+					return db
+				}()},
+			args:    args{p: &product{Name: "shoes", Brand: "nike", Stock: 20, SellerUUID: "c943dc0a-98bb-47b4-9d1d-056b95d3f064"}},
+			wantErr: nil,
+		},
+		{
+			name: "Returns foreign key violation, when Seller does not exist",
+			fields: fields{
+				db: func() *sql.DB {
+					db, m, _ := sqlmock.New()
+					m.ExpectExec("INSERT").
+						WillReturnError(&mysql.MySQLError{Number: 1048, Message: "Column 'fk_seller' cannot be null"})
+
+					return db
+				}()},
+			args:    args{p: &product{Name: "shoes", Brand: "nike", Stock: 20, SellerUUID: "c943dc0a-98bb-47b4-9d1d-056b95d3f064"}},
+			wantErr: storage.ErrForeignKey,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &repository{
+				db:      tt.fields.db,
+				dialect: storage.MySQL,
+			}
+
+			defer tt.fields.db.Close()
+
+			got, err := r.insert(context.Background(), tt.args.p)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			if tt.wantErr == nil {
+				assert.NotEmpty(t, got.UUID)
+			}
+		})
+	}
+}
+
+func TestRepository_upsert(t *testing.T) {
+	const (
+		sellerUUID  = "c943dc0a-98bb-47b4-9d1d-056b95d3f064"
+		productUUID = "e943dc0a-98bb-47b4-9d1d-056b95d3f064"
+	)
+
+	tests := []struct {
+		name        string
+		expect      func(m sqlmock.Sqlmock)
+		wantCreated bool
+		wantUUID    string
+		wantErr     error
+	}{
+		{
+			name: "updates the product of the external ID",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("SELECT (.+) FROM product p (.+) WHERE s.uuid = \\? AND p.external_id = \\?").
+					WithArgs(sellerUUID, "sku-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id_product", "name", "brand", "stock", "seller_uuid", "uuid"}).
+						AddRow(7, "shoes", "nike", 3, sellerUUID, productUUID))
+				m.ExpectExec("UPDATE product SET name = \\?, brand = \\?, stock = \\? WHERE uuid = \\?").
+					WithArgs("sneakers", "nike", 20, productUUID).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantUUID: productUUID,
+		},
+		{
+			name: "inserts the product, when there is none of the external ID",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("SELECT (.+) FROM product p").
+					WithArgs(sellerUUID, "sku-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id_product", "name", "brand", "stock", "seller_uuid", "uuid"}))
+				m.ExpectExec("INSERT INTO product (.+) VALUES").
+					WithArgs("sneakers", "nike", 20, sellerUUID, sqlmock.AnyArg(), "sku-1").
+					WillReturnResult(sqlmock.NewResult(8, 1))
+			},
+			wantCreated: true,
+		},
+		{
+			name: "returns storage.ErrConflict, when the product of the external ID is inserted concurrently",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("SELECT (.+) FROM product p").
+					WithArgs(sellerUUID, "sku-1").
+					WillReturnRows(sqlmock.NewRows([]string{"id_product", "name", "brand", "stock", "seller_uuid", "uuid"}))
+				m.ExpectExec("INSERT INTO product").
+					WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry '1-sku-1' for key 'seller_external_id'"})
+			},
+			wantErr: storage.ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, m, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.expect(m)
+
+			p := &product{Name: "sneakers", Brand: "nike", Stock: 20, SellerUUID: sellerUUID, ExternalID: "sku-1"}
+
+			created, err := NewRepository(db, 0).upsert(context.Background(), p)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantCreated, created)
+			if tt.wantUUID != "" {
+				assert.Equal(t, tt.wantUUID, p.UUID)
+			}
+			assert.NoError(t, m.ExpectationsWereMet())
 		})
 	}
 }
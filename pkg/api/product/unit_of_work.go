@@ -0,0 +1,38 @@
+package product
+
+import (
+	"context"
+	"database/sql"
+
+	sellerAPI "coding-challenge-go/pkg/api/seller"
+	"coding-challenge-go/pkg/storage"
+)
+
+// NewUnitOfWork builds the DB UnitOfWork, its steps run on the repositories bound to the
+// transactions of the manager.
+func NewUnitOfWork(manager *storage.TxManager, products *repository, sellers *sellerAPI.Repository) *unitOfWork {
+	return &unitOfWork{manager: manager, products: products, sellers: sellers}
+}
+
+// unitOfWork is the DB implementation for the UnitOfWork.
+type unitOfWork struct {
+	manager  *storage.TxManager
+	products *repository
+	sellers  *sellerAPI.Repository
+}
+
+// do is the DB implementation for the UnitOfWork, the transactions which deadlock are retried.
+func (u *unitOfWork) do(ctx context.Context, fn func(tx *Tx) error) error {
+	return u.manager.Do(ctx, func(tx *sql.Tx) error {
+		return fn(&Tx{Products: u.products.withTx(tx), Sellers: u.sellers.WithTx(tx)})
+	})
+}
+
+// controllerRepository is the TxRepository of the repositories of the controller, whose writes are
+// not bound to a transaction.
+type controllerRepository struct {
+	Inserter
+	Updater
+	Deleter
+	FinderByUUID
+}
@@ -0,0 +1,53 @@
+package product
+
+import (
+	"context"
+	"testing"
+
+	sellerAPI "coding-challenge-go/pkg/api/seller"
+	"coding-challenge-go/pkg/storage"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_unitOfWork_do(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	sellerUUID := "c943dc0a-98bb-47b4-9d1d-056b95d3f064"
+	sellerRows := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id_seller", "name", "email", "phone", "uuid"}).
+			AddRow(1, "Christene Maggio", "c.maggio@example.com", "202-555-0143", sellerUUID)
+	}
+
+	// the first attempt deadlocks, the retry runs all the steps again in a new transaction.
+	m.ExpectBegin()
+	m.ExpectQuery("SELECT (.+) FROM seller").WithArgs(sellerUUID).WillReturnRows(sellerRows())
+	m.ExpectExec("INSERT").WillReturnError(&mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"})
+	m.ExpectRollback()
+	m.ExpectBegin()
+	m.ExpectQuery("SELECT (.+) FROM seller").WithArgs(sellerUUID).WillReturnRows(sellerRows())
+	m.ExpectExec("INSERT").WillReturnResult(sqlmock.NewResult(1, 1))
+	m.ExpectCommit()
+
+	u := NewUnitOfWork(storage.NewTxManager(db, 1), NewRepository(db, 0), sellerAPI.NewRepository(db, 0))
+
+	var inserted *product
+
+	err := u.do(context.Background(), func(tx *Tx) error {
+		seller, err := tx.Sellers.FindByUUID(context.Background(), sellerUUID)
+		if err != nil {
+			return err
+		}
+
+		inserted, err = tx.Products.insert(context.Background(), &product{Name: "shoes", Brand: "nike", Stock: 20, SellerUUID: seller.UUID})
+
+		return err
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, sellerUUID, inserted.SellerUUID)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
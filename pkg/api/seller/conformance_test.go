@@ -0,0 +1,192 @@
+package seller
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"coding-challenge-go/pkg/storage"
+	"coding-challenge-go/pkg/storage/memory"
+	"coding-challenge-go/pkg/storage/storagetest"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backend is a storage backend of the conformance tests. The products of the sellers are inserted
+// directly into its DB, as the repository of the products depends on this package.
+type backend struct {
+	sellers        Store
+	insertProducts func(t *testing.T, sellerUUID string, n int)
+}
+
+func TestMemoryRepository_conformance(t *testing.T) {
+	testStoreConformance(t, func(t *testing.T) backend {
+		db := memory.New()
+
+		return backend{
+			sellers: NewMemoryRepository(db),
+			insertProducts: func(t *testing.T, sellerUUID string, n int) {
+				require.NoError(t, db.Write(context.Background(), func(tables *memory.Tables) error {
+					for i := 0; i < n; i++ {
+						product := &memory.Product{UUID: uuid.New().String(), Name: "shoes", Brand: "nike", SellerID: tables.SellerByUUID(sellerUUID).ID}
+						if err := tables.InsertProduct(product); err != nil {
+							return err
+						}
+					}
+
+					return nil
+				}))
+			},
+		}
+	})
+}
+
+func TestRepository_conformance(t *testing.T) {
+	testStoreConformance(t, sqlBackend(storagetest.OpenMySQL))
+}
+
+func TestRepository_conformancePostgres(t *testing.T) {
+	testStoreConformance(t, sqlBackend(storagetest.OpenPostgres))
+}
+
+func TestRepository_conformanceSQLite(t *testing.T) {
+	testStoreConformance(t, sqlBackend(storagetest.OpenSQLite))
+}
+
+// sqlBackend returns the function building the backend of the DBs opened by open.
+func sqlBackend(open func(t *testing.T) *sql.DB) func(t *testing.T) backend {
+	return func(t *testing.T) backend {
+		db := open(t)
+
+		return backend{
+			sellers:        NewRepository(db, 0),
+			insertProducts: insertSQLProducts(db),
+		}
+	}
+}
+
+// insertSQLProducts returns the function inserting the products of the seller into the DB.
+func insertSQLProducts(db *sql.DB) func(t *testing.T, sellerUUID string, n int) {
+	return func(t *testing.T, sellerUUID string, n int) {
+		for i := 0; i < n; i++ {
+			_, err := storage.DialectOf(db).Bind(db).ExecContext(
+				context.Background(),
+				"INSERT INTO product (name, brand, stock, fk_seller, uuid) VALUES('shoes', 'nike', 0, (SELECT id_seller FROM seller WHERE uuid = ?), ?)",
+				sellerUUID, uuid.New().String(),
+			)
+			require.NoError(t, err)
+		}
+	}
+}
+
+// testStoreConformance runs the cases of the repositories of the Sellers against the backends, which
+// must behave the same.
+func testStoreConformance(t *testing.T, newBackend func(t *testing.T) backend) {
+	ctx := context.Background()
+
+	// setup inserts the Sellers of the names, in order.
+	setup := func(t *testing.T, names ...string) (backend, []*Seller) {
+		b := newBackend(t)
+
+		var sellers []*Seller
+
+		for _, name := range names {
+			seller, err := b.sellers.Insert(ctx, &Seller{Name: name, Email: name + "@example.com", Phone: "202-555-0143"})
+			require.NoError(t, err)
+
+			sellers = append(sellers, seller)
+		}
+
+		return b, sellers
+	}
+
+	names := func(sellers []*Seller) []string {
+		var names []string
+		for _, s := range sellers {
+			names = append(names, s.Name)
+		}
+
+		return names
+	}
+
+	t.Run("inserts and finds the Seller", func(t *testing.T) {
+		b, sellers := setup(t, "james")
+
+		assert.NotZero(t, sellers[0].SellerID)
+		assert.NotEmpty(t, sellers[0].UUID)
+
+		found, err := b.sellers.FindByUUID(ctx, sellers[0].UUID)
+		require.NoError(t, err)
+		assert.Equal(t, sellers[0], found)
+
+		found, err = b.sellers.FindByUUID(ctx, "a223850e-d8ab-430a-9a1a-28628cfd52b0")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("finds the Sellers of the UUIDs", func(t *testing.T) {
+		b, sellers := setup(t, "james", "mark", "anna")
+
+		found, err := b.sellers.FindByUUIDs(ctx, []string{sellers[2].UUID, "a223850e-d8ab-430a-9a1a-28628cfd52b0", sellers[0].UUID})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"james", "anna"}, names(found))
+
+		found, err = b.sellers.FindByUUIDs(ctx, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, found)
+	})
+
+	t.Run("lists the Sellers", func(t *testing.T) {
+		b, _ := setup(t, "james", "mark")
+
+		sellers, err := b.sellers.List(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"james", "mark"}, names(sellers))
+	})
+
+	t.Run("updates the Seller", func(t *testing.T) {
+		b, sellers := setup(t, "james")
+
+		seller := sellers[0]
+		seller.Name, seller.Email, seller.Phone = "mark", "m@example.com", "202-555-0188"
+		require.NoError(t, b.sellers.Update(ctx, seller))
+
+		found, err := b.sellers.FindByUUID(ctx, seller.UUID)
+		require.NoError(t, err)
+		assert.Equal(t, seller, found)
+
+		err = b.sellers.Update(ctx, &Seller{UUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0", Name: "anna"})
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+	})
+
+	t.Run("deletes the Seller without products", func(t *testing.T) {
+		b, sellers := setup(t, "james", "mark")
+		b.insertProducts(t, sellers[1].UUID, 1)
+
+		require.NoError(t, b.sellers.Delete(ctx, sellers[0].UUID))
+
+		found, err := b.sellers.FindByUUID(ctx, sellers[0].UUID)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+
+		assert.ErrorIs(t, b.sellers.Delete(ctx, sellers[0].UUID), storage.ErrNotFound)
+		assert.ErrorIs(t, b.sellers.Delete(ctx, sellers[1].UUID), storage.ErrConflict)
+	})
+
+	t.Run("returns the top Sellers by the count of their products", func(t *testing.T) {
+		b, sellers := setup(t, "james", "mark", "anna", "lena")
+		b.insertProducts(t, sellers[0].UUID, 1)
+		b.insertProducts(t, sellers[1].UUID, 3)
+		b.insertProducts(t, sellers[3].UUID, 1)
+
+		top, err := b.sellers.top(ctx, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mark", "james", "lena"}, names(top))
+
+		top, err = b.sellers.top(ctx, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"mark", "james"}, names(top))
+	})
+}
@@ -1,21 +1,36 @@
 package seller
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 
+	"coding-challenge-go/pkg/api/apierror"
+
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 )
 
 // ManyFinder is a Finder for many Sellers.
 type ManyFinder interface {
-	list() ([]*Seller, error)
+	list(ctx context.Context) ([]*Seller, error)
 }
 
 // TopSellerFinder is a Finder for top Sellers of Products.
 type TopSellerFinder interface {
-	top(limit int) ([]*Seller, error)
+	top(ctx context.Context, limit int) ([]*Seller, error)
+}
+
+// Store is the repository of the Sellers of a storage backend, the DB or the memory.
+type Store interface {
+	ManyFinder
+	TopSellerFinder
+	FindByUUID(ctx context.Context, uuid string) (*Seller, error)
+	FindByUUIDs(ctx context.Context, uuids []string) ([]*Seller, error)
+	List(ctx context.Context) ([]*Seller, error)
+	Insert(ctx context.Context, seller *Seller) (*Seller, error)
+	Update(ctx context.Context, seller *Seller) error
+	Delete(ctx context.Context, uuid string) error
 }
 
 // controller is HTTP controller handles HTTP requests for Seller APIs.
@@ -36,19 +51,21 @@ func NewController(
 
 // List returns many sellers.
 func (pc *controller) List(c *gin.Context) {
-	sellers, err := pc.finder.list()
+	ctx := c.Request.Context()
+
+	sellers, err := pc.finder.list(ctx)
 
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to query seller list")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query seller list"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to query seller list")
+		apierror.Respond(c, apierror.StatusCode(err), apierror.CodeOf(err), "Fail to query seller list")
 		return
 	}
 
 	sellersJson, err := json.Marshal(sellers)
 
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to marshal sellers")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal sellers"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to marshal sellers")
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternalServerError, "Fail to marshal sellers")
 		return
 	}
 
@@ -58,17 +75,19 @@ func (pc *controller) List(c *gin.Context) {
 // Top10 gets the array of maximum 10 sellers ordered by count of products they
 // have for sale (count of entries in product table) from the largest to the smallest number.
 func (pc *controller) Top10(c *gin.Context) {
-	sellers, err := pc.topFinder.top(10)
+	ctx := c.Request.Context()
+
+	sellers, err := pc.topFinder.top(ctx, 10)
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to query seller list")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to query seller list"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to query seller list")
+		apierror.Respond(c, apierror.StatusCode(err), apierror.CodeOf(err), "Fail to query seller list")
 		return
 	}
 
 	sellersJson, err := json.Marshal(sellers)
 	if err != nil {
-		log.Error().Err(err).Msg("Fail to marshal sellers")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Fail to marshal sellers"})
+		log.Ctx(ctx).Error().Err(err).Msg("Fail to marshal sellers")
+		apierror.Respond(c, http.StatusInternalServerError, apierror.CodeInternalServerError, "Fail to marshal sellers")
 		return
 	}
 
@@ -1,11 +1,14 @@
 package seller
 
 import (
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"coding-challenge-go/pkg/api/middleware"
+
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -16,13 +19,13 @@ type ManyFinderMock struct {
 	mock.Mock
 }
 
-// list provides a mock function with given fields:
-func (_m *ManyFinderMock) list() ([]*Seller, error) {
-	ret := _m.Called()
+// list provides a mock function with given fields: ctx
+func (_m *ManyFinderMock) list(ctx context.Context) ([]*Seller, error) {
+	ret := _m.Called(ctx)
 
 	var r0 []*Seller
-	if rf, ok := ret.Get(0).(func() []*Seller); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context) []*Seller); ok {
+		r0 = rf(ctx)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*Seller)
@@ -30,8 +33,8 @@ func (_m *ManyFinderMock) list() ([]*Seller, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -44,13 +47,13 @@ type TopSellerFinderMock struct {
 	mock.Mock
 }
 
-// top provides a mock function with given fields: limit
-func (_m *TopSellerFinderMock) top(limit int) ([]*Seller, error) {
-	ret := _m.Called(limit)
+// top provides a mock function with given fields: ctx, limit
+func (_m *TopSellerFinderMock) top(ctx context.Context, limit int) ([]*Seller, error) {
+	ret := _m.Called(ctx, limit)
 
 	var r0 []*Seller
-	if rf, ok := ret.Get(0).(func(int) []*Seller); ok {
-		r0 = rf(limit)
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*Seller); ok {
+		r0 = rf(ctx, limit)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]*Seller)
@@ -58,8 +61,8 @@ func (_m *TopSellerFinderMock) top(limit int) ([]*Seller, error) {
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(int) error); ok {
-		r1 = rf(limit)
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -100,7 +103,7 @@ func Test_controller_List(t *testing.T) {
 							Phone:    "456-23-23",
 						},
 					}
-					m.On("list").Return(sellers, nil)
+					m.On("list", mock.Anything).Return(sellers, nil)
 					return m
 				}(),
 			},
@@ -113,7 +116,7 @@ func Test_controller_List(t *testing.T) {
 			fields: fields{
 				finder: func() ManyFinder {
 					m := new(ManyFinderMock)
-					m.On("list").Return(nil, errors.New("any error from repo"))
+					m.On("list", mock.Anything).Return(nil, errors.New("any error from repo"))
 					return m
 				}(),
 			},
@@ -126,6 +129,7 @@ func Test_controller_List(t *testing.T) {
 
 		pc := NewController(tt.fields.finder, tt.fields.topFinder)
 		r := gin.Default()
+		r.Use(middleware.APIVersionResolver)
 
 		r.GET("/api/v1/sellers", pc.List)
 
@@ -176,7 +180,7 @@ func Test_controller_Top10(t *testing.T) {
 							Phone:    "456-23-23",
 						},
 					}
-					m.On("top", 10).Return(sellers, nil)
+					m.On("top", mock.Anything, 10).Return(sellers, nil)
 					return m
 				}(),
 			},
@@ -189,19 +193,20 @@ func Test_controller_Top10(t *testing.T) {
 			fields: fields{
 				topFinder: func() TopSellerFinder {
 					m := new(TopSellerFinderMock)
-					m.On("top", 10).Return(nil, errors.New("any error from repo"))
+					m.On("top", mock.Anything, 10).Return(nil, errors.New("any error from repo"))
 					return m
 				}(),
 			},
 			path:      "/api/v2/sellers/top10",
 			expStatus: http.StatusInternalServerError,
-			expBody:   `{"error": "Fail to query seller list"}`,
+			expBody:   `{"type":"https://api.gfg.com/problems/internal_server_error","title":"Internal Server Error","status":500,"detail":"Fail to query seller list","instance":"/api/v2/sellers/top10","code":"internal_server_error"}`,
 		},
 	}
 	for _, tt := range tests {
 
 		sc := NewController(tt.fields.finder, tt.fields.topFinder)
 		r := gin.Default()
+		r.Use(middleware.APIVersionResolver)
 
 		r.GET("/api/v2/sellers/top10", sc.Top10)
 
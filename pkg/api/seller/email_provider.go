@@ -1,23 +1,34 @@
 package seller
 
 import (
-	"fmt"
+	"context"
+
+	"coding-challenge-go/pkg/redact"
+	"coding-challenge-go/pkg/tracing"
 
 	"github.com/rs/zerolog/log"
 )
 
 // NewEmailProvider builds the EmailProvider with all ite provided dependencies.
-func NewEmailProvider() EmailProvider {
-	return EmailProvider{}
+func NewEmailProvider(redactor *redact.Redactor) EmailProvider {
+	return EmailProvider{redactor: redactor}
 }
 
 // EmailProvider implements the email sending to given email id.
 type EmailProvider struct {
+	redactor *redact.Redactor
 }
 
 // StockChanged sends an Email to Seller to their email id, when a Product Stock is changed.
-func (ep EmailProvider) StockChanged(sellerUUID, sellerEmail string, oldStock, newStock int, product string) {
-	log.Print(
-		fmt.Sprintf("Email Warning sent to %s (Email: %s): %s Product stock changed", sellerUUID, sellerEmail, product),
-	)
+func (ep EmailProvider) StockChanged(ctx context.Context, sellerUUID, sellerEmail string, oldStock, newStock int, product string) {
+	ctx, span := tracing.Start(ctx, "seller.EmailProvider.StockChanged")
+	defer span.End()
+
+	log.Ctx(ctx).Debug().
+		Str("seller_uuid", sellerUUID).
+		Str("email", ep.redactor.Email(sellerEmail)).
+		Str("product", product).
+		Int("old_stock", oldStock).
+		Int("new_stock", newStock).
+		Msg("Email Warning sent: Product stock changed")
 }
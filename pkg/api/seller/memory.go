@@ -0,0 +1,151 @@
+package seller
+
+import (
+	"context"
+	"sort"
+
+	"coding-challenge-go/pkg/storage"
+	"coding-challenge-go/pkg/storage/memory"
+
+	"github.com/google/uuid"
+)
+
+// NewMemoryRepository builds the in-memory repository of the Sellers of the DB.
+func NewMemoryRepository(db *memory.DB) *MemoryRepository {
+	return &MemoryRepository{db: db}
+}
+
+// MemoryRepository is the in-memory implementation of the repositories of the Sellers, it behaves
+// like the DB Repository.
+type MemoryRepository struct {
+	db *memory.DB
+}
+
+func toSeller(row *memory.Seller) *Seller {
+	return &Seller{SellerID: row.ID, UUID: row.UUID, Name: row.Name, Email: row.Email, Phone: row.Phone}
+}
+
+// FindByUUID is the in-memory implementation for the product.SellerFinder.
+func (r *MemoryRepository) FindByUUID(ctx context.Context, uuid string) (*Seller, error) {
+	var seller *Seller
+
+	err := r.db.Read(ctx, func(t *memory.Tables) error {
+		if row := t.SellerByUUID(uuid); row != nil {
+			seller = toSeller(row)
+		}
+
+		return nil
+	})
+
+	return seller, err
+}
+
+// FindByUUIDs is the in-memory implementation for the product.SellerFinder of many Sellers.
+func (r *MemoryRepository) FindByUUIDs(ctx context.Context, uuids []string) ([]*Seller, error) {
+	var sellers []*Seller
+
+	err := r.db.Read(ctx, func(t *memory.Tables) error {
+		for _, uuid := range uuids {
+			if row := t.SellerByUUID(uuid); row != nil {
+				sellers = append(sellers, toSeller(row))
+			}
+		}
+
+		return nil
+	})
+
+	return sellers, err
+}
+
+// list is the in-memory implementation for the ManyFinder.
+func (r *MemoryRepository) list(ctx context.Context) ([]*Seller, error) {
+	var sellers []*Seller
+
+	err := r.db.Read(ctx, func(t *memory.Tables) error {
+		for _, row := range t.Sellers() {
+			sellers = append(sellers, toSeller(row))
+		}
+
+		return nil
+	})
+
+	return sellers, err
+}
+
+// top is the in-memory implementation of TopSellerFinder.
+func (r *MemoryRepository) top(ctx context.Context, limit int) ([]*Seller, error) {
+	var sellers []*Seller
+
+	err := r.db.Read(ctx, func(t *memory.Tables) error {
+		counts := map[int]int{}
+		for _, row := range t.Products() {
+			counts[row.SellerID]++
+		}
+
+		for _, row := range t.Sellers() {
+			if counts[row.ID] > 0 {
+				sellers = append(sellers, toSeller(row))
+			}
+		}
+
+		// NOTE - the sellers are in the order of their IDs, which breaks the ties of the counts.
+		sort.SliceStable(sellers, func(i, j int) bool {
+			return counts[sellers[i].SellerID] > counts[sellers[j].SellerID]
+		})
+
+		return nil
+	})
+
+	if len(sellers) > limit {
+		sellers = sellers[:limit]
+	}
+
+	return sellers, err
+}
+
+// List returns all the Sellers, e.g. for the admin tools.
+func (r *MemoryRepository) List(ctx context.Context) ([]*Seller, error) {
+	return r.list(ctx)
+}
+
+// Insert is the in-memory implementation of Repository.Insert.
+func (r *MemoryRepository) Insert(ctx context.Context, seller *Seller) (*Seller, error) {
+	err := r.db.Write(ctx, func(t *memory.Tables) error {
+		row := &memory.Seller{UUID: uuid.New().String(), Name: seller.Name, Email: seller.Email, Phone: seller.Phone}
+
+		if err := t.InsertSeller(row); err != nil {
+			return err
+		}
+
+		seller.SellerID, seller.UUID = row.ID, row.UUID
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return seller, nil
+}
+
+// Update is the in-memory implementation of Repository.Update.
+func (r *MemoryRepository) Update(ctx context.Context, seller *Seller) error {
+	return r.db.Write(ctx, func(t *memory.Tables) error {
+		row := t.SellerByUUID(seller.UUID)
+		if row == nil {
+			return storage.ErrNotFound
+		}
+
+		row.Name, row.Email, row.Phone = seller.Name, seller.Email, seller.Phone
+
+		return nil
+	})
+}
+
+// Delete is the in-memory implementation of Repository.Delete.
+func (r *MemoryRepository) Delete(ctx context.Context, uuid string) error {
+	return r.db.Write(ctx, func(t *memory.Tables) error {
+		return t.DeleteSeller(uuid)
+	})
+}
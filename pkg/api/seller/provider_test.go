@@ -0,0 +1,45 @@
+package seller
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"coding-challenge-go/pkg/redact"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProviders_redactedLog logs through the providers to the redacting writer, which must not mask
+// again the values masked by the providers.
+func TestProviders_redactedLog(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      redact.Mode
+		wantPhone string
+		wantEmail string
+	}{
+		{name: "full", mode: redact.ModeFull, wantPhone: `"phone":"[REDACTED]"`, wantEmail: `"email":"[REDACTED]"`},
+		{name: "partial", mode: redact.ModePartial, wantPhone: `"phone":"***4567"`, wantEmail: `"email":"o***@seller.com"`},
+		{name: "hashed", mode: redact.ModeHashed, wantPhone: `"phone":"sha256:95988812379ab0d7"`, wantEmail: `"email":"sha256:33a1398bd8be74f7"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redactor, err := redact.New(tt.mode, "key", false)
+			require.NoError(t, err)
+
+			buf := &bytes.Buffer{}
+			ctx := zerolog.New(redactor.Writer(buf)).WithContext(context.Background())
+
+			NewSMSProvider(redactor).StockChanged(ctx, "bdbba8f8", "202-555-4567", 1, 2, "shoes")
+			assert.Contains(t, buf.String(), tt.wantPhone)
+
+			buf.Reset()
+
+			NewEmailProvider(redactor).StockChanged(ctx, "bdbba8f8", "owen.ringgold@seller.com", 1, 2, "shoes")
+			assert.Contains(t, buf.String(), tt.wantEmail)
+		})
+	}
+}
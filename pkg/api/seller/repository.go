@@ -1,25 +1,50 @@
 package seller
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
+	"coding-challenge-go/pkg/storage"
+	"coding-challenge-go/pkg/tracing"
+
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
 // NewRepository builds a new DB repo for Seller.
-func NewRepository(db *sql.DB) *Repository {
-	return &Repository{db: db}
+func NewRepository(db *sql.DB, queryTimeout time.Duration) *Repository {
+	dialect := storage.DialectOf(db)
+
+	return &Repository{db: dialect.Bind(db), dialect: dialect, queryTimeout: queryTimeout}
 }
 
 // Repository is DB repo.
 type Repository struct {
-	db *sql.DB
+	// db is the DB, or the transaction of the Repository bound to it by WithTx.
+	db storage.DBTX
+	// dialect is the dialect of the DB, its queries are adapted to it.
+	dialect *storage.Dialect
+	// queryTimeout bounds every query, it is not bounded when it is not positive.
+	queryTimeout time.Duration
+}
+
+// WithTx returns the Repository bound to the transaction, its queries run on the transaction.
+func (r *Repository) WithTx(tx storage.DBTX) *Repository {
+	return &Repository{db: r.dialect.Bind(tx), dialect: r.dialect, queryTimeout: r.queryTimeout}
 }
 
 // FindByUUID is the DB implementation for the product.SellerFinder.
-func (r *Repository) FindByUUID(uuid string) (*Seller, error) {
-	rows, err := r.db.Query("SELECT id_seller, name, email, phone, uuid FROM seller WHERE uuid = ?", uuid)
+func (r *Repository) FindByUUID(ctx context.Context, uuid string) (_ *Seller, err error) {
+	ctx, span := tracing.Start(ctx, "seller.Repository.FindByUUID", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT id_seller, name, email, phone, uuid FROM seller WHERE uuid = ?", uuid)
 
 	if err != nil {
 		return nil, err
@@ -42,9 +67,66 @@ func (r *Repository) FindByUUID(uuid string) (*Seller, error) {
 	return seller, nil
 }
 
+// FindByUUIDs is the DB implementation for the product.SellerFinder of many Sellers,
+// it queries all of them at once. The missing Sellers are not returned.
+func (r *Repository) FindByUUIDs(ctx context.Context, uuids []string) (_ []*Seller, err error) {
+	ctx, span := tracing.Start(ctx, "seller.Repository.FindByUUIDs", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	if len(uuids) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	args := make([]interface{}, 0, len(uuids))
+	for _, uuid := range uuids {
+		args = append(args, uuid)
+	}
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT id_seller, name, email, phone, uuid FROM seller WHERE uuid IN (?"+strings.Repeat(",?", len(uuids)-1)+")",
+		args...,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var sellers []*Seller
+
+	for rows.Next() {
+		seller := &Seller{}
+
+		err := rows.Scan(&seller.SellerID, &seller.Name, &seller.Email, &seller.Phone, &seller.UUID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sellers = append(sellers, seller)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("seller.Repository.FindByUUIDs: failed to read sql.Rows: %w", rows.Err())
+	}
+
+	return sellers, nil
+}
+
 // list is the DB implementation for the ManyFinder.
-func (r *Repository) list() ([]*Seller, error) {
-	rows, err := r.db.Query("SELECT id_seller, name, email, phone, uuid FROM seller")
+func (r *Repository) list(ctx context.Context) (_ []*Seller, err error) {
+	ctx, span := tracing.Start(ctx, "seller.Repository.list", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(ctx, "SELECT id_seller, name, email, phone, uuid FROM seller")
 
 	if err != nil {
 		return nil, err
@@ -76,19 +158,26 @@ func (r *Repository) list() ([]*Seller, error) {
 //
 // Returns the Sellers who are selling products ordered by count of products
 // they have for sale from the largest to the smallest number limited by given limit.
-func (r *Repository) top(limit int) ([]*Seller, error) {
-	query := "SELECT id_seller, name, email, phone, uuid FROM seller" +
-		" WHERE id_seller IN (SELECT fk_seller FROM product GROUP BY fk_seller ORDER BY COUNT(*) DESC)" +
-		" LIMIT ?"
+func (r *Repository) top(ctx context.Context, limit int) (_ []*Seller, err error) {
+	ctx, span := tracing.Start(ctx, "seller.Repository.top", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
 
-	rows, err := r.db.Query(query, limit)
+	// NOTE - the ties of the counts are broken by the IDs, so that the order is stable.
+	query := "SELECT s.id_seller, s.name, s.email, s.phone, s.uuid FROM seller s" +
+		" INNER JOIN product p ON(p.fk_seller = s.id_seller)" +
+		" GROUP BY s.id_seller, s.name, s.email, s.phone, s.uuid ORDER BY COUNT(*) DESC, s.id_seller LIMIT ?"
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
 	if err != nil {
 		return nil, err
 	}
 
 	defer func() {
 		if err := rows.Close(); err != nil {
-			log.Log().Err(err).Msg("seller.Repository: failed to close the sql.Rows")
+			log.Ctx(ctx).Error().Err(err).Msg("seller.Repository: failed to close the sql.Rows")
 		}
 	}()
 
@@ -111,3 +200,78 @@ func (r *Repository) top(limit int) ([]*Seller, error) {
 
 	return sellers, nil
 }
+
+// List returns all the Sellers, e.g. for the admin tools.
+func (r *Repository) List(ctx context.Context) ([]*Seller, error) {
+	return r.list(ctx)
+}
+
+// Insert inserts the Seller, its UUID is generated.
+func (r *Repository) Insert(ctx context.Context, seller *Seller) (_ *Seller, err error) {
+	ctx, span := tracing.Start(ctx, "seller.Repository.Insert", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	seller.UUID = uuid.New().String()
+
+	id, err := r.dialect.InsertID(
+		ctx,
+		r.db,
+		"INSERT INTO seller (name, email, phone, uuid) VALUES(?,?,?,?)",
+		"id_seller",
+		seller.Name, seller.Email, seller.Phone, seller.UUID,
+	)
+
+	if err != nil {
+		return nil, storage.FromDB(err)
+	}
+
+	seller.SellerID = int(id)
+
+	return seller, nil
+}
+
+// Update updates the name and the contact details of the Seller.
+//
+// Returns storage.ErrNotFound, when the Seller does not exist.
+func (r *Repository) Update(ctx context.Context, seller *Seller) (err error) {
+	ctx, span := tracing.Start(ctx, "seller.Repository.Update", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE seller SET name = ?, email = ?, phone = ? WHERE uuid = ?",
+		seller.Name, seller.Email, seller.Phone, seller.UUID,
+	)
+
+	if err != nil {
+		return storage.FromDB(err)
+	}
+
+	return storage.ExpectAffected(result)
+}
+
+// Delete deletes the Seller.
+//
+// Returns storage.ErrNotFound, when the Seller does not exist. Returns storage.ErrConflict, when
+// the Seller still has products or API keys.
+func (r *Repository) Delete(ctx context.Context, uuid string) (err error) {
+	ctx, span := tracing.Start(ctx, "seller.Repository.Delete", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, "DELETE FROM seller WHERE uuid = ?", uuid)
+
+	if err != nil {
+		return storage.FromDB(err)
+	}
+
+	return storage.ExpectAffected(result)
+}
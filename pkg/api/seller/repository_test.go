@@ -1,11 +1,16 @@
 package seller
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"errors"
 	"testing"
 
+	"coding-challenge-go/pkg/storage"
+
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -62,12 +67,13 @@ func TestRepository_top(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &Repository{
-				db: tt.fields.db,
+				db:      tt.fields.db,
+				dialect: storage.MySQL,
 			}
 
-			defer r.db.Close()
+			defer tt.fields.db.Close()
 
-			got, err := r.top(tt.args.limit)
+			got, err := r.top(context.Background(), tt.args.limit)
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.EqualValues(t, tt.want, got)
 		})
@@ -127,14 +133,116 @@ func TestRepository_list(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			r := &Repository{
-				db: tt.fields.db,
+				db:      tt.fields.db,
+				dialect: storage.MySQL,
 			}
 
-			defer r.db.Close()
+			defer tt.fields.db.Close()
 
-			got, err := r.list()
+			got, err := r.list(context.Background())
 			assert.Equal(t, tt.wantErr, err != nil)
 			assert.EqualValues(t, tt.want, got)
 		})
 	}
 }
+
+func TestRepository_FindByUUIDs(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id_seller", "name", "email", "phone", "uuid"}).
+		AddRow(3, "james", "j@ex.com", "323-23423-3", "c943dc0a-98bb-47b4-9d1d-056b95d3f064").
+		AddRow(2, "mark", "m@ex.com", "789-23423-3", "d943dc0a-98bb-47b4-9d1d-056b95d3f064")
+	m.ExpectQuery(`SELECT .* WHERE uuid IN \(\?,\?\)`).
+		WithArgs("c943dc0a-98bb-47b4-9d1d-056b95d3f064", "d943dc0a-98bb-47b4-9d1d-056b95d3f064").
+		WillReturnRows(rows)
+
+	r := &Repository{db: db, dialect: storage.MySQL}
+
+	got, err := r.FindByUUIDs(context.Background(), []string{"c943dc0a-98bb-47b4-9d1d-056b95d3f064", "d943dc0a-98bb-47b4-9d1d-056b95d3f064"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*Seller{
+		{3, "c943dc0a-98bb-47b4-9d1d-056b95d3f064", "james", "j@ex.com", "323-23423-3"},
+		{2, "d943dc0a-98bb-47b4-9d1d-056b95d3f064", "mark", "m@ex.com", "789-23423-3"},
+	}, got)
+	assert.NoError(t, m.ExpectationsWereMet())
+
+	got, err = r.FindByUUIDs(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestRepository_Insert(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	m.ExpectExec(`INSERT INTO seller \(name, email, phone, uuid\) VALUES\(\?,\?,\?,\?\)`).
+		WithArgs("james", "j@ex.com", "323-23423-3", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(15, 1))
+
+	got, err := NewRepository(db, 0).Insert(context.Background(), &Seller{Name: "james", Email: "j@ex.com", Phone: "323-23423-3"})
+	assert.NoError(t, err)
+	assert.Equal(t, 15, got.SellerID)
+	assert.Len(t, got.UUID, 36)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRepository_Update(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	m.ExpectExec(`UPDATE seller SET name = \?, email = \?, phone = \? WHERE uuid = \?`).
+		WithArgs("james", "j@ex.com", "323-23423-3", "c943dc0a-98bb-47b4-9d1d-056b95d3f064").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := NewRepository(db, 0).Update(context.Background(), &Seller{
+		UUID: "c943dc0a-98bb-47b4-9d1d-056b95d3f064", Name: "james", Email: "j@ex.com", Phone: "323-23423-3",
+	})
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRepository_Delete(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  driver.Result
+		err     error
+		wantErr error
+	}{
+		{
+			name:   "deletes the seller",
+			result: sqlmock.NewResult(0, 1),
+		},
+		{
+			name:    "returns storage.ErrNotFound, when the seller does not exist",
+			result:  sqlmock.NewResult(0, 0),
+			wantErr: storage.ErrNotFound,
+		},
+		{
+			name:    "returns storage.ErrConflict, when the seller still has products",
+			err:     &mysql.MySQLError{Number: 1451, Message: "Cannot delete or update a parent row"},
+			wantErr: storage.ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, m, _ := sqlmock.New()
+			defer db.Close()
+
+			e := m.ExpectExec(`DELETE FROM seller WHERE uuid = \?`).WithArgs("c943dc0a-98bb-47b4-9d1d-056b95d3f064")
+			if tt.err != nil {
+				e.WillReturnError(tt.err)
+			} else {
+				e.WillReturnResult(tt.result)
+			}
+
+			err := NewRepository(db, 0).Delete(context.Background(), "c943dc0a-98bb-47b4-9d1d-056b95d3f064")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, m.ExpectationsWereMet())
+		})
+	}
+}
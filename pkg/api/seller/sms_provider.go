@@ -1,23 +1,34 @@
 package seller
 
 import (
-	"fmt"
+	"context"
+
+	"coding-challenge-go/pkg/redact"
+	"coding-challenge-go/pkg/tracing"
 
 	"github.com/rs/zerolog/log"
 )
 
 // SMSProvider implements the SMS Sending to given phone number.
 type SMSProvider struct {
+	redactor *redact.Redactor
 }
 
 // NewSMSProvider builds the SMSProvider with all ite provided dependencies.
-func NewSMSProvider() SMSProvider {
-	return SMSProvider{}
+func NewSMSProvider(redactor *redact.Redactor) SMSProvider {
+	return SMSProvider{redactor: redactor}
 }
 
 // StockChanged sends an SMS to Seller to their phone, when a Product Stock is changed.
-func (sp SMSProvider) StockChanged(sellerUUID string, sellerPhone string, oldStock int, newStock int, product string) {
-	log.Print(
-		fmt.Sprintf("SMS Warning sent to %s (Phone: %s): %s Product stock changed", sellerUUID, sellerPhone, product),
-	)
+func (sp SMSProvider) StockChanged(ctx context.Context, sellerUUID string, sellerPhone string, oldStock int, newStock int, product string) {
+	ctx, span := tracing.Start(ctx, "seller.SMSProvider.StockChanged")
+	defer span.End()
+
+	log.Ctx(ctx).Debug().
+		Str("seller_uuid", sellerUUID).
+		Str("phone", sp.redactor.Phone(sellerPhone)).
+		Str("product", product).
+		Int("old_stock", oldStock).
+		Int("new_stock", newStock).
+		Msg("SMS Warning sent: Product stock changed")
 }
@@ -0,0 +1,224 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"coding-challenge-go/pkg/storage"
+	"coding-challenge-go/pkg/tracing"
+)
+
+// keyPrefix prefixes the API keys, so that they are recognizable, e.g. by secret scanners.
+const keyPrefix = "gfg_"
+
+// APIKey is an API key, its plain key is known only when it is created.
+type APIKey struct {
+	Name   string
+	Scopes []Scope
+	// SellerUUID is the seller the key is bound to, empty for the keys of the platform.
+	SellerUUID string
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
+
+// GenerateKey generates a new random API key.
+func GenerateKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("auth: failed to generate key: %w", err)
+	}
+
+	return keyPrefix + hex.EncodeToString(b), nil
+}
+
+// HashKey returns the hash of the API key which is stored instead of the key.
+//
+// NOTE - the keys are random with 256 bits of entropy, so a fast hash is enough
+// unlike the passwords.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Repository is the DB repository of the API keys. The keys are queried on every request,
+// so that created and revoked keys take effect without a restart.
+type Repository struct {
+	db           storage.DBTX
+	dialect      *storage.Dialect
+	queryTimeout time.Duration
+}
+
+// NewRepository builds the API key repository.
+func NewRepository(db *sql.DB, queryTimeout time.Duration) *Repository {
+	dialect := storage.DialectOf(db)
+
+	return &Repository{db: dialect.Bind(db), dialect: dialect, queryTimeout: queryTimeout}
+}
+
+// FindByKey returns the principal of the API key, nil when the key is unknown or revoked.
+func (r *Repository) FindByKey(ctx context.Context, key string) (_ *Principal, err error) {
+	ctx, span := tracing.Start(ctx, "auth.Repository.FindByKey", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT k.id_api_key, k.name, k.scopes, s.uuid FROM api_key k LEFT JOIN seller s ON(s.id_seller = k.fk_seller) "+
+			"WHERE k.key_hash = ? AND k.revoked_at IS NULL",
+		HashKey(key),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		return nil, rows.Err()
+	}
+
+	var (
+		id           int64
+		name, scopes string
+		sellerUUID   sql.NullString
+	)
+
+	if err = rows.Scan(&id, &name, &scopes, &sellerUUID); err != nil {
+		return nil, err
+	}
+
+	parsed, err := ParseScopes(scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Principal{ID: "apikey:" + strconv.FormatInt(id, 10), Name: name, Scopes: parsed, SellerUUID: sellerUUID.String}, nil
+}
+
+// Create creates the API key of the name and returns its plain key, which is not stored.
+// The key is bound to the seller unless sellerUUID is empty, it returns storage.ErrNotFound
+// when the seller does not exist.
+func (r *Repository) Create(ctx context.Context, name string, scopes []Scope, sellerUUID string) (_ string, err error) {
+	ctx, span := tracing.Start(ctx, "auth.Repository.Create", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	key, err := GenerateKey()
+	if err != nil {
+		return "", err
+	}
+
+	var result sql.Result
+
+	if sellerUUID == "" {
+		result, err = r.db.ExecContext(
+			ctx,
+			"INSERT INTO api_key (name, key_hash, scopes) VALUES(?,?,?)",
+			name, HashKey(key), FormatScopes(scopes),
+		)
+	} else {
+		// NOTE - nothing is inserted for the missing seller, rather than a key which is not bound to any.
+		result, err = r.db.ExecContext(
+			ctx,
+			"INSERT INTO api_key (name, key_hash, scopes, fk_seller) SELECT ?, ?, ?, id_seller FROM seller WHERE uuid = ?",
+			name, HashKey(key), FormatScopes(scopes), sellerUUID,
+		)
+	}
+
+	if err != nil {
+		return "", storage.FromDB(err)
+	}
+
+	if err = storage.ExpectAffected(result); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// Revoke revokes the API key of the name, it returns storage.ErrNotFound when there is no such active key.
+func (r *Repository) Revoke(ctx context.Context, name string) (err error) {
+	ctx, span := tracing.Start(ctx, "auth.Repository.Revoke", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE api_key SET revoked_at = CURRENT_TIMESTAMP WHERE name = ? AND revoked_at IS NULL",
+		name,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return storage.ExpectAffected(result)
+}
+
+// List returns all the API keys, including the revoked ones.
+func (r *Repository) List(ctx context.Context) (_ []*APIKey, err error) {
+	ctx, span := tracing.Start(ctx, "auth.Repository.List", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT k.name, k.scopes, s.uuid, k.created_at, k.revoked_at FROM api_key k "+
+			"LEFT JOIN seller s ON(s.id_seller = k.fk_seller) ORDER BY k.name",
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var keys []*APIKey
+
+	for rows.Next() {
+		var (
+			key        = &APIKey{}
+			scopes     string
+			sellerUUID sql.NullString
+			revokedAt  sql.NullTime
+		)
+
+		if err := rows.Scan(&key.Name, &scopes, &sellerUUID, &key.CreatedAt, &revokedAt); err != nil {
+			return nil, err
+		}
+
+		key.SellerUUID = sellerUUID.String
+
+		if key.Scopes, err = ParseScopes(scopes); err != nil {
+			return nil, err
+		}
+
+		if revokedAt.Valid {
+			key.RevokedAt = &revokedAt.Time
+		}
+
+		keys = append(keys, key)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("auth.Repository.List: failed to read sql.Rows: %w", rows.Err())
+	}
+
+	return keys, nil
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+
+	"coding-challenge-go/pkg/storage"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateKey(t *testing.T) {
+	key, err := GenerateKey()
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "gfg_"))
+	assert.Len(t, key, len("gfg_")+64)
+
+	other, err := GenerateKey()
+	assert.NoError(t, err)
+	assert.NotEqual(t, key, other)
+}
+
+func TestRepository_FindByKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		err     error
+		want    *Principal
+		wantErr bool
+	}{
+		{
+			name: "returns the principal of the key",
+			rows: sqlmock.NewRows([]string{"id_api_key", "name", "scopes", "uuid"}).AddRow(1, "shop", "products:read,products:write", nil),
+			want: &Principal{ID: "apikey:1", Name: "shop", Scopes: []Scope{ScopeProductsRead, ScopeProductsWrite}},
+		},
+		{
+			name: "returns the principal of the key bound to a seller",
+			rows: sqlmock.NewRows([]string{"id_api_key", "name", "scopes", "uuid"}).AddRow(2, "seller", "products:write", "sellerUUID"),
+			want: &Principal{ID: "apikey:2", Name: "seller", Scopes: []Scope{ScopeProductsWrite}, SellerUUID: "sellerUUID"},
+		},
+		{
+			name: "returns nil, when the key is unknown or revoked",
+			rows: sqlmock.NewRows([]string{"id_api_key", "name", "scopes", "uuid"}),
+			want: nil,
+		},
+		{
+			name:    "returns error",
+			err:     errors.New("any sql error"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, m, _ := sqlmock.New()
+			defer db.Close()
+
+			q := m.ExpectQuery("SELECT k.id_api_key, k.name, k.scopes, s.uuid FROM api_key k LEFT JOIN seller s .* WHERE k.key_hash = \\? AND k.revoked_at IS NULL").
+				WithArgs(HashKey("gfg_key"))
+			if tt.err != nil {
+				q.WillReturnError(tt.err)
+			} else {
+				q.WillReturnRows(tt.rows)
+			}
+
+			got, err := NewRepository(db, 0).FindByKey(context.Background(), "gfg_key")
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRepository_Create(t *testing.T) {
+	tests := []struct {
+		name       string
+		sellerUUID string
+		query      string
+		args       []driver.Value
+		affected   int64
+		wantErr    error
+	}{
+		{
+			name:     "creates the key",
+			query:    "INSERT INTO api_key \\(name, key_hash, scopes\\) VALUES",
+			args:     []driver.Value{"shop", sqlmock.AnyArg(), "products:read,sellers:admin"},
+			affected: 1,
+		},
+		{
+			name:       "creates the key bound to the seller",
+			sellerUUID: "sellerUUID",
+			query:      "INSERT INTO api_key \\(name, key_hash, scopes, fk_seller\\) SELECT .* FROM seller WHERE uuid = \\?",
+			args:       []driver.Value{"shop", sqlmock.AnyArg(), "products:read,sellers:admin", "sellerUUID"},
+			affected:   1,
+		},
+		{
+			name:       "returns not found, when the seller does not exist",
+			sellerUUID: "unknown",
+			query:      "INSERT INTO api_key \\(name, key_hash, scopes, fk_seller\\) SELECT",
+			args:       []driver.Value{"shop", sqlmock.AnyArg(), "products:read,sellers:admin", "unknown"},
+			affected:   0,
+			wantErr:    storage.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, m, _ := sqlmock.New()
+			defer db.Close()
+
+			m.ExpectExec(tt.query).WithArgs(tt.args...).WillReturnResult(sqlmock.NewResult(1, tt.affected))
+
+			key, err := NewRepository(db, 0).Create(
+				context.Background(), "shop", []Scope{ScopeProductsRead, ScopeSellersAdmin}, tt.sellerUUID,
+			)
+			assert.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr == nil {
+				assert.True(t, strings.HasPrefix(key, "gfg_"))
+			}
+			assert.NoError(t, m.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_Revoke(t *testing.T) {
+	tests := []struct {
+		name     string
+		affected int64
+		wantErr  error
+	}{
+		{
+			name:     "revokes the key",
+			affected: 1,
+		},
+		{
+			name:     "returns not found, when there is no active key of the name",
+			affected: 0,
+			wantErr:  storage.ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, m, _ := sqlmock.New()
+			defer db.Close()
+
+			m.ExpectExec("UPDATE api_key SET revoked_at").WithArgs("shop").WillReturnResult(sqlmock.NewResult(0, tt.affected))
+
+			err := NewRepository(db, 0).Revoke(context.Background(), "shop")
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,120 @@
+// Package auth holds the principals calling the API and the credentials they are authenticated by.
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Scope is a permission granted to a principal.
+type Scope string
+
+const (
+	ScopeProductsRead  Scope = "products:read"
+	ScopeProductsWrite Scope = "products:write"
+	ScopeSellersAdmin  Scope = "sellers:admin"
+)
+
+// scopes are the known scopes.
+var scopes = map[Scope]bool{
+	ScopeProductsRead:  true,
+	ScopeProductsWrite: true,
+	ScopeSellersAdmin:  true,
+}
+
+// ParseScopes parses the comma-separated scopes, e.g. products:read,products:write.
+func ParseScopes(s string) ([]Scope, error) {
+	var parsed []Scope
+
+	for _, scope := range strings.Split(s, ",") {
+		scope := Scope(strings.TrimSpace(scope))
+		if scope == "" {
+			continue
+		}
+
+		if !scopes[scope] {
+			return nil, fmt.Errorf("auth: unknown scope %q", scope)
+		}
+
+		parsed = append(parsed, scope)
+	}
+
+	return parsed, nil
+}
+
+// FormatScopes formats the scopes as they are parsed by ParseScopes.
+func FormatScopes(scopes []Scope) string {
+	s := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		s = append(s, string(scope))
+	}
+
+	return strings.Join(s, ",")
+}
+
+// Principal is the authenticated caller of the API.
+type Principal struct {
+	// ID identifies the principal along with the way it is authenticated, e.g. apikey:<id of its
+	// API key> or jwt:<subject>, so that the principals of the API keys and of the tokens sharing
+	// a name are told apart. It is at most maxIDLength long.
+	ID string
+	// Name is the name of the principal, e.g. the name of its API key or the subject of its token.
+	Name   string
+	Scopes []Scope
+	// SellerUUID is the seller the principal acts for, it is empty for the principals of the platform.
+	SellerUUID string
+}
+
+// maxIDLength bounds the IDs of the principals, which are stored by the import jobs.
+const maxIDLength = 100
+
+// principalID returns the ID of the principal of the name authenticated by the way, e.g. jwt. The
+// names which would not fit maxIDLength, e.g. long subjects of the tokens, are replaced by their
+// SHA-256, so that the IDs stay unique.
+func principalID(way, name string) string {
+	if id := way + ":" + name; len(id) <= maxIDLength {
+		return id
+	}
+
+	sum := sha256.Sum256([]byte(name))
+
+	return way + ":sha256:" + hex.EncodeToString(sum[:])
+}
+
+// HasScope reports whether the scope is granted to the principal.
+func (p *Principal) HasScope(scope Scope) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MayModify reports whether the principal may modify the resources of the seller: the principals
+// bound to a seller may modify only the resources of their seller, unless they are admins.
+func (p *Principal) MayModify(sellerUUID string) bool {
+	if p.SellerUUID == "" || p.HasScope(ScopeSellersAdmin) {
+		return true
+	}
+
+	return p.SellerUUID == sellerUUID
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFrom returns the principal of ctx, nil when the request is not authenticated.
+func PrincipalFrom(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+
+	return p
+}
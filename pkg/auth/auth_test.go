@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseScopes(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    []Scope
+		wantErr bool
+	}{
+		{
+			name: "parses the scopes",
+			s:    "products:read, products:write,sellers:admin",
+			want: []Scope{ScopeProductsRead, ScopeProductsWrite, ScopeSellersAdmin},
+		},
+		{
+			name: "parses no scope",
+			s:    "",
+			want: nil,
+		},
+		{
+			name:    "returns error, when a scope is unknown",
+			s:       "products:read,products:admin",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseScopes(tt.s)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPrincipalID(t *testing.T) {
+	assert.Equal(t, "jwt:zalando", principalID("jwt", "zalando"))
+
+	long := principalID("jwt", strings.Repeat("s", maxIDLength))
+	assert.Equal(t, "jwt:sha256:", long[:len("jwt:sha256:")])
+	assert.LessOrEqual(t, len(long), maxIDLength)
+	assert.NotEqual(t, long, principalID("jwt", strings.Repeat("s", maxIDLength+1)))
+}
+
+func TestPrincipal_HasScope(t *testing.T) {
+	p := &Principal{Name: "shop", Scopes: []Scope{ScopeProductsRead}}
+
+	assert.True(t, p.HasScope(ScopeProductsRead))
+	assert.False(t, p.HasScope(ScopeProductsWrite))
+}
+
+func TestPrincipal_MayModify(t *testing.T) {
+	tests := []struct {
+		name      string
+		principal *Principal
+		want      bool
+	}{
+		{
+			name:      "the principal of the platform may modify any seller",
+			principal: &Principal{Name: "shop", Scopes: []Scope{ScopeProductsWrite}},
+			want:      true,
+		},
+		{
+			name:      "the principal of the seller may modify its seller",
+			principal: &Principal{Name: "seller", Scopes: []Scope{ScopeProductsWrite}, SellerUUID: "sellerUUID"},
+			want:      true,
+		},
+		{
+			name:      "the principal of another seller may not modify the seller",
+			principal: &Principal{Name: "other", Scopes: []Scope{ScopeProductsWrite}, SellerUUID: "otherUUID"},
+			want:      false,
+		},
+		{
+			name:      "the admin of another seller may modify the seller",
+			principal: &Principal{Name: "admin", Scopes: []Scope{ScopeSellersAdmin}, SellerUUID: "otherUUID"},
+			want:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.principal.MayModify("sellerUUID"))
+		})
+	}
+}
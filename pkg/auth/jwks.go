@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// minRefreshInterval bounds how often the key set is reloaded, for unknown key IDs or after
+// a failed load, so that tokens of made up key IDs can not hammer the source.
+const minRefreshInterval = 30 * time.Second
+
+// KeySet is the set of the public keys verifying the tokens, loaded from a JWKS file or URL.
+//
+// The keys are cached for the TTL, and they are reloaded earlier for a key ID which is unknown,
+// so that the rotated keys are picked up as soon as the tokens are signed by them. The cached
+// keys are kept when they can not be reloaded.
+//
+// The keys are loaded once at a time outside of the lock, the cached keys are served meanwhile.
+type KeySet struct {
+	source     string
+	ttl        time.Duration
+	httpClient *http.Client
+	now        func() time.Time
+	loads      singleflight.Group
+
+	mu          sync.Mutex
+	keys        map[string]crypto.PublicKey
+	loadedAt    time.Time
+	attemptedAt time.Time
+	loadErr     error
+}
+
+// NewKeySet builds the key set loaded from the source, either a file path or an http(s) URL.
+func NewKeySet(source string, ttl time.Duration) *KeySet {
+	return &KeySet{
+		source:     source,
+		ttl:        ttl,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		now:        time.Now,
+	}
+}
+
+// Key returns the public key of the key ID, it returns ErrInvalidToken when there is no such key.
+func (s *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	s.mu.Lock()
+	key, known := s.keys[kid]
+	loaded, loadErr := s.keys != nil, s.loadErr
+	reload := s.reload(known)
+	s.mu.Unlock()
+
+	switch {
+	case !reload && !loaded && loadErr != nil:
+		return nil, loadErr
+	case reload && known:
+		// NOTE - the expired keys are refreshed in the background, the key is still valid until
+		// it is removed from the source.
+		go func() { _ = s.load(context.WithoutCancel(ctx)) }()
+	case reload || !loaded:
+		// NOTE - the keys are probably rotated, or the first load is in flight. The failure is not
+		// returned when some keys are cached, as the token may be just forged.
+		if err := s.load(ctx); err != nil && !loaded {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		key, known = s.keys[kid]
+		s.mu.Unlock()
+	}
+
+	if !known {
+		return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+	}
+
+	return key, nil
+}
+
+// reload reports whether the keys are to be reloaded, and records the attempt. It must be called
+// with the lock held.
+func (s *KeySet) reload(known bool) bool {
+	now := s.now()
+
+	if !s.attemptedAt.IsZero() && now.Sub(s.attemptedAt) <= minRefreshInterval {
+		return false
+	}
+
+	if s.keys != nil && known && now.Sub(s.loadedAt) <= s.ttl {
+		return false
+	}
+
+	s.attemptedAt = now
+
+	return true
+}
+
+// load loads the keys from the source, the keys are replaced only when they are loaded. The
+// concurrent loads share the same read of the source, which is not canceled by ctx.
+func (s *KeySet) load(ctx context.Context) error {
+	ch := s.loads.DoChan("", func() (interface{}, error) {
+		err := s.fetch(context.WithoutCancel(ctx))
+
+		s.mu.Lock()
+		s.loadErr = err
+		s.mu.Unlock()
+
+		return nil, err
+	})
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case res := <-ch:
+		return res.Err
+	}
+}
+
+func (s *KeySet) fetch(ctx context.Context) error {
+	data, err := s.read(ctx)
+	if err != nil {
+		return fmt.Errorf("auth: failed to load JWKS: %w", err)
+	}
+
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.keys, s.loadedAt = keys, s.now()
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *KeySet) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+		return os.ReadFile(s.source)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s responded %d", s.source, resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// jwk is a JSON Web Key of RFC 7517, only the RSA and P-256 EC signing keys are supported.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS parses the JSON Web Key Set into the public keys by their IDs. The keys which are
+// not for signing, or of other types than RSA and P-256 EC, are skipped.
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	set := &struct {
+		Keys []jwk `json:"keys"`
+	}{}
+
+	if err := json.Unmarshal(data, set); err != nil {
+		return nil, fmt.Errorf("auth: invalid JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var (
+			key crypto.PublicKey
+			err error
+		)
+
+		switch {
+		case k.Kty == "RSA":
+			key, err = k.rsa()
+		case k.Kty == "EC" && k.Crv == "P-256":
+			key, err = k.ecdsa()
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("auth: invalid JWK %q: %w", k.Kid, err)
+		}
+
+		keys[k.Kid] = key
+	}
+
+	return keys, nil
+}
+
+func (k jwk) rsa() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := decodeBigInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.IsInt64() || e.Int64() < 2 || e.Int64() > 1<<31-1 {
+		return nil, fmt.Errorf("invalid exponent")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k jwk) ecdsa() (*ecdsa.PublicKey, error) {
+	x, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, err
+	}
+
+	y, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, err
+	}
+
+	curve := elliptic.P256()
+	if !curve.IsOnCurve(x, y) {
+		return nil, fmt.Errorf("point is not on the curve")
+	}
+
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) == 0 {
+		return nil, fmt.Errorf("missing parameter")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// jwksOf returns the JWKS of the public keys by their IDs.
+func jwksOf(t *testing.T, keys map[string]interface{}) []byte {
+	t.Helper()
+
+	encode := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+
+	set := struct {
+		Keys []map[string]string `json:"keys"`
+	}{}
+
+	for kid, key := range keys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, map[string]string{
+				"kty": "RSA", "kid": kid, "use": "sig", "alg": "RS256",
+				"n": encode(k.N), "e": encode(big.NewInt(int64(k.E))),
+			})
+		case *ecdsa.PublicKey:
+			set.Keys = append(set.Keys, map[string]string{
+				"kty": "EC", "kid": kid, "use": "sig", "alg": "ES256", "crv": "P-256",
+				"x": encode(k.X), "y": encode(k.Y),
+			})
+		}
+	}
+
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestParseJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	keys, err := ParseJWKS(jwksOf(t, map[string]interface{}{"rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey}))
+	require.NoError(t, err)
+	assert.True(t, rsaKey.PublicKey.Equal(keys["rsa"]))
+	assert.True(t, ecKey.PublicKey.Equal(keys["ec"]))
+
+	keys, err = ParseJWKS([]byte(`{"keys":[{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"},{"kty":"oct","kid":"hmac","k":"c2VjcmV0"}]}`))
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	_, err = ParseJWKS([]byte(`{"keys":[{"kty":"EC","kid":"ec","crv":"P-256","x":"AQ","y":"AQ"}]}`))
+	assert.Error(t, err)
+
+	_, err = ParseJWKS([]byte(`not json`))
+	assert.Error(t, err)
+}
+
+func TestKeySet_Key_file(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwksOf(t, map[string]interface{}{"ec": &key.PublicKey}), 0o600))
+
+	s := NewKeySet(path, time.Hour)
+
+	got, err := s.Key(context.Background(), "ec")
+	require.NoError(t, err)
+	assert.True(t, key.PublicKey.Equal(got))
+
+	_, err = s.Key(context.Background(), "unknown")
+	assert.True(t, errors.Is(err, ErrInvalidToken))
+
+	_, err = NewKeySet(filepath.Join(t.TempDir(), "missing.json"), time.Hour).Key(context.Background(), "ec")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrInvalidToken))
+}
+
+func TestKeySet_Key_rotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	var (
+		jwks  atomic.Value
+		loads int32
+	)
+
+	jwks.Store(jwksOf(t, map[string]interface{}{"old": &oldKey.PublicKey}))
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&loads, 1)
+		_, _ = w.Write(jwks.Load().([]byte))
+	}))
+	defer srv.Close()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s := NewKeySet(srv.URL, time.Hour)
+	s.now = func() time.Time { return now }
+
+	_, err = s.Key(context.Background(), "old")
+	require.NoError(t, err)
+
+	_, err = s.Key(context.Background(), "old")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads), "the keys are cached")
+
+	jwks.Store(jwksOf(t, map[string]interface{}{"old": &oldKey.PublicKey, "new": &newKey.PublicKey}))
+
+	_, err = s.Key(context.Background(), "new")
+	assert.True(t, errors.Is(err, ErrInvalidToken), "the keys are not reloaded more often than minRefreshInterval")
+
+	now = now.Add(minRefreshInterval + time.Second)
+
+	got, err := s.Key(context.Background(), "new")
+	require.NoError(t, err)
+	assert.True(t, newKey.PublicKey.Equal(got))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+
+	srv.Close()
+	now = now.Add(2 * time.Hour)
+
+	_, err = s.Key(context.Background(), "old")
+	assert.NoError(t, err, "the cached keys are kept when they can not be reloaded")
+}
+
+func TestKeySet_Key_failedLoad(t *testing.T) {
+	var loads int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&loads, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s := NewKeySet(srv.URL, time.Hour)
+	s.now = func() time.Time { return now }
+
+	_, err := s.Key(context.Background(), "old")
+	assert.Error(t, err)
+
+	_, err = s.Key(context.Background(), "old")
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrInvalidToken))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads), "the failed loads are not retried more often than minRefreshInterval")
+
+	now = now.Add(minRefreshInterval + time.Second)
+
+	_, err = s.Key(context.Background(), "old")
+	assert.Error(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+}
+
+func TestKeySet_Key_servesCachedKeysWhileLoading(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	var (
+		loads   int32
+		release = make(chan struct{})
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&loads, 1) > 1 {
+			<-release
+		}
+
+		_, _ = w.Write(jwksOf(t, map[string]interface{}{"ec": &key.PublicKey}))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s := NewKeySet(srv.URL, time.Hour)
+	s.now = func() time.Time { return now }
+
+	_, err = s.Key(context.Background(), "ec")
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Hour)
+
+	for i := 0; i < 3; i++ {
+		got, err := s.Key(context.Background(), "ec")
+		require.NoError(t, err, "the expired keys are served while they are reloaded")
+		assert.True(t, key.PublicKey.Equal(got))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	now = now.Add(minRefreshInterval + time.Second)
+
+	_, err = s.Key(ctx, "unknown")
+	assert.True(t, errors.Is(err, ErrInvalidToken), "the unknown key waits for the load in flight, until ctx is done")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads), "the loads are shared")
+}
@@ -0,0 +1,273 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// ErrInvalidToken is matched by the errors of the tokens which are not accepted.
+var ErrInvalidToken = errors.New("auth: invalid token")
+
+const (
+	defaultScopeClaim  = "scope"
+	defaultSellerClaim = "seller_uuid"
+)
+
+// KeyFinder finds the public key verifying the tokens signed by the key ID.
+type KeyFinder interface {
+	Key(ctx context.Context, kid string) (crypto.PublicKey, error)
+}
+
+// JWTConfig configures the JWTVerifier.
+type JWTConfig struct {
+	// Issuer is the required iss claim.
+	Issuer string
+	// Audience is the required member of the aud claim.
+	Audience string
+	// ClockSkew is tolerated when checking the exp and nbf claims.
+	ClockSkew time.Duration
+	// ScopeClaim holds the space-separated scopes, or an array of them, scope by default.
+	ScopeClaim string
+	// SellerClaim holds the UUID of the seller the principal acts for, seller_uuid by default.
+	SellerClaim string
+}
+
+// JWTVerifier verifies the JWT bearer tokens signed with RS256 or ES256, and maps their claims
+// to the principal: the subject to its name, the scope claim to its scopes and the seller claim
+// to its seller.
+type JWTVerifier struct {
+	keys KeyFinder
+	cfg  JWTConfig
+	now  func() time.Time
+}
+
+// NewJWTVerifier builds the verifier of the tokens signed by the keys.
+func NewJWTVerifier(keys KeyFinder, cfg JWTConfig) *JWTVerifier {
+	if cfg.ScopeClaim == "" {
+		cfg.ScopeClaim = defaultScopeClaim
+	}
+
+	if cfg.SellerClaim == "" {
+		cfg.SellerClaim = defaultSellerClaim
+	}
+
+	return &JWTVerifier{keys: keys, cfg: cfg, now: time.Now}
+}
+
+// IsJWT reports whether the credential looks like a JWT rather than an API key.
+func IsJWT(credential string) bool {
+	return strings.Count(credential, ".") == 2
+}
+
+// Verify verifies the token and returns its principal, it returns an error matching
+// ErrInvalidToken when the token is not accepted.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	header := &struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+
+	if err := decodeSegment(parts[0], header); err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	key, err := v.keys.Key(ctx, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+
+	if err := verifySignature(header.Alg, key, digest[:], signature); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]json.RawMessage
+
+	if err := decodeSegment(parts[1], &raw); err != nil {
+		return nil, err
+	}
+
+	c := &claims{}
+
+	if err := decodeSegment(parts[1], c); err != nil {
+		return nil, err
+	}
+
+	if err := v.validate(c); err != nil {
+		return nil, err
+	}
+
+	principal := &Principal{ID: principalID("jwt", c.Subject), Name: c.Subject}
+
+	if scopes, ok := raw[v.cfg.ScopeClaim]; ok {
+		if principal.Scopes, err = parseScopeClaim(scopes); err != nil {
+			return nil, err
+		}
+	}
+
+	if seller, ok := raw[v.cfg.SellerClaim]; ok {
+		if err := json.Unmarshal(seller, &principal.SellerUUID); err != nil {
+			return nil, fmt.Errorf("%w: invalid %s claim", ErrInvalidToken, v.cfg.SellerClaim)
+		}
+	}
+
+	return principal, nil
+}
+
+// claims are the registered claims of RFC 7519 which are validated.
+type claims struct {
+	Issuer    string   `json:"iss"`
+	Subject   string   `json:"sub"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *float64 `json:"exp"`
+	NotBefore *float64 `json:"nbf"`
+}
+
+// audience is the aud claim, which is either a single string or an array of them.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(a))
+}
+
+func (v *JWTVerifier) validate(c *claims) error {
+	now := v.now()
+
+	if c.ExpiresAt == nil {
+		return fmt.Errorf("%w: missing exp claim", ErrInvalidToken)
+	}
+
+	if now.After(unixTime(*c.ExpiresAt).Add(v.cfg.ClockSkew)) {
+		return fmt.Errorf("%w: token is expired", ErrInvalidToken)
+	}
+
+	if c.NotBefore != nil && now.Add(v.cfg.ClockSkew).Before(unixTime(*c.NotBefore)) {
+		return fmt.Errorf("%w: token is not valid yet", ErrInvalidToken)
+	}
+
+	if v.cfg.Issuer != "" && c.Issuer != v.cfg.Issuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, c.Issuer)
+	}
+
+	if v.cfg.Audience != "" && !c.Audience.contains(v.cfg.Audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+
+	if c.Subject == "" {
+		return fmt.Errorf("%w: missing sub claim", ErrInvalidToken)
+	}
+
+	return nil
+}
+
+func (a audience) contains(aud string) bool {
+	for _, member := range a {
+		if member == aud {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseScopeClaim parses the space-separated scopes or the array of them. The scopes which are not
+// of this API, e.g. openid, are skipped.
+func parseScopeClaim(data json.RawMessage) ([]Scope, error) {
+	var list []string
+
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		list = strings.Fields(single)
+	} else if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("%w: invalid scope claim", ErrInvalidToken)
+	}
+
+	var parsed []Scope
+
+	for _, s := range list {
+		if scope := Scope(s); scopes[scope] {
+			parsed = append(parsed, scope)
+		}
+	}
+
+	return parsed, nil
+}
+
+// verifySignature verifies the signature of the digest by the key of the algorithm.
+func verifySignature(alg string, key crypto.PublicKey, digest, signature []byte) error {
+	switch alg {
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key is not of %s", ErrInvalidToken, alg)
+		}
+
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, signature) != nil {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: key is not of %s", ErrInvalidToken, alg)
+		}
+
+		// NOTE - the signature is the concatenation of r and s of 32 bytes each, not ASN.1.
+		if len(signature) != 64 {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+		}
+
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(pub, digest, r, s) {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+		}
+	default:
+		// NOTE - none and the HMAC algorithms are never accepted.
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, alg)
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: malformed segment", ErrInvalidToken)
+	}
+
+	return nil
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
@@ -0,0 +1,200 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// keyFinderStub finds the keys by their IDs.
+type keyFinderStub map[string]crypto.PublicKey
+
+func (s keyFinderStub) Key(_ context.Context, kid string) (crypto.PublicKey, error) {
+	key, ok := s[kid]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return key, nil
+}
+
+// signToken signs the claims with the private key of the algorithm.
+func signToken(t *testing.T, alg, kid string, key crypto.Signer, claims map[string]interface{}) string {
+	t.Helper()
+
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var signature []byte
+
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+		require.NoError(t, err)
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, k, digest[:])
+		require.NoError(t, err)
+
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func TestJWTVerifier_Verify(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	claims := func(overrides map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"iss":         "https://sso.gfg.com",
+			"aud":         "product-api",
+			"sub":         "zalando",
+			"exp":         now.Add(time.Hour).Unix(),
+			"scope":       "openid products:read products:write",
+			"seller_uuid": "sellerUUID",
+		}
+
+		for k, v := range overrides {
+			if v == nil {
+				delete(c, k)
+				continue
+			}
+
+			c[k] = v
+		}
+
+		return c
+	}
+
+	seller := &Principal{ID: "jwt:zalando", Name: "zalando", Scopes: []Scope{ScopeProductsRead, ScopeProductsWrite}, SellerUUID: "sellerUUID"}
+
+	tests := []struct {
+		name    string
+		token   string
+		want    *Principal
+		wantErr bool
+	}{
+		{
+			name:  "verifies the token signed with RS256",
+			token: signToken(t, "RS256", "rsa", rsaKey, claims(nil)),
+			want:  seller,
+		},
+		{
+			name:  "verifies the token signed with ES256",
+			token: signToken(t, "ES256", "ec", ecKey, claims(nil)),
+			want:  seller,
+		},
+		{
+			name: "maps the array of scopes and the audiences",
+			token: signToken(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{
+				"scope":       []string{"sellers:admin"},
+				"aud":         []string{"other-api", "product-api"},
+				"seller_uuid": nil,
+			})),
+			want: &Principal{ID: "jwt:zalando", Name: "zalando", Scopes: []Scope{ScopeSellersAdmin}},
+		},
+		{
+			name:  "accepts the expired token within the clock skew",
+			token: signToken(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()})),
+			want:  seller,
+		},
+		{
+			name:    "rejects the expired token",
+			token:   signToken(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()})),
+			wantErr: true,
+		},
+		{
+			name:    "rejects the token without expiration",
+			token:   signToken(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"exp": nil})),
+			wantErr: true,
+		},
+		{
+			name:    "rejects the token which is not valid yet",
+			token:   signToken(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"nbf": now.Add(2 * time.Minute).Unix()})),
+			wantErr: true,
+		},
+		{
+			name:    "rejects the token of another issuer",
+			token:   signToken(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"iss": "https://evil.com"})),
+			wantErr: true,
+		},
+		{
+			name:    "rejects the token of another audience",
+			token:   signToken(t, "RS256", "rsa", rsaKey, claims(map[string]interface{}{"aud": "other-api"})),
+			wantErr: true,
+		},
+		{
+			name:    "rejects the token signed by another key",
+			token:   signToken(t, "RS256", "rsa", otherKey, claims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "rejects the token of an unknown key",
+			token:   signToken(t, "RS256", "unknown", rsaKey, claims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "rejects the token of the algorithm which is not of the key",
+			token:   signToken(t, "ES256", "rsa", ecKey, claims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "rejects the unsigned token",
+			token:   signToken(t, "none", "rsa", rsaKey, claims(nil)),
+			wantErr: true,
+		},
+		{
+			name:    "rejects the malformed token",
+			token:   "not.a.token",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewJWTVerifier(
+				keyFinderStub{"rsa": &rsaKey.PublicKey, "ec": &ecKey.PublicKey},
+				JWTConfig{Issuer: "https://sso.gfg.com", Audience: "product-api", ClockSkew: time.Minute},
+			)
+			v.now = func() time.Time { return now }
+
+			got, err := v.Verify(context.Background(), tt.token)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.wantErr, errors.Is(err, ErrInvalidToken))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestIsJWT(t *testing.T) {
+	assert.True(t, IsJWT("header.payload.signature"))
+	assert.False(t, IsJWT("gfg_0123456789abcdef"))
+}
@@ -0,0 +1,202 @@
+// Package client is a typed client of the Product API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Version is the API version the client talks to.
+type Version string
+
+const (
+	V1 Version = "v1"
+	V2 Version = "v2"
+)
+
+const (
+	defaultRetries = 2
+	defaultBackoff = 100 * time.Millisecond
+)
+
+// Client is a client of the Product API, it is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	version    Version
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+	apiKey     string
+}
+
+// Option configures the Client.
+type Option func(*Client)
+
+// WithVersion sets the API version, V2 by default.
+func WithVersion(version Version) Option {
+	return func(c *Client) {
+		c.version = version
+	}
+}
+
+// WithAPIKey sets the API key the requests are authenticated by.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithHTTPClient sets the HTTP client, http.DefaultClient by default.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times the requests are retried on network errors, 502, 503 and 504
+// responses, waiting the backoff doubled by every retry. 2 times from 100ms by default. POST and
+// PATCH are sent with an Idempotency-Key then, so that their retries are not executed twice.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// New builds the Client of the API served at the base URL, e.g. http://localhost:8080.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("client.New: invalid base URL: %w", err)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		version:    V2,
+		httpClient: http.DefaultClient,
+		retries:    defaultRetries,
+		backoff:    defaultBackoff,
+	}
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.version != V1 && c.version != V2 {
+		return nil, fmt.Errorf("client.New: unsupported version %q", c.version)
+	}
+
+	return c, nil
+}
+
+// do sends the request to the path of the version and decodes the JSON response into out,
+// out may be nil when the response has no body to decode.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body []byte
+
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("client: failed to marshal request: %w", err)
+		}
+	}
+
+	u := *c.baseURL
+	u.Path += "/api/" + string(c.version) + path
+	u.RawQuery = query.Encode()
+
+	resp, err := c.send(ctx, method, u.String(), body)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("client: failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// send sends the request, retrying it.
+func (c *Client) send(ctx context.Context, method, u string, body []byte) (*http.Response, error) {
+	var idempotencyKey string
+	if c.retries > 0 && (method == http.MethodPost || method == http.MethodPatch) {
+		idempotencyKey = uuid.New().String()
+	}
+
+	backoff := c.backoff
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("client: failed to build request: %w", err)
+		}
+
+		req.Header.Set("Accept", "application/json")
+		if c.apiKey != "" {
+			req.Header.Set("X-API-Key", c.apiKey)
+		}
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if idempotencyKey != "" {
+			req.Header.Set("Idempotency-Key", idempotencyKey)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if attempt >= c.retries || !retryable(resp, err) {
+			if err != nil {
+				return nil, fmt.Errorf("client: %s %s: %w", method, u, err)
+			}
+
+			return resp, nil
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// retryable reports whether the request may succeed when it is sent again.
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
@@ -0,0 +1,337 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"coding-challenge-go/pkg/api"
+	"coding-challenge-go/pkg/config"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	productColumns = []string{"id_product", "name", "brand", "stock", "uuid", "uuid"}
+	sellerColumns  = []string{"id_seller", "name", "email", "phone", "uuid"}
+)
+
+// newTestClient builds the Client of the API engine served by httptest on the DB of the expectations.
+func newTestClient(t *testing.T, version Version, expect func(m sqlmock.Sqlmock)) *Client {
+	db, m, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	expect(m)
+
+	engine, _, err := api.CreateAPIEngine(db, config.ENVConfig{LogPIIRedaction: "full"})
+	assert.NoError(t, err)
+
+	srv := httptest.NewServer(engine)
+
+	t.Cleanup(func() {
+		srv.Close()
+		assert.NoError(t, m.ExpectationsWereMet())
+		db.Close()
+	})
+
+	c, err := New(srv.URL, WithVersion(version), WithRetries(0, 0))
+	assert.NoError(t, err)
+
+	return c
+}
+
+func TestClient_Products(t *testing.T) {
+	c := newTestClient(t, V2, func(m sqlmock.Sqlmock) {
+		page := sqlmock.NewRows(productColumns)
+		for i := 0; i < PageSize; i++ {
+			page.AddRow(i, "shoes", "nike", i, "a223850e-d8ab-430a-9a1a-28628cfd52b0", fmt.Sprintf("uuid-%d", i))
+		}
+
+		m.ExpectQuery("SELECT").WithArgs(PageSize, 0).WillReturnRows(page)
+		m.ExpectQuery("SELECT").WithArgs(PageSize, PageSize).WillReturnRows(sqlmock.NewRows(productColumns).
+			AddRow(10, "socks", "adidas", 15, "a223850e-d8ab-430a-9a1a-28628cfd52b0", "uuid-10"))
+	})
+
+	var products []*Product
+
+	it := c.Products()
+	for it.Next(context.Background()) {
+		products = append(products, it.Product())
+	}
+
+	assert.NoError(t, it.Err())
+	assert.Len(t, products, PageSize+1)
+	assert.Equal(t, &Product{
+		UUID:       "uuid-10",
+		Name:       "socks",
+		Brand:      "adidas",
+		Stock:      15,
+		SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+	}, products[PageSize])
+}
+
+func TestClient_GetProduct(t *testing.T) {
+	tests := []struct {
+		name    string
+		version Version
+		rows    *sqlmock.Rows
+		want    *Product
+		wantErr error
+	}{
+		{
+			name:    "v1: returns the Product",
+			version: V1,
+			rows: sqlmock.NewRows(productColumns).
+				AddRow(1, "shoes", "nike", 10, "a223850e-d8ab-430a-9a1a-28628cfd52b0", "61981e52-e1ca-449e-b79f-01d5906b3435"),
+			want: &Product{
+				UUID:       "61981e52-e1ca-449e-b79f-01d5906b3435",
+				Name:       "shoes",
+				Brand:      "nike",
+				Stock:      10,
+				SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+			},
+		},
+		{
+			name:    "v2: returns the Product",
+			version: V2,
+			rows: sqlmock.NewRows(productColumns).
+				AddRow(1, "shoes", "nike", 10, "a223850e-d8ab-430a-9a1a-28628cfd52b0", "61981e52-e1ca-449e-b79f-01d5906b3435"),
+			want: &Product{
+				UUID:       "61981e52-e1ca-449e-b79f-01d5906b3435",
+				Name:       "shoes",
+				Brand:      "nike",
+				Stock:      10,
+				SellerUUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+			},
+		},
+		{
+			name:    "v1: returns not found, when the Product does not exist",
+			version: V1,
+			rows:    sqlmock.NewRows(productColumns),
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "v2: returns not found, when the Product does not exist",
+			version: V2,
+			rows:    sqlmock.NewRows(productColumns),
+			wantErr: ErrNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, tt.version, func(m sqlmock.Sqlmock) {
+				m.ExpectQuery("SELECT").WithArgs("61981e52-e1ca-449e-b79f-01d5906b3435").WillReturnRows(tt.rows)
+			})
+
+			got, err := c.GetProduct(context.Background(), "61981e52-e1ca-449e-b79f-01d5906b3435")
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestClient_CreateProduct(t *testing.T) {
+	t.Run("creates the Product", func(t *testing.T) {
+		c := newTestClient(t, V2, func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("SELECT").WithArgs("a223850e-d8ab-430a-9a1a-28628cfd52b0").WillReturnRows(sqlmock.NewRows(sellerColumns).
+				AddRow(1, "david", "d@example.com", "324-3243-32", "a223850e-d8ab-430a-9a1a-28628cfd52b0"))
+			m.ExpectExec("INSERT").WillReturnResult(sqlmock.NewResult(1, 1))
+			m.ExpectCommit()
+		})
+
+		got, err := c.CreateProduct(context.Background(), ProductCreate{
+			Name:   "shoes",
+			Brand:  "nike",
+			Stock:  10,
+			Seller: "a223850e-d8ab-430a-9a1a-28628cfd52b0",
+		})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, got.UUID)
+		assert.Equal(t, "a223850e-d8ab-430a-9a1a-28628cfd52b0", got.SellerUUID)
+	})
+
+	t.Run("returns the API error, when the Seller does not exist", func(t *testing.T) {
+		c := newTestClient(t, V2, func(m sqlmock.Sqlmock) {
+			m.ExpectBegin()
+			m.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(sellerColumns))
+			m.ExpectCommit()
+		})
+
+		_, err := c.CreateProduct(context.Background(), ProductCreate{Seller: "a223850e-d8ab-430a-9a1a-28628cfd52b0"})
+		assert.ErrorIs(t, err, ErrInvalidRequest)
+
+		var apiErr *Error
+		assert.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusUnprocessableEntity, apiErr.Status)
+		assert.Equal(t, "seller_not_found", apiErr.Code)
+		assert.Equal(t, "Seller is not found", apiErr.Message)
+		assert.NotEmpty(t, apiErr.RequestID)
+	})
+}
+
+func TestClient_UpdateProduct(t *testing.T) {
+	c := newTestClient(t, V1, func(m sqlmock.Sqlmock) {
+		m.ExpectQuery("SELECT").WithArgs("61981e52-e1ca-449e-b79f-01d5906b3435").WillReturnRows(sqlmock.NewRows(productColumns).
+			AddRow(1, "shoes", "nike", 10, "a223850e-d8ab-430a-9a1a-28628cfd52b0", "61981e52-e1ca-449e-b79f-01d5906b3435"))
+		m.ExpectExec("UPDATE").
+			WithArgs("boots", "nike", 10, "61981e52-e1ca-449e-b79f-01d5906b3435").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+	})
+
+	got, err := c.UpdateProduct(context.Background(), "61981e52-e1ca-449e-b79f-01d5906b3435", ProductUpdate{
+		Name:  "boots",
+		Brand: "nike",
+		Stock: 10,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "boots", got.Name)
+}
+
+func TestClient_AdjustStock(t *testing.T) {
+	t.Run("adjusts the stock", func(t *testing.T) {
+		c := newTestClient(t, V2, func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("SELECT").WithArgs("61981e52-e1ca-449e-b79f-01d5906b3435").WillReturnRows(sqlmock.NewRows(productColumns).
+				AddRow(1, "shoes", "nike", 10, "a223850e-d8ab-430a-9a1a-28628cfd52b0", "61981e52-e1ca-449e-b79f-01d5906b3435"))
+			m.ExpectExec("UPDATE product SET stock").
+				WithArgs(-4, "61981e52-e1ca-449e-b79f-01d5906b3435", -4).
+				WillReturnResult(sqlmock.NewResult(6, 1))
+			m.ExpectQuery("SELECT").WithArgs("a223850e-d8ab-430a-9a1a-28628cfd52b0").WillReturnRows(sqlmock.NewRows(sellerColumns).
+				AddRow(1, "david", "d@example.com", "324-3243-32", "a223850e-d8ab-430a-9a1a-28628cfd52b0"))
+		})
+
+		got, err := c.AdjustStock(context.Background(), "61981e52-e1ca-449e-b79f-01d5906b3435", -4)
+		assert.NoError(t, err)
+		assert.Equal(t, 6, got.Stock)
+	})
+
+	t.Run("returns conflict, when the stock is insufficient", func(t *testing.T) {
+		c := newTestClient(t, V2, func(m sqlmock.Sqlmock) {
+			rows := func() *sqlmock.Rows {
+				return sqlmock.NewRows(productColumns).
+					AddRow(1, "shoes", "nike", 10, "a223850e-d8ab-430a-9a1a-28628cfd52b0", "61981e52-e1ca-449e-b79f-01d5906b3435")
+			}
+			m.ExpectQuery("SELECT").WillReturnRows(rows())
+			m.ExpectExec("UPDATE product SET stock").WillReturnResult(sqlmock.NewResult(0, 0))
+			m.ExpectQuery("SELECT").WillReturnRows(rows())
+		})
+
+		_, err := c.AdjustStock(context.Background(), "61981e52-e1ca-449e-b79f-01d5906b3435", -20)
+		assert.ErrorIs(t, err, ErrConflict)
+	})
+}
+
+func TestClient_DeleteProduct(t *testing.T) {
+	t.Run("deletes the Product", func(t *testing.T) {
+		c := newTestClient(t, V2, func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(productColumns).
+				AddRow(1, "shoes", "nike", 10, "a223850e-d8ab-430a-9a1a-28628cfd52b0", "61981e52-e1ca-449e-b79f-01d5906b3435"))
+			m.ExpectExec("DELETE").WithArgs("61981e52-e1ca-449e-b79f-01d5906b3435").WillReturnResult(sqlmock.NewResult(0, 1))
+		})
+
+		assert.NoError(t, c.DeleteProduct(context.Background(), "61981e52-e1ca-449e-b79f-01d5906b3435"))
+	})
+
+	t.Run("returns conflict, when the Product is referenced", func(t *testing.T) {
+		c := newTestClient(t, V2, func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("SELECT").WillReturnRows(sqlmock.NewRows(productColumns).
+				AddRow(1, "shoes", "nike", 10, "a223850e-d8ab-430a-9a1a-28628cfd52b0", "61981e52-e1ca-449e-b79f-01d5906b3435"))
+			m.ExpectExec("DELETE").WillReturnError(&mysql.MySQLError{Number: 1451, Message: "Cannot delete or update a parent row"})
+		})
+
+		err := c.DeleteProduct(context.Background(), "61981e52-e1ca-449e-b79f-01d5906b3435")
+		assert.ErrorIs(t, err, ErrConflict)
+	})
+}
+
+func TestClient_sellers(t *testing.T) {
+	rows := func() *sqlmock.Rows {
+		return sqlmock.NewRows(sellerColumns).
+			AddRow(1, "david", "d@example.com", "324-3243-32", "a223850e-d8ab-430a-9a1a-28628cfd52b0")
+	}
+	want := []*Seller{{UUID: "a223850e-d8ab-430a-9a1a-28628cfd52b0", Name: "david", Email: "d@example.com", Phone: "324-3243-32"}}
+
+	t.Run("v1: lists the Sellers", func(t *testing.T) {
+		c := newTestClient(t, V1, func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("SELECT").WillReturnRows(rows())
+		})
+
+		got, err := c.ListSellers(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+
+	t.Run("v2: lists the top Sellers", func(t *testing.T) {
+		c := newTestClient(t, V2, func(m sqlmock.Sqlmock) {
+			m.ExpectQuery("SELECT").WithArgs(10).WillReturnRows(rows())
+		})
+
+		got, err := c.TopSellers(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, want, got)
+	})
+}
+
+func TestClient_retries(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        func(c *Client) error
+		expCalls      int
+		expErrIs      error
+		failureCount  int
+		expIdempotent bool
+	}{
+		{
+			name:         "retries the idempotent request until it succeeds",
+			method:       func(c *Client) error { _, err := c.ListProducts(context.Background(), 1); return err },
+			failureCount: 2,
+			expCalls:     3,
+		},
+		{
+			name:         "gives up after the retries",
+			method:       func(c *Client) error { _, err := c.ListProducts(context.Background(), 1); return err },
+			failureCount: 5,
+			expCalls:     3,
+			expErrIs:     ErrUnavailable,
+		},
+		{
+			name:          "retries the creation with the same Idempotency-Key",
+			method:        func(c *Client) error { _, err := c.CreateProduct(context.Background(), ProductCreate{}); return err },
+			failureCount:  5,
+			expCalls:      3,
+			expErrIs:      ErrUnavailable,
+			expIdempotent: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			keys := map[string]bool{}
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				keys[r.Header.Get("Idempotency-Key")] = true
+				if calls <= tt.failureCount {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+
+				_, _ = w.Write([]byte(`[]`))
+			}))
+			defer srv.Close()
+
+			c, err := New(srv.URL, WithRetries(2, time.Millisecond))
+			assert.NoError(t, err)
+
+			assert.ErrorIs(t, tt.method(c), tt.expErrIs)
+			assert.Equal(t, tt.expCalls, calls)
+			assert.Len(t, keys, 1)
+			assert.Equal(t, tt.expIdempotent, !keys[""])
+		})
+	}
+}
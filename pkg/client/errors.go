@@ -0,0 +1,106 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	// ErrNotFound is matched by the errors of missing resources.
+	ErrNotFound = errors.New("client: not found")
+	// ErrConflict is matched by the errors of requests in conflict with the current state of the resource.
+	ErrConflict = errors.New("client: conflict")
+	// ErrInvalidRequest is matched by the errors of invalid requests.
+	ErrInvalidRequest = errors.New("client: invalid request")
+	// ErrUnauthorized is matched by the errors of requests without a valid API key or its scope.
+	ErrUnauthorized = errors.New("client: unauthorized")
+	// ErrUnavailable is matched by the errors of the API which is temporarily unavailable.
+	ErrUnavailable = errors.New("client: unavailable")
+)
+
+// Error is an error response of the API, it is matched by the sentinel errors of its status
+// with errors.Is, e.g. errors.Is(err, client.ErrNotFound).
+type Error struct {
+	// Status is the HTTP status code of the response.
+	Status int
+	// Code is the stable code of the error, v1 does not respond it.
+	Code string
+	// Message is the human-readable message of the error.
+	Message string
+	// RequestID is the ID of the request, it is useful when reporting the error.
+	RequestID string
+	// Fields are the validation errors per field of the request, v1 does not respond them.
+	Fields []FieldError
+}
+
+// FieldError is the validation error of a single field of the request.
+type FieldError struct {
+	Field  string `json:"field"`
+	Rule   string `json:"rule"`
+	Detail string `json:"detail"`
+}
+
+func (e *Error) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("client: %d %s: %s", e.Status, e.Code, e.Message)
+	}
+
+	return fmt.Sprintf("client: %d: %s", e.Status, e.Message)
+}
+
+// Is reports whether the error matches the sentinel error of its status.
+func (e *Error) Is(target error) bool {
+	switch e.Status {
+	case http.StatusNotFound:
+		return target == ErrNotFound
+	case http.StatusConflict:
+		return target == ErrConflict
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return target == ErrUnauthorized
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return target == ErrInvalidRequest
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return target == ErrUnavailable
+	}
+
+	return false
+}
+
+// decodeError decodes the error response, either {"error": "message"} of v1 or the problem details.
+func decodeError(resp *http.Response) error {
+	body := &struct {
+		Error     string       `json:"error"`
+		Detail    string       `json:"detail"`
+		Code      string       `json:"code"`
+		RequestID string       `json:"request_id"`
+		Errors    []FieldError `json:"errors"`
+	}{}
+
+	data, _ := io.ReadAll(resp.Body)
+	_ = json.Unmarshal(data, body)
+
+	e := &Error{
+		Status:    resp.StatusCode,
+		Code:      body.Code,
+		Message:   body.Detail,
+		RequestID: body.RequestID,
+		Fields:    body.Errors,
+	}
+
+	if e.Message == "" {
+		e.Message = body.Error
+	}
+
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+
+	if e.RequestID == "" {
+		e.RequestID = resp.Header.Get("X-Request-ID")
+	}
+
+	return e
+}
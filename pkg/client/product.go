@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// PageSize is the count of products in a page of the product lists.
+const PageSize = 10
+
+// Product is a product, the same for all the versions.
+type Product struct {
+	UUID       string
+	Name       string
+	Brand      string
+	Stock      int
+	SellerUUID string
+}
+
+// ProductCreate is the request of creating a product.
+type ProductCreate struct {
+	Name   string `json:"name"`
+	Brand  string `json:"brand"`
+	Stock  int    `json:"stock"`
+	Seller string `json:"seller"`
+}
+
+// ProductUpdate is the request of updating a product.
+type ProductUpdate struct {
+	Name  string `json:"name"`
+	Brand string `json:"brand"`
+	Stock int    `json:"stock"`
+}
+
+// productJSON is the representation of the product by v1 and v2.
+type productJSON struct {
+	UUID  string `json:"uuid"`
+	Name  string `json:"name"`
+	Brand string `json:"brand"`
+	Stock int    `json:"stock"`
+	// SellerUUID is represented by v1.
+	SellerUUID string `json:"seller_uuid"`
+	// Seller is represented by v2.
+	Seller *struct {
+		UUID string `json:"uuid"`
+	} `json:"seller"`
+}
+
+func (p *productJSON) product() *Product {
+	product := &Product{
+		UUID:       p.UUID,
+		Name:       p.Name,
+		Brand:      p.Brand,
+		Stock:      p.Stock,
+		SellerUUID: p.SellerUUID,
+	}
+
+	if p.Seller != nil {
+		product.SellerUUID = p.Seller.UUID
+	}
+
+	return product
+}
+
+// ListProducts returns the page of products, the first page is 1.
+func (c *Client) ListProducts(ctx context.Context, page int) ([]*Product, error) {
+	var body []*productJSON
+
+	if err := c.do(ctx, http.MethodGet, "/products", url.Values{"page": {strconv.Itoa(page)}}, nil, &body); err != nil {
+		return nil, err
+	}
+
+	products := make([]*Product, 0, len(body))
+	for _, p := range body {
+		products = append(products, p.product())
+	}
+
+	return products, nil
+}
+
+// Products returns the iterator of all the products, page by page.
+func (c *Client) Products() *ProductIterator {
+	return &ProductIterator{client: c}
+}
+
+// GetProduct returns the product, the error matches ErrNotFound when it does not exist.
+func (c *Client) GetProduct(ctx context.Context, uuid string) (*Product, error) {
+	var body *productJSON
+
+	if err := c.do(ctx, http.MethodGet, "/product", url.Values{"id": {uuid}}, nil, &body); err != nil {
+		return nil, err
+	}
+
+	// v1 responds null for the missing product.
+	if body == nil {
+		return nil, ErrNotFound
+	}
+
+	return body.product(), nil
+}
+
+// CreateProduct creates the product and returns it.
+func (c *Client) CreateProduct(ctx context.Context, create ProductCreate) (*Product, error) {
+	body := &productJSON{}
+
+	if err := c.do(ctx, http.MethodPost, "/product", nil, create, body); err != nil {
+		return nil, err
+	}
+
+	return body.product(), nil
+}
+
+// UpdateProduct updates the product and returns it.
+func (c *Client) UpdateProduct(ctx context.Context, uuid string, update ProductUpdate) (*Product, error) {
+	body := &productJSON{}
+
+	if err := c.do(ctx, http.MethodPut, "/product", url.Values{"id": {uuid}}, update, body); err != nil {
+		return nil, err
+	}
+
+	return body.product(), nil
+}
+
+// AdjustStock adjusts the stock of the product by the delta and returns the product, it returns
+// ErrConflict when the stock is insufficient. V1 does not support it.
+func (c *Client) AdjustStock(ctx context.Context, uuid string, delta int) (*Product, error) {
+	body := &productJSON{}
+	request := &struct {
+		Delta int `json:"delta"`
+	}{Delta: delta}
+
+	if err := c.do(ctx, http.MethodPatch, "/product/stock", url.Values{"id": {uuid}}, request, body); err != nil {
+		return nil, err
+	}
+
+	return body.product(), nil
+}
+
+// DeleteProduct deletes the product.
+func (c *Client) DeleteProduct(ctx context.Context, uuid string) error {
+	return c.do(ctx, http.MethodDelete, "/product", url.Values{"id": {uuid}}, nil, nil)
+}
+
+// ProductIterator iterates all the products page by page:
+//
+//	it := c.Products()
+//	for it.Next(ctx) {
+//		p := it.Product()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type ProductIterator struct {
+	client   *Client
+	page     int
+	products []*Product
+	current  *Product
+	done     bool
+	err      error
+}
+
+// Next advances to the next product, it queries the next page when the current one is consumed.
+// It returns false when there are no more products or an error occurred.
+func (it *ProductIterator) Next(ctx context.Context) bool {
+	if it.err != nil {
+		return false
+	}
+
+	if len(it.products) == 0 {
+		if it.done {
+			return false
+		}
+
+		it.page++
+
+		it.products, it.err = it.client.ListProducts(ctx, it.page)
+		if it.err != nil {
+			return false
+		}
+
+		// a partial page is the last one.
+		it.done = len(it.products) < PageSize
+
+		if len(it.products) == 0 {
+			return false
+		}
+	}
+
+	it.current, it.products = it.products[0], it.products[1:]
+
+	return true
+}
+
+// Product returns the current product.
+func (it *ProductIterator) Product() *Product {
+	return it.current
+}
+
+// Err returns the error which stopped the iteration.
+func (it *ProductIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,36 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Seller is a seller of products.
+type Seller struct {
+	UUID  string `json:"uuid"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+// ListSellers returns all the sellers, it is served by v1 only.
+func (c *Client) ListSellers(ctx context.Context) ([]*Seller, error) {
+	var sellers []*Seller
+
+	if err := c.do(ctx, http.MethodGet, "/sellers", nil, nil, &sellers); err != nil {
+		return nil, err
+	}
+
+	return sellers, nil
+}
+
+// TopSellers returns the 10 sellers with the most products, it is served by v2 only.
+func (c *Client) TopSellers(ctx context.Context) ([]*Seller, error) {
+	var sellers []*Seller
+
+	if err := c.do(ctx, http.MethodGet, "/sellers/top10", nil, nil, &sellers); err != nil {
+		return nil, err
+	}
+
+	return sellers, nil
+}
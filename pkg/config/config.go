@@ -1,7 +1,99 @@
 package config
 
+import "time"
+
+// The storage backends of DB_BACKEND.
+const (
+	DBBackendMySQL    = "mysql"
+	DBBackendPostgres = "postgres"
+	DBBackendSQLite   = "sqlite"
+	DBBackendMemory   = "memory"
+)
+
 // ENVConfig is an ENV configuration.
 type ENVConfig struct {
 	NotifyEmail bool `envconfig:"NOTIFY_SMS"`
 	NotifySMS   bool `envconfig:"NOTIFY_EMAIL"`
+
+	// DBBackend is the storage of the products and the sellers: the SQL DBs mysql, postgres or sqlite, or
+	// memory for the local development without a DB, whose data is lost on exit.
+	DBBackend string `envconfig:"DB_BACKEND" default:"mysql"`
+	// DBMemorySeed inserts the sample sellers and products into the memory backend on startup.
+	DBMemorySeed bool `envconfig:"DB_MEMORY_SEED"`
+	// DBDSN is the data source name of the SQL DB of DBBackend, e.g. postgres://user:password@db:5432/product
+	// of PostgreSQL or file:product.db of SQLite.
+	DBDSN string `envconfig:"DB_DSN" default:"user:password@tcp(db:3306)/product?clientFoundRows=true&parseTime=true"`
+	// DBQueryTimeout bounds every DB query, 0 means no timeout.
+	DBQueryTimeout time.Duration `envconfig:"DB_QUERY_TIMEOUT" default:"5s"`
+	// DBTxMaxRetries is the number of the retries of a transaction which deadlocks, 0 means no retry.
+	DBTxMaxRetries int `envconfig:"DB_TX_MAX_RETRIES" default:"3"`
+	// DBAutoMigrate applies the pending migrations of the schema on startup, they are applied by the
+	// migrate command otherwise.
+	DBAutoMigrate bool `envconfig:"DB_AUTO_MIGRATE"`
+
+	// AuthEnabled requires an API key of the scope of the route on every request, except the specifications.
+	AuthEnabled bool `envconfig:"AUTH_ENABLED" default:"true"`
+
+	// JWTJWKS is the file or URL of the JWKS verifying the JWT bearer tokens of the SSO,
+	// only the API keys are accepted when it is empty.
+	JWTJWKS string `envconfig:"JWT_JWKS"`
+	// JWTJWKSRefresh is how long the JWKS is cached, it is reloaded earlier for unknown key IDs.
+	JWTJWKSRefresh time.Duration `envconfig:"JWT_JWKS_REFRESH" default:"1h"`
+	// JWTIssuer and JWTAudience are the required iss and aud claims of the tokens.
+	JWTIssuer   string `envconfig:"JWT_ISSUER"`
+	JWTAudience string `envconfig:"JWT_AUDIENCE"`
+	// JWTClockSkew is tolerated when checking the expiration of the tokens.
+	JWTClockSkew time.Duration `envconfig:"JWT_CLOCK_SKEW" default:"1m"`
+	// JWTScopeClaim and JWTSellerClaim are the claims mapped to the scopes and the seller of the principal.
+	JWTScopeClaim  string `envconfig:"JWT_SCOPE_CLAIM" default:"scope"`
+	JWTSellerClaim string `envconfig:"JWT_SELLER_CLAIM" default:"seller_uuid"`
+
+	// TrustedProxies are the IPs or the CIDRs of the proxies whose X-Forwarded-For identifies the
+	// clients, e.g. of the load balancer. The forwarded headers of the other clients are ignored.
+	TrustedProxies []string `envconfig:"TRUSTED_PROXIES"`
+
+	// RateLimitEnabled limits the requests of every client, identified by its principal or IP.
+	RateLimitEnabled bool `envconfig:"RATE_LIMIT_ENABLED" default:"true"`
+	// RateLimitRead and RateLimitWrite are the limits of the reads and the writes, e.g. 600/1m.
+	RateLimitRead  string `envconfig:"RATE_LIMIT_READ" default:"600/1m"`
+	RateLimitWrite string `envconfig:"RATE_LIMIT_WRITE" default:"60/1m"`
+	// RateLimitIP limits all the requests of every client IP ahead of the authentication, so that the
+	// requests failing it are limited too.
+	RateLimitIP string `envconfig:"RATE_LIMIT_IP" default:"1200/1m"`
+	// RateLimitReadQuotas and RateLimitWriteQuotas override the limits per principal, named by the way
+	// it is authenticated and the name of its API key or the subject of its token, e.g.
+	// apikey/shop:6000/1m,jwt/feed:1200/1m.
+	RateLimitReadQuotas  map[string]string `envconfig:"RATE_LIMIT_READ_QUOTAS"`
+	RateLimitWriteQuotas map[string]string `envconfig:"RATE_LIMIT_WRITE_QUOTAS"`
+
+	// IdempotencyTTL is how long the responses of the requests are replayed to the retries
+	// sending the same Idempotency-Key.
+	IdempotencyTTL time.Duration `envconfig:"IDEMPOTENCY_TTL" default:"24h"`
+
+	// ImportMaxRows is the maximum number of the rows of an imported file.
+	ImportMaxRows int `envconfig:"IMPORT_MAX_ROWS" default:"50000"`
+
+	// ShutdownTimeout bounds how long the server waits for the requests in flight and the imports
+	// running in the background, when it is stopped by SIGINT or SIGTERM.
+	ShutdownTimeout time.Duration `envconfig:"SHUTDOWN_TIMEOUT" default:"30s"`
+
+	// APIDeprecated are the dates since when the API versions are deprecated, e.g. v1:2026-01-01.
+	APIDeprecated map[string]string `envconfig:"API_DEPRECATED"`
+	// APISunset are the dates after which the API versions may not be served, e.g. v1:2026-07-01.
+	APISunset map[string]string `envconfig:"API_SUNSET"`
+	// APIMigrationDocsURL is the documentation of migrating from a deprecated version, {version} is replaced.
+	APIMigrationDocsURL string `envconfig:"API_MIGRATION_DOCS_URL"`
+	// APIRejectAfterSunset makes the versions responded with 410 after their sunset.
+	APIRejectAfterSunset bool `envconfig:"API_REJECT_AFTER_SUNSET"`
+
+	// TracingExporter is the exporter of the spans: none, otlp or stdout.
+	TracingExporter     string `envconfig:"TRACING_EXPORTER" default:"none"`
+	TracingServiceName  string `envconfig:"TRACING_SERVICE_NAME" default:"product-api"`
+	TracingOTLPEndpoint string `envconfig:"TRACING_OTLP_ENDPOINT"`
+
+	// LogPIIRedaction is the way emails and phones are masked in logs: full, partial or hashed.
+	LogPIIRedaction string `envconfig:"LOG_PII_REDACTION" default:"full"`
+	LogPIIHashKey   string `envconfig:"LOG_PII_HASH_KEY"`
+	// LogPIIDebug disables the masking of emails and phones, never enable it on production.
+	LogPIIDebug bool `envconfig:"LOG_PII_DEBUG"`
 }
@@ -0,0 +1,169 @@
+// Package idempotency keeps the responses of the requests by their idempotency keys, so that
+// the retried requests are responded the same without being executed again.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"coding-challenge-go/pkg/storage"
+	"coding-challenge-go/pkg/tracing"
+)
+
+// Record is the request of an idempotency key which is already reserved.
+type Record struct {
+	// Fingerprint identifies the request, the key may not be reused by another request.
+	Fingerprint string
+	// Response is the stored response, it is nil while the request is in flight.
+	Response *Response
+}
+
+// Response is the stored response of a request.
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// Repository is the DB repository of the idempotency keys, the keys are stored hashed.
+type Repository struct {
+	db           storage.DBTX
+	dialect      *storage.Dialect
+	queryTimeout time.Duration
+	now          func() time.Time
+}
+
+// NewRepository builds the idempotency key repository.
+func NewRepository(db *sql.DB, queryTimeout time.Duration) *Repository {
+	dialect := storage.DialectOf(db)
+
+	return &Repository{db: dialect.Bind(db), dialect: dialect, queryTimeout: queryTimeout, now: time.Now}
+}
+
+// Reserve reserves the key for the request of the fingerprint until the TTL expires. It returns nil
+// when the key is reserved, and the record of the key when it is already reserved by a request.
+//
+// NOTE - the expired keys are purged on every reservation, so that they can be reused and the
+// table does not grow.
+func (r *Repository) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (_ *Record, err error) {
+	ctx, span := tracing.Start(ctx, "idempotency.Repository.Reserve", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	now := r.now()
+
+	if _, err = r.db.ExecContext(ctx, "DELETE FROM idempotency_key WHERE expires_at < ?", now); err != nil {
+		return nil, err
+	}
+
+	_, err = r.db.ExecContext(
+		ctx,
+		"INSERT INTO idempotency_key (key_hash, fingerprint, expires_at) VALUES(?,?,?)",
+		hashKey(key), fingerprint, now.Add(ttl),
+	)
+
+	if err = storage.FromDB(err); !errors.Is(err, storage.ErrConflict) {
+		return nil, err
+	}
+
+	return r.find(ctx, key)
+}
+
+func (r *Repository) find(ctx context.Context, key string) (*Record, error) {
+	rows, err := r.db.QueryContext(
+		ctx,
+		"SELECT fingerprint, status, headers, body FROM idempotency_key WHERE key_hash = ?",
+		hashKey(key),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		// NOTE - the key is released in the meantime, it can not be reserved again by this request
+		// as another one may be reserving it now.
+		return nil, fmt.Errorf("%w: idempotency key is released", storage.ErrConflict)
+	}
+
+	var (
+		record  = &Record{}
+		status  sql.NullInt64
+		headers []byte
+		body    []byte
+	)
+
+	if err := rows.Scan(&record.Fingerprint, &status, &headers, &body); err != nil {
+		return nil, err
+	}
+
+	if status.Valid {
+		record.Response = &Response{Status: int(status.Int64), Body: body}
+
+		if err := json.Unmarshal(headers, &record.Response.Header); err != nil {
+			return nil, fmt.Errorf("idempotency.Repository.Reserve: invalid headers: %w", err)
+		}
+	}
+
+	return record, nil
+}
+
+// Complete stores the response of the reserved key.
+func (r *Repository) Complete(ctx context.Context, key string, response *Response) (err error) {
+	ctx, span := tracing.Start(ctx, "idempotency.Repository.Complete", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	headers, err := json.Marshal(response.Header)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(
+		ctx,
+		"UPDATE idempotency_key SET status = ?, headers = ?, body = ? WHERE key_hash = ?",
+		response.Status, headers, response.Body, hashKey(key),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return storage.ExpectAffected(result)
+}
+
+// Release releases the key whose request is not completed, so that it can be retried.
+func (r *Repository) Release(ctx context.Context, key string) (err error) {
+	ctx, span := tracing.Start(ctx, "idempotency.Repository.Release", r.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	ctx, cancel := storage.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	_, err = r.db.ExecContext(ctx, "DELETE FROM idempotency_key WHERE key_hash = ? AND status IS NULL", hashKey(key))
+
+	return err
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(sum[:])
+}
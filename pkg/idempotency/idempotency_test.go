@@ -0,0 +1,126 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"coding-challenge-go/pkg/storage"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+var errAnySQL = errors.New("any sql error")
+
+func TestRepository_Reserve(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	dupEntry := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry '...' for key 'key_hash'"}
+
+	tests := []struct {
+		name    string
+		expect  func(m sqlmock.Sqlmock)
+		want    *Record
+		wantErr error
+	}{
+		{
+			name: "reserves the key",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("INSERT INTO idempotency_key").
+					WithArgs(hashKey("key"), "fingerprint", now.Add(time.Hour)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+			},
+		},
+		{
+			name: "returns the completed request of the key",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("INSERT INTO idempotency_key").WillReturnError(dupEntry)
+				m.ExpectQuery("SELECT fingerprint, status, headers, body FROM idempotency_key WHERE key_hash = \\?").
+					WithArgs(hashKey("key")).
+					WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status", "headers", "body"}).
+						AddRow("fingerprint", 201, `{"Location":["/api/v3/products/uuid"]}`, `{"uuid":"uuid"}`))
+			},
+			want: &Record{Fingerprint: "fingerprint", Response: &Response{
+				Status: http.StatusCreated,
+				Header: http.Header{"Location": {"/api/v3/products/uuid"}},
+				Body:   []byte(`{"uuid":"uuid"}`),
+			}},
+		},
+		{
+			name: "returns the request of the key in flight",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("INSERT INTO idempotency_key").WillReturnError(dupEntry)
+				m.ExpectQuery("SELECT fingerprint").
+					WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status", "headers", "body"}).
+						AddRow("fingerprint", nil, nil, nil))
+			},
+			want: &Record{Fingerprint: "fingerprint"},
+		},
+		{
+			name: "returns conflict, when the key is released meanwhile",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("INSERT INTO idempotency_key").WillReturnError(dupEntry)
+				m.ExpectQuery("SELECT fingerprint").
+					WillReturnRows(sqlmock.NewRows([]string{"fingerprint", "status", "headers", "body"}))
+			},
+			wantErr: storage.ErrConflict,
+		},
+		{
+			name: "returns error",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("INSERT INTO idempotency_key").WillReturnError(errAnySQL)
+			},
+			wantErr: errAnySQL,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, m, _ := sqlmock.New()
+			defer db.Close()
+
+			m.ExpectExec("DELETE FROM idempotency_key WHERE expires_at < \\?").WithArgs(now).
+				WillReturnResult(sqlmock.NewResult(0, 0))
+			tt.expect(m)
+
+			r := NewRepository(db, 0)
+			r.now = func() time.Time { return now }
+
+			got, err := r.Reserve(context.Background(), "key", "fingerprint", time.Hour)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, tt.want, got)
+			assert.NoError(t, m.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepository_Complete(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	m.ExpectExec("UPDATE idempotency_key SET status = \\?, headers = \\?, body = \\? WHERE key_hash = \\?").
+		WithArgs(http.StatusOK, []byte(`{"Content-Type":["application/json"]}`), []byte(`{}`), hashKey("key")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := NewRepository(db, 0).Complete(context.Background(), "key", &Response{
+		Status: http.StatusOK,
+		Header: http.Header{"Content-Type": {"application/json"}},
+		Body:   []byte(`{}`),
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestRepository_Release(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	m.ExpectExec("DELETE FROM idempotency_key WHERE key_hash = \\? AND status IS NULL").
+		WithArgs(hashKey("key")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, NewRepository(db, 0).Release(context.Background(), "key"))
+	assert.NoError(t, m.ExpectationsWereMet())
+}
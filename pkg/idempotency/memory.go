@@ -0,0 +1,78 @@
+package idempotency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"coding-challenge-go/pkg/storage"
+)
+
+// MemoryStore is the in-memory store of the idempotency keys, for the local development without
+// MySQL. It behaves like the Repository, but the keys are not shared by the instances of the API.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]*memoryRecord
+	now     func() time.Time
+}
+
+type memoryRecord struct {
+	Record
+	expiresAt time.Time
+}
+
+// NewMemoryStore builds the in-memory store of the idempotency keys.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: map[string]*memoryRecord{}, now: time.Now}
+}
+
+// Reserve is the in-memory implementation of Repository.Reserve.
+func (s *MemoryStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	for hash, record := range s.records {
+		if record.expiresAt.Before(now) {
+			delete(s.records, hash)
+		}
+	}
+
+	if record, ok := s.records[hashKey(key)]; ok {
+		reserved := record.Record
+
+		return &reserved, nil
+	}
+
+	s.records[hashKey(key)] = &memoryRecord{Record: Record{Fingerprint: fingerprint}, expiresAt: now.Add(ttl)}
+
+	return nil, nil
+}
+
+// Complete is the in-memory implementation of Repository.Complete.
+func (s *MemoryStore) Complete(ctx context.Context, key string, response *Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.records[hashKey(key)]
+	if !ok {
+		return storage.ErrNotFound
+	}
+
+	record.Response = response
+
+	return nil
+}
+
+// Release is the in-memory implementation of Repository.Release.
+func (s *MemoryStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record, ok := s.records[hashKey(key)]; ok && record.Response == nil {
+		delete(s.records, hashKey(key))
+	}
+
+	return nil
+}
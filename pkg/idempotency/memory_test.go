@@ -0,0 +1,54 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"coding-challenge-go/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	record, err := s.Reserve(ctx, "key", "fingerprint", time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	// the request of the key is in flight.
+	record, err = s.Reserve(ctx, "key", "other", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, &Record{Fingerprint: "fingerprint"}, record)
+
+	response := &Response{Status: http.StatusCreated, Header: http.Header{"Location": {"/api/v3/products/uuid"}}, Body: []byte(`{}`)}
+	assert.NoError(t, s.Complete(ctx, "key", response))
+
+	// the completed keys are not released.
+	assert.NoError(t, s.Release(ctx, "key"))
+
+	record, err = s.Reserve(ctx, "key", "fingerprint", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, &Record{Fingerprint: "fingerprint", Response: response}, record)
+
+	// the expired keys are purged.
+	now = now.Add(2 * time.Hour)
+
+	record, err = s.Reserve(ctx, "key", "other", time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	assert.NoError(t, s.Release(ctx, "key"))
+
+	record, err = s.Reserve(ctx, "key", "fingerprint", time.Hour)
+	assert.NoError(t, err)
+	assert.Nil(t, record)
+
+	assert.ErrorIs(t, s.Complete(ctx, "missing", response), storage.ErrNotFound)
+}
@@ -0,0 +1,15 @@
+package logging
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+)
+
+// WithStr returns a copy of ctx whose contextual logger carries the additional string field,
+// so that all the next log lines of the request include it.
+func WithStr(ctx context.Context, key, value string) context.Context {
+	logger := log.Ctx(ctx).With().Str(key, value).Logger()
+
+	return logger.WithContext(ctx)
+}
@@ -0,0 +1,481 @@
+// Package migrate versions the schema of the DB by the up and down migrations embedded in the binary,
+// every dialect has its own migrations of the same versions. The applied versions are recorded in the
+// schema_migrations table.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"coding-challenge-go/pkg/storage"
+)
+
+//go:embed migrations/*/*.sql
+var migrationsFS embed.FS
+
+//go:embed seed/*.sql
+var seedFS embed.FS
+
+// lockName is the advisory lock serializing the migrators, e.g. of the instances of the API
+// auto-migrating on startup.
+const lockName = "schema_migrations"
+
+// defaultLockTimeout is how long a migrator waits for the others to finish.
+const defaultLockTimeout = time.Minute
+
+// lockPollInterval is the interval of the attempts to take the lock of PostgreSQL, which can not wait
+// for it with a timeout.
+const lockPollInterval = 100 * time.Millisecond
+
+// schemaMigrationsDDL creates the table of the applied versions of the dialects.
+var schemaMigrationsDDL = map[*storage.Dialect]string{
+	storage.MySQL: `CREATE TABLE IF NOT EXISTS schema_migrations
+(
+  version    BIGINT unsigned NOT NULL,
+  name       VARCHAR(200)    NOT NULL,
+  applied_at DATETIME        NOT NULL,
+  PRIMARY KEY (version)
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8`,
+	storage.Postgres: `CREATE TABLE IF NOT EXISTS schema_migrations
+(
+  version    BIGINT       NOT NULL,
+  name       VARCHAR(200) NOT NULL,
+  applied_at TIMESTAMPTZ  NOT NULL,
+  PRIMARY KEY (version)
+)`,
+	storage.SQLite: `CREATE TABLE IF NOT EXISTS schema_migrations
+(
+  version    BIGINT       NOT NULL,
+  name       VARCHAR(200) NOT NULL,
+  applied_at DATETIME     NOT NULL,
+  PRIMARY KEY (version)
+)`,
+}
+
+// migrationFile is the name of a migration file, e.g. 0001_create_seller.up.sql.
+var migrationFile = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// statementEnd is a semicolon ending a line, which ends a statement.
+var statementEnd = regexp.MustCompile(`;\s*(\n|$)`)
+
+// Migration is a version of the schema, applied by Up and rolled back by Down.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration along with the time it is applied at, which is nil when it is pending.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Load loads the migrations of the directory, ordered by their versions. Every migration must have
+// both an up and a down file.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int]*Migration{}
+
+	for _, entry := range entries {
+		match := migrationFile.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("migrate: invalid migration file name %q", entry.Name())
+		}
+
+		version, _ := strconv.Atoi(match[1])
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migrate: migrations %s and %s have the same version", m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migrate: migration %04d_%s has no up or down file", m.Version, m.Name)
+		}
+
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Migrator applies and rolls back the migrations.
+//
+// NOTE - MySQL commits the DDL statements implicitly, so a migration can not be rolled back when one of
+// its statements fails. The migrations are therefore kept to a single DDL statement where possible.
+type Migrator struct {
+	db          *sql.DB
+	dialect     *storage.Dialect
+	migrations  []Migration
+	lockTimeout time.Duration
+	now         func() time.Time
+}
+
+// New builds the migrator of the migrations of the dialect of the DB embedded in the binary.
+func New(db *sql.DB) (*Migrator, error) {
+	migrations, err := Load(migrationsFS, path.Join("migrations", storage.DialectOf(db).Name))
+	if err != nil {
+		return nil, err
+	}
+
+	return NewMigrator(db, migrations), nil
+}
+
+// NewMigrator builds the migrator of the migrations, which must be ordered by their versions.
+func NewMigrator(db *sql.DB, migrations []Migration) *Migrator {
+	return &Migrator{
+		db:          db,
+		dialect:     storage.DialectOf(db),
+		migrations:  migrations,
+		lockTimeout: defaultLockTimeout,
+		now:         time.Now,
+	}
+}
+
+// Latest returns the version of the last migration, 0 when there is none.
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status returns all the migrations along with the time they are applied at.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	var statuses []Status
+
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		statuses = make([]Status, 0, len(m.migrations))
+
+		for _, migration := range m.migrations {
+			s := Status{Migration: migration}
+			if at, ok := applied[migration.Version]; ok {
+				s.AppliedAt = &at
+			}
+
+			statuses = append(statuses, s)
+		}
+
+		return nil
+	})
+
+	return statuses, err
+}
+
+// Up applies all the pending migrations, it returns the applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down rolls back the last applied migrations, as many as the steps. It returns the rolled back ones.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+
+			if err := m.down(ctx, conn, migration); err != nil {
+				return err
+			}
+
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// To migrates the schema to the version, by applying the pending migrations up to it and rolling back
+// the applied ones after it. Version 0 rolls back all the migrations. It returns the applied or rolled
+// back migrations.
+func (m *Migrator) To(ctx context.Context, version int) ([]Migration, error) {
+	if version != 0 && !m.known(version) {
+		return nil, fmt.Errorf("migrate: unknown version %d", version)
+	}
+
+	var done []Migration
+
+	err := m.locked(ctx, func(conn *sql.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok || migration.Version <= version {
+				continue
+			}
+
+			if err := m.down(ctx, conn, migration); err != nil {
+				return err
+			}
+
+			done = append(done, migration)
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok || migration.Version > version {
+				continue
+			}
+
+			if err := m.up(ctx, conn, migration); err != nil {
+				return err
+			}
+
+			done = append(done, migration)
+		}
+
+		return nil
+	})
+
+	return done, err
+}
+
+// Seed inserts the sample sellers and products, e.g. for local development. It is not a migration,
+// so that the production DBs are never seeded, and it fails when the rows already exist.
+func (m *Migrator) Seed(ctx context.Context) (err error) {
+	seedSQL, err := fs.ReadFile(seedFS, path.Join("seed", m.dialect.Name+".sql"))
+	if err != nil {
+		return err
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	for _, statement := range splitStatements(string(seedSQL)) {
+		if _, err = tx.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migrate: fail to seed: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m *Migrator) known(version int) bool {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// locked runs fn on a connection holding the lock of the migrations, the lock is bound to the connection.
+func (m *Migrator) locked(ctx context.Context, fn func(conn *sql.Conn) error) (err error) {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer conn.Close()
+
+	release, err := m.lock(ctx, conn)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if releaseErr := release(context.WithoutCancel(ctx)); err == nil {
+			err = releaseErr
+		}
+	}()
+
+	if _, err := conn.ExecContext(ctx, schemaMigrationsDDL[m.dialect]); err != nil {
+		return err
+	}
+
+	return fn(conn)
+}
+
+// lock takes the advisory lock of the migrations on the connection, it returns the function releasing it.
+//
+// NOTE - SQLite has no advisory locks, its DB is usually of a single instance. The statements of
+// concurrent migrators are serialized by the lock of the DB.
+func (m *Migrator) lock(ctx context.Context, conn *sql.Conn) (func(ctx context.Context) error, error) {
+	switch m.dialect {
+	case storage.SQLite:
+		return func(context.Context) error { return nil }, nil
+	case storage.Postgres:
+		return m.lockPostgres(ctx, conn)
+	}
+
+	var acquired sql.NullInt64
+	if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, int(m.lockTimeout.Seconds())).Scan(&acquired); err != nil {
+		return nil, err
+	}
+
+	if acquired.Int64 != 1 {
+		return nil, errors.New("migrate: timeout waiting for the lock of another migrator")
+	}
+
+	return func(ctx context.Context) error {
+		_, err := conn.ExecContext(ctx, "SELECT RELEASE_LOCK(?)", lockName)
+		return err
+	}, nil
+}
+
+// lockPostgres takes the advisory lock of PostgreSQL, which is identified by the hash of its name.
+func (m *Migrator) lockPostgres(ctx context.Context, conn *sql.Conn) (func(ctx context.Context) error, error) {
+	deadline := time.Now().Add(m.lockTimeout)
+
+	for {
+		var acquired bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", lockName).Scan(&acquired); err != nil {
+			return nil, err
+		}
+
+		if acquired {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			return nil, errors.New("migrate: timeout waiting for the lock of another migrator")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	return func(ctx context.Context) error {
+		_, err := conn.ExecContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", lockName)
+		return err
+	}, nil
+}
+
+// applied returns the applied versions along with the time they are applied at.
+func (m *Migrator) applied(ctx context.Context, conn *sql.Conn) (map[int]time.Time, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	applied := map[int]time.Time{}
+
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt time.Time
+		)
+
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+func (m *Migrator) up(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	for _, statement := range splitStatements(migration.Up) {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migrate: fail to apply %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	_, err := conn.ExecContext(
+		ctx,
+		m.dialect.Rebind("INSERT INTO schema_migrations (version, name, applied_at) VALUES(?,?,?)"),
+		migration.Version, migration.Name, m.now().UTC(),
+	)
+
+	return err
+}
+
+func (m *Migrator) down(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	for _, statement := range splitStatements(migration.Down) {
+		if _, err := conn.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("migrate: fail to roll back %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+	}
+
+	_, err := conn.ExecContext(ctx, m.dialect.Rebind("DELETE FROM schema_migrations WHERE version = ?"), migration.Version)
+
+	return err
+}
+
+// splitStatements splits the SQL by the semicolons ending the lines, as the driver executes a
+// single statement at once.
+func splitStatements(sql string) []string {
+	var statements []string
+
+	for _, statement := range statementEnd.Split(sql, -1) {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+
+	return statements
+}
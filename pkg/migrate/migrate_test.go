@@ -0,0 +1,321 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"coding-challenge-go/pkg/storage"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	appliedAt = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testMigrations = []Migration{
+		{Version: 1, Name: "create_seller", Up: "CREATE TABLE seller (id INT);", Down: "DROP TABLE seller;"},
+		{Version: 2, Name: "create_product", Up: "CREATE TABLE product (id INT);", Down: "DROP TABLE product;"},
+		{Version: 3, Name: "add_sku", Up: "ALTER TABLE product ADD sku INT;\nCREATE INDEX sku ON product (sku);", Down: "ALTER TABLE product DROP sku;"},
+	}
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name    string
+		fs      fstest.MapFS
+		want    []Migration
+		wantErr bool
+	}{
+		{
+			name: "loads the migrations ordered by their versions",
+			fs: fstest.MapFS{
+				"m/0002_create_product.up.sql":   {Data: []byte("CREATE TABLE product (id INT);")},
+				"m/0002_create_product.down.sql": {Data: []byte("DROP TABLE product;")},
+				"m/0001_create_seller.up.sql":    {Data: []byte("CREATE TABLE seller (id INT);")},
+				"m/0001_create_seller.down.sql":  {Data: []byte("DROP TABLE seller;")},
+			},
+			want: testMigrations[:2],
+		},
+		{
+			name: "returns error, when a migration has no down file",
+			fs: fstest.MapFS{
+				"m/0001_create_seller.up.sql": {Data: []byte("CREATE TABLE seller (id INT);")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns error, when two migrations have the same version",
+			fs: fstest.MapFS{
+				"m/0001_create_seller.up.sql":    {Data: []byte("CREATE TABLE seller (id INT);")},
+				"m/0001_create_product.down.sql": {Data: []byte("DROP TABLE product;")},
+			},
+			wantErr: true,
+		},
+		{
+			name: "returns error, when a file is not a migration",
+			fs: fstest.MapFS{
+				"m/create_seller.sql": {Data: []byte("CREATE TABLE seller (id INT);")},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(tt.fs, "m")
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	m, err := New(nil)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, m.migrations)
+	assert.Equal(t, m.migrations[len(m.migrations)-1].Version, m.Latest())
+}
+
+func TestNew_dialects(t *testing.T) {
+	mysql, err := Load(migrationsFS, "migrations/mysql")
+	require.NoError(t, err)
+
+	// the dialects have the same versions, so that a DB can be moved to another dialect.
+	for _, dialect := range []*storage.Dialect{storage.Postgres, storage.SQLite} {
+		migrations, err := Load(migrationsFS, "migrations/"+dialect.Name)
+		require.NoError(t, err)
+		require.Len(t, migrations, len(mysql))
+
+		for i, migration := range migrations {
+			assert.Equal(t, mysql[i].Version, migration.Version)
+			assert.Equal(t, mysql[i].Name, migration.Name)
+		}
+	}
+
+	for _, dialect := range []*storage.Dialect{storage.MySQL, storage.Postgres, storage.SQLite} {
+		seed, err := fs.ReadFile(seedFS, "seed/"+dialect.Name+".sql")
+		require.NoError(t, err)
+		assert.NotEmpty(t, splitStatements(string(seed)))
+	}
+}
+
+func TestMigrator_To(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		applied []int
+		expect  func(m sqlmock.Sqlmock)
+		want    []int
+		wantErr bool
+	}{
+		{
+			name:    "applies the pending migrations up to the version",
+			version: 2,
+			applied: []int{1},
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("CREATE TABLE product \\(id INT\\)").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectExec("INSERT INTO schema_migrations \\(version, name, applied_at\\) VALUES\\(\\?,\\?,\\?\\)").
+					WithArgs(2, "create_product", appliedAt).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want: []int{2},
+		},
+		{
+			name:    "applies every statement of the migration",
+			version: 3,
+			applied: []int{1, 2},
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("ALTER TABLE product ADD sku INT").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectExec("CREATE INDEX sku ON product \\(sku\\)").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectExec("INSERT INTO schema_migrations").
+					WithArgs(3, "add_sku", appliedAt).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want: []int{3},
+		},
+		{
+			name:    "rolls back the applied migrations after the version, the last one first",
+			version: 1,
+			applied: []int{1, 2, 3},
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("ALTER TABLE product DROP sku").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectExec("DELETE FROM schema_migrations WHERE version = \\?").
+					WithArgs(3).WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectExec("DROP TABLE product").WillReturnResult(sqlmock.NewResult(0, 0))
+				m.ExpectExec("DELETE FROM schema_migrations WHERE version = \\?").
+					WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			want: []int{3, 2},
+		},
+		{
+			name:    "does nothing, when the schema is at the version",
+			version: 3,
+			applied: []int{1, 2, 3},
+			expect:  func(m sqlmock.Sqlmock) {},
+		},
+		{
+			name:    "returns error and stops, when a migration fails",
+			version: 3,
+			applied: []int{1},
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectExec("CREATE TABLE product").WillReturnError(errors.New("any sql error"))
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, m, _ := sqlmock.New()
+			defer db.Close()
+
+			expectLocked(m, tt.applied)
+			tt.expect(m)
+			m.ExpectExec("SELECT RELEASE_LOCK").WithArgs(lockName).WillReturnResult(sqlmock.NewResult(0, 0))
+
+			got, err := testMigrator(db).To(context.Background(), tt.version)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, versions(got))
+			assert.NoError(t, m.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestMigrator_To_unknownVersion(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	_, err := testMigrator(db).To(context.Background(), 4)
+	assert.Error(t, err)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestMigrator_To_lockTimeout(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	m.ExpectQuery("SELECT GET_LOCK\\(\\?, \\?\\)").WithArgs(lockName, 60).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(0))
+
+	_, err := testMigrator(db).Up(context.Background())
+	assert.Error(t, err)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestMigrator_Down(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	expectLocked(m, []int{1, 2})
+	m.ExpectExec("DROP TABLE product").WillReturnResult(sqlmock.NewResult(0, 0))
+	m.ExpectExec("DELETE FROM schema_migrations WHERE version = \\?").
+		WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	m.ExpectExec("SELECT RELEASE_LOCK").WithArgs(lockName).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	got, err := testMigrator(db).Down(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, versions(got))
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestMigrator_Status(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	expectLocked(m, []int{1})
+	m.ExpectExec("SELECT RELEASE_LOCK").WithArgs(lockName).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	got, err := testMigrator(db).Status(context.Background())
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, &appliedAt, got[0].AppliedAt)
+	assert.Nil(t, got[1].AppliedAt)
+	assert.Nil(t, got[2].AppliedAt)
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+func TestMigrator_Seed(t *testing.T) {
+	db, m, _ := sqlmock.New()
+	defer db.Close()
+
+	m.ExpectBegin()
+	m.ExpectExec("INSERT INTO seller").WillReturnResult(sqlmock.NewResult(14, 14))
+	m.ExpectExec("INSERT INTO product").WillReturnError(errors.New("any sql error"))
+	m.ExpectRollback()
+
+	assert.Error(t, testMigrator(db).Seed(context.Background()))
+	assert.NoError(t, m.ExpectationsWereMet())
+}
+
+// TestMigrator_sqlite applies, seeds and rolls back the migrations of SQLite, which needs no external DB.
+func TestMigrator_sqlite(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := storage.Open(storage.SQLite, "file:"+filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	migrator, err := New(db)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(migrator.migrations))
+
+	require.NoError(t, migrator.Seed(ctx))
+
+	var sellers, products int
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(DISTINCT uuid) FROM seller").Scan(&sellers))
+	require.NoError(t, db.QueryRowContext(ctx, "SELECT COUNT(DISTINCT uuid) FROM product").Scan(&products))
+	assert.Equal(t, 14, sellers)
+	assert.Equal(t, 23, products)
+
+	rolledBack, err := migrator.To(ctx, 0)
+	require.NoError(t, err)
+	assert.Len(t, rolledBack, len(migrator.migrations))
+
+	applied, err = migrator.Up(ctx)
+	require.NoError(t, err)
+	assert.Len(t, applied, len(migrator.migrations))
+}
+
+func Test_splitStatements(t *testing.T) {
+	got := splitStatements("INSERT INTO a VALUES ('x;y');\n\nDELETE FROM b;  \nSELECT 1")
+	assert.Equal(t, []string{"INSERT INTO a VALUES ('x;y')", "DELETE FROM b", "SELECT 1"}, got)
+}
+
+func testMigrator(db *sql.DB) *Migrator {
+	m := NewMigrator(db, testMigrations)
+	m.now = func() time.Time { return appliedAt }
+
+	return m
+}
+
+// expectLocked expects the lock to be acquired and the applied versions to be read.
+func expectLocked(m sqlmock.Sqlmock, applied []int) {
+	m.ExpectQuery("SELECT GET_LOCK\\(\\?, \\?\\)").WithArgs(lockName, 60).
+		WillReturnRows(sqlmock.NewRows([]string{"lock"}).AddRow(1))
+	m.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	rows := sqlmock.NewRows([]string{"version", "applied_at"})
+	for _, version := range applied {
+		rows.AddRow(version, appliedAt)
+	}
+
+	m.ExpectQuery("SELECT version, applied_at FROM schema_migrations").WillReturnRows(rows)
+}
+
+func versions(migrations []Migration) []int {
+	var got []int
+	for _, m := range migrations {
+		got = append(got, m.Version)
+	}
+
+	return got
+}
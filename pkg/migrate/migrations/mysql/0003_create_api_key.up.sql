@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `api_key`
+(
+  `id_api_key` int(10) unsigned NOT NULL AUTO_INCREMENT,
+  `name`       VARCHAR(100)     NOT NULL,
+  `key_hash`   CHAR(64)         NOT NULL,
+  `scopes`     VARCHAR(200)     NOT NULL,
+  `fk_seller`  INT(10) unsigned NULL     DEFAULT NULL,
+  `created_at` DATETIME         NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  `revoked_at` DATETIME         NULL     DEFAULT NULL,
+  PRIMARY KEY (`id_api_key`),
+  UNIQUE KEY `name` (`name`),
+  UNIQUE KEY `key_hash` (`key_hash`),
+  CONSTRAINT fk_api_key_seller FOREIGN KEY (fk_seller) REFERENCES seller (id_seller)
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
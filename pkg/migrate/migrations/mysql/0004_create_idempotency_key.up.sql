@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `idempotency_key`
+(
+  `id_idempotency_key` INT(10) unsigned  NOT NULL AUTO_INCREMENT,
+  `key_hash`           CHAR(64)          NOT NULL,
+  `fingerprint`        CHAR(64)          NOT NULL,
+  `status`             SMALLINT unsigned NULL     DEFAULT NULL,
+  `headers`            TEXT              NULL     DEFAULT NULL,
+  `body`               MEDIUMBLOB        NULL     DEFAULT NULL,
+  `expires_at`         DATETIME          NOT NULL,
+  PRIMARY KEY (`id_idempotency_key`),
+  UNIQUE KEY `key_hash` (`key_hash`),
+  KEY `expires_at` (`expires_at`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
@@ -0,0 +1,3 @@
+ALTER TABLE `product`
+  DROP INDEX `seller_external_id`,
+  DROP COLUMN `external_id`;
@@ -0,0 +1,3 @@
+ALTER TABLE `product`
+  ADD COLUMN `external_id` VARCHAR(100) NULL DEFAULT NULL,
+  ADD UNIQUE KEY `seller_external_id` (`fk_seller`, `external_id`);
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS `import_job`
+(
+  `id_import_job` INT(10) unsigned NOT NULL AUTO_INCREMENT,
+  `uuid`          VARCHAR(36)      NOT NULL,
+  `principal`     VARCHAR(100)     NOT NULL DEFAULT '',
+  `format`        VARCHAR(10)      NOT NULL,
+  `dry_run`       TINYINT(1)       NOT NULL,
+  `upsert`        TINYINT(1)       NOT NULL,
+  `status`        VARCHAR(20)      NOT NULL,
+  `total`         INT(10) unsigned NOT NULL,
+  `processed`     INT(10) unsigned NOT NULL DEFAULT 0,
+  `created`       INT(10) unsigned NOT NULL DEFAULT 0,
+  `updated`       INT(10) unsigned NOT NULL DEFAULT 0,
+  `failed`        INT(10) unsigned NOT NULL DEFAULT 0,
+  `errors`        MEDIUMTEXT       NULL     DEFAULT NULL,
+  `error`         VARCHAR(500)     NULL     DEFAULT NULL,
+  `created_at`    DATETIME         NOT NULL,
+  `finished_at`   DATETIME         NULL     DEFAULT NULL,
+  PRIMARY KEY (`id_import_job`),
+  UNIQUE KEY `uuid` (`uuid`)
+) ENGINE = InnoDB
+  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS seller
+(
+  id_seller SERIAL       NOT NULL,
+  name      VARCHAR(200) NOT NULL,
+  email     VARCHAR(100) NOT NULL,
+  phone     VARCHAR(100) NOT NULL,
+  uuid      VARCHAR(36)  NOT NULL,
+  PRIMARY KEY (id_seller),
+  CONSTRAINT seller_uuid UNIQUE (uuid)
+);
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS product
+(
+  id_product SERIAL       NOT NULL,
+  name       VARCHAR(200) NOT NULL,
+  brand      VARCHAR(200) NOT NULL,
+  stock      INTEGER      DEFAULT 0,
+  fk_seller  INTEGER      NOT NULL,
+  uuid       VARCHAR(36)  NOT NULL,
+  PRIMARY KEY (id_product),
+  CONSTRAINT product_uuid UNIQUE (uuid),
+  CONSTRAINT fk_seller FOREIGN KEY (fk_seller) REFERENCES seller (id_seller)
+);
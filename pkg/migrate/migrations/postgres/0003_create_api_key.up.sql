@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS api_key
+(
+  id_api_key SERIAL       NOT NULL,
+  name       VARCHAR(100) NOT NULL,
+  key_hash   CHAR(64)     NOT NULL,
+  scopes     VARCHAR(200) NOT NULL,
+  fk_seller  INTEGER      NULL     DEFAULT NULL,
+  created_at TIMESTAMPTZ  NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  revoked_at TIMESTAMPTZ  NULL     DEFAULT NULL,
+  PRIMARY KEY (id_api_key),
+  CONSTRAINT api_key_name UNIQUE (name),
+  CONSTRAINT api_key_key_hash UNIQUE (key_hash),
+  CONSTRAINT fk_api_key_seller FOREIGN KEY (fk_seller) REFERENCES seller (id_seller)
+);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS idempotency_key
+(
+  id_idempotency_key SERIAL      NOT NULL,
+  key_hash           CHAR(64)    NOT NULL,
+  fingerprint        CHAR(64)    NOT NULL,
+  status             SMALLINT    NULL     DEFAULT NULL,
+  headers            TEXT        NULL     DEFAULT NULL,
+  body               BYTEA       NULL     DEFAULT NULL,
+  expires_at         TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (id_idempotency_key),
+  CONSTRAINT idempotency_key_key_hash UNIQUE (key_hash)
+);
+CREATE INDEX IF NOT EXISTS idempotency_key_expires_at ON idempotency_key (expires_at);
@@ -0,0 +1,3 @@
+ALTER TABLE product
+  DROP CONSTRAINT product_seller_external_id,
+  DROP COLUMN external_id;
@@ -0,0 +1,3 @@
+ALTER TABLE product
+  ADD COLUMN external_id VARCHAR(100) NULL DEFAULT NULL,
+  ADD CONSTRAINT product_seller_external_id UNIQUE (fk_seller, external_id);
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS seller
+(
+  id_seller INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+  name      VARCHAR(200) NOT NULL,
+  email     VARCHAR(100) NOT NULL,
+  phone     VARCHAR(100) NOT NULL,
+  uuid      VARCHAR(36)  NOT NULL,
+  CONSTRAINT seller_uuid UNIQUE (uuid)
+);
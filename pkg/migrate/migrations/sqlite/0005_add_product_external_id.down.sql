@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS product_seller_external_id;
+ALTER TABLE product DROP COLUMN external_id;
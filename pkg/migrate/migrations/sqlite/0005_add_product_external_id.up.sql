@@ -0,0 +1,2 @@
+ALTER TABLE product ADD COLUMN external_id VARCHAR(100) NULL DEFAULT NULL;
+CREATE UNIQUE INDEX product_seller_external_id ON product (fk_seller, external_id);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS import_job
+(
+  id_import_job INTEGER      NOT NULL PRIMARY KEY AUTOINCREMENT,
+  uuid          VARCHAR(36)  NOT NULL,
+  principal     VARCHAR(100) NOT NULL DEFAULT '',
+  format        VARCHAR(10)  NOT NULL,
+  dry_run       BOOLEAN      NOT NULL,
+  upsert        BOOLEAN      NOT NULL,
+  status        VARCHAR(20)  NOT NULL,
+  total         INTEGER      NOT NULL,
+  processed     INTEGER      NOT NULL DEFAULT 0,
+  created       INTEGER      NOT NULL DEFAULT 0,
+  updated       INTEGER      NOT NULL DEFAULT 0,
+  failed        INTEGER      NOT NULL DEFAULT 0,
+  errors        TEXT         NULL     DEFAULT NULL,
+  error         VARCHAR(500) NULL     DEFAULT NULL,
+  created_at    DATETIME     NOT NULL,
+  finished_at   DATETIME     NULL     DEFAULT NULL,
+  CONSTRAINT import_job_uuid UNIQUE (uuid)
+);
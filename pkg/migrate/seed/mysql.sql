@@ -1,33 +1,3 @@
-CREATE DATABASE IF NOT EXISTS `product`;
-
-USE `product`;
-
-CREATE TABLE IF NOT EXISTS `seller` (
-  `id_seller` int(10) unsigned NOT NULL AUTO_INCREMENT,
-  `name` VARCHAR(200) NOT NULL,
-  `email` VARCHAR(100) NOT NULL,
-  `phone` VARCHAR(100) NOT NULL,
-  `uuid` VARCHAR(36) NOT NULL,
-  PRIMARY KEY (`id_seller`),
-  UNIQUE KEY `uuid` (`uuid`)
-) ENGINE = InnoDB
-  DEFAULT CHARSET = utf8
-  ROW_FORMAT = DYNAMIC;
-
-CREATE TABLE IF NOT EXISTS `product`
-(
-  `id_product` int(10) unsigned NOT NULL AUTO_INCREMENT,
-  `name`       VARCHAR(200)     NOT NULL,
-  `brand`      VARCHAR(200)     NOT NULL,
-  `stock`      INT(10) DEFAULT 0,
-  `fk_seller`  INT(10) unsigned NOT NULL,
-  `uuid`       VARCHAR(36)      NOT NULL,
-  PRIMARY KEY (`id_product`),
-  UNIQUE KEY `uuid` (`uuid`),
-  CONSTRAINT fk_seller FOREIGN KEY (fk_seller) REFERENCES seller (id_seller)
-) ENGINE = InnoDB
-  DEFAULT CHARSET=utf8 ROW_FORMAT=DYNAMIC;
-
 INSERT INTO seller (id_seller, name, email, phone, uuid) VALUES
 (1, 'Christene Maggio', 'christene.maggio@seller.com', '202-555-0143', UUID()),
 (2, 'Owen Ringgold', 'owen.ringgold@seller.com', '202-555-0188', UUID()),
@@ -0,0 +1,44 @@
+INSERT INTO seller (id_seller, name, email, phone, uuid) VALUES
+(1, 'Christene Maggio', 'christene.maggio@seller.com', '202-555-0143', gen_random_uuid()),
+(2, 'Owen Ringgold', 'owen.ringgold@seller.com', '202-555-0188', gen_random_uuid()),
+(3, 'Shani Marinello', 'shani.marinello@seller.com', '202-555-0138', gen_random_uuid()),
+(4, 'Lajuana Mooring', 'lajuana.mooring@seller.com', '202-555-0103', gen_random_uuid()),
+(5, 'Tatyana Moua', 'tatyana.moua@seller.com', '202-555-0178', gen_random_uuid()),
+(6, 'Chelsie Wurster', 'chelsie.wurster@seller.com', '202-555-0187', gen_random_uuid()),
+(7, 'Syble Coria', 'syble.coria@seller.com', '202-555-0132', gen_random_uuid()),
+(8, 'Cathleen Swick', 'cathleen.swick@seller.com', '202-555-0166', gen_random_uuid()),
+(9, 'Cathleen Wurster', 'cathleen.wurster@seller.com', '202-555-0166', gen_random_uuid()),
+(10, 'Tatyana Ringgold', 'tatyana.ringgold@seller.com', '202-555-0166', gen_random_uuid()),
+(11, 'Chelsie Maggio', 'chelsie.maggio@seller.com', '202-555-0166', gen_random_uuid()),
+(12, 'Lajuana Marinello', 'lajuana.marinellok@seller.com', '202-555-0166', gen_random_uuid()),
+(13, 'Owen Swick', 'owen.swick@seller.com', '202-555-0166', gen_random_uuid()),
+(14, 'Christene Ringgold', 'christene.ringgold@seller.com', '202-555-0166', gen_random_uuid());
+
+INSERT INTO product (id_product, name, brand, stock, fk_seller, uuid) VALUES
+(1, 'Raja', 'RJ', 100, 1, gen_random_uuid()),
+(2, 'Pure Linen Plain Shirt', 'ShirtsCo', 44, 1, gen_random_uuid()),
+(3, 'Long Sleeve Nursing Tops', 'ShirtsCo', 12, 1, gen_random_uuid()),
+(4, 'Christian Dior Pure Poison Eau De Parfum Spray', 'Christian Dior', 1031, 2, gen_random_uuid()),
+(5, 'Speed TR Flexweave Shoes', 'Flexweave', 1300, 2, gen_random_uuid()),
+(6, 'Berlin New Shirt', 'ShirtsCo', 2100, 2, gen_random_uuid()),
+(7, '2 Pack Bikini Brief', 'ShirtsCo', 3200, 2, gen_random_uuid()),
+(8, 'Organic Moisturizing Lip Balm', 'Organic', 10, 2, gen_random_uuid()),
+(9, 'Combi Leather Sandals', 'ShoesCo', 1100, 3, gen_random_uuid()),
+(10, 'Sasha', 'Sasha', 120, 3, gen_random_uuid()),
+(11, 'Womens Bella Surfing Maxi Tank Dress', 'ShirtsCo', 2100, 4, gen_random_uuid()),
+(12, 'Plano Tee', 'TeeCo', 10, 4, gen_random_uuid()),
+(13, 'Solid Cross Back Bikini Top', 'ShirtsCo', 120, 3, gen_random_uuid()),
+(14, 'Ottana Sweater', 'Ottana', 3200, 1, gen_random_uuid()),
+(15, 'Brushed Herringbone Pant With Tape Detail In Loose Tapered Fit', 'Ottana', 100, 5, gen_random_uuid()),
+(16, 'Mina', 'Mina', 321, 6, gen_random_uuid()),
+(17, 'Claire Top', 'Claire', 1321, 7, gen_random_uuid()),
+(18, 'Calvin Klein Fully Delicious Sheer Plumping Lip Gloss', 'Calvin Klein', 132, 8, gen_random_uuid()),
+(19, 'Shara Shara White Stem Sleeping Mask', 'Shara Shara', 1021, 9, gen_random_uuid()),
+(20, 'Classy & Fabulous Coat', 'ShirtsCo', 1332, 11, gen_random_uuid()),
+(21, 'Black Cherry Dress', 'ShirtsCo', 11, 12, gen_random_uuid()),
+(22, 'Storm Jacket', 'ShirtsCo', 145, 14, gen_random_uuid()),
+(23, 'Sweet Daisy Top', 'ShirtsCo', 14400, 14, gen_random_uuid());
+
+-- the IDs are inserted explicitly, so the sequences continue after them.
+SELECT setval(pg_get_serial_sequence('seller', 'id_seller'), (SELECT MAX(id_seller) FROM seller));
+SELECT setval(pg_get_serial_sequence('product', 'id_product'), (SELECT MAX(id_product) FROM product));
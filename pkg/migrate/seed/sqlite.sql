@@ -0,0 +1,46 @@
+INSERT INTO seller (id_seller, name, email, phone, uuid) VALUES
+(1, 'Christene Maggio', 'christene.maggio@seller.com', '202-555-0143', lower(hex(randomblob(16)))),
+(2, 'Owen Ringgold', 'owen.ringgold@seller.com', '202-555-0188', lower(hex(randomblob(16)))),
+(3, 'Shani Marinello', 'shani.marinello@seller.com', '202-555-0138', lower(hex(randomblob(16)))),
+(4, 'Lajuana Mooring', 'lajuana.mooring@seller.com', '202-555-0103', lower(hex(randomblob(16)))),
+(5, 'Tatyana Moua', 'tatyana.moua@seller.com', '202-555-0178', lower(hex(randomblob(16)))),
+(6, 'Chelsie Wurster', 'chelsie.wurster@seller.com', '202-555-0187', lower(hex(randomblob(16)))),
+(7, 'Syble Coria', 'syble.coria@seller.com', '202-555-0132', lower(hex(randomblob(16)))),
+(8, 'Cathleen Swick', 'cathleen.swick@seller.com', '202-555-0166', lower(hex(randomblob(16)))),
+(9, 'Cathleen Wurster', 'cathleen.wurster@seller.com', '202-555-0166', lower(hex(randomblob(16)))),
+(10, 'Tatyana Ringgold', 'tatyana.ringgold@seller.com', '202-555-0166', lower(hex(randomblob(16)))),
+(11, 'Chelsie Maggio', 'chelsie.maggio@seller.com', '202-555-0166', lower(hex(randomblob(16)))),
+(12, 'Lajuana Marinello', 'lajuana.marinellok@seller.com', '202-555-0166', lower(hex(randomblob(16)))),
+(13, 'Owen Swick', 'owen.swick@seller.com', '202-555-0166', lower(hex(randomblob(16)))),
+(14, 'Christene Ringgold', 'christene.ringgold@seller.com', '202-555-0166', lower(hex(randomblob(16))));
+
+INSERT INTO product (id_product, name, brand, stock, fk_seller, uuid) VALUES
+(1, 'Raja', 'RJ', 100, 1, lower(hex(randomblob(16)))),
+(2, 'Pure Linen Plain Shirt', 'ShirtsCo', 44, 1, lower(hex(randomblob(16)))),
+(3, 'Long Sleeve Nursing Tops', 'ShirtsCo', 12, 1, lower(hex(randomblob(16)))),
+(4, 'Christian Dior Pure Poison Eau De Parfum Spray', 'Christian Dior', 1031, 2, lower(hex(randomblob(16)))),
+(5, 'Speed TR Flexweave Shoes', 'Flexweave', 1300, 2, lower(hex(randomblob(16)))),
+(6, 'Berlin New Shirt', 'ShirtsCo', 2100, 2, lower(hex(randomblob(16)))),
+(7, '2 Pack Bikini Brief', 'ShirtsCo', 3200, 2, lower(hex(randomblob(16)))),
+(8, 'Organic Moisturizing Lip Balm', 'Organic', 10, 2, lower(hex(randomblob(16)))),
+(9, 'Combi Leather Sandals', 'ShoesCo', 1100, 3, lower(hex(randomblob(16)))),
+(10, 'Sasha', 'Sasha', 120, 3, lower(hex(randomblob(16)))),
+(11, 'Womens Bella Surfing Maxi Tank Dress', 'ShirtsCo', 2100, 4, lower(hex(randomblob(16)))),
+(12, 'Plano Tee', 'TeeCo', 10, 4, lower(hex(randomblob(16)))),
+(13, 'Solid Cross Back Bikini Top', 'ShirtsCo', 120, 3, lower(hex(randomblob(16)))),
+(14, 'Ottana Sweater', 'Ottana', 3200, 1, lower(hex(randomblob(16)))),
+(15, 'Brushed Herringbone Pant With Tape Detail In Loose Tapered Fit', 'Ottana', 100, 5, lower(hex(randomblob(16)))),
+(16, 'Mina', 'Mina', 321, 6, lower(hex(randomblob(16)))),
+(17, 'Claire Top', 'Claire', 1321, 7, lower(hex(randomblob(16)))),
+(18, 'Calvin Klein Fully Delicious Sheer Plumping Lip Gloss', 'Calvin Klein', 132, 8, lower(hex(randomblob(16)))),
+(19, 'Shara Shara White Stem Sleeping Mask', 'Shara Shara', 1021, 9, lower(hex(randomblob(16)))),
+(20, 'Classy & Fabulous Coat', 'ShirtsCo', 1332, 11, lower(hex(randomblob(16)))),
+(21, 'Black Cherry Dress', 'ShirtsCo', 11, 12, lower(hex(randomblob(16)))),
+(22, 'Storm Jacket', 'ShirtsCo', 145, 14, lower(hex(randomblob(16)))),
+(23, 'Sweet Daisy Top', 'ShirtsCo', 14400, 14, lower(hex(randomblob(16))));
+
+-- SQLite has no UUID function, the random hex strings are formatted like the UUIDs of version 4.
+UPDATE seller SET uuid = substr(uuid, 1, 8) || '-' || substr(uuid, 9, 4) || '-4' || substr(uuid, 14, 3) || '-' ||
+  substr('89ab', 1 + abs(random()) % 4, 1) || substr(uuid, 18, 3) || '-' || substr(uuid, 21, 12);
+UPDATE product SET uuid = substr(uuid, 1, 8) || '-' || substr(uuid, 9, 4) || '-4' || substr(uuid, 14, 3) || '-' ||
+  substr('89ab', 1 + abs(random()) % 4, 1) || substr(uuid, 18, 3) || '-' || substr(uuid, 21, 12);
@@ -0,0 +1,143 @@
+// Package ratelimit limits the rate of the requests of the clients with token buckets.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit is the quota of a client: it may send Requests per Period, in bursts of Requests at most.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit parses the limit formatted as requests/period, e.g. 100/1m.
+func ParseLimit(s string) (Limit, error) {
+	parts := strings.SplitN(s, "/", 2)
+	if len(parts) != 2 {
+		return Limit{}, fmt.Errorf("ratelimit: invalid limit %q, expected requests/period", s)
+	}
+
+	requests, err := strconv.Atoi(parts[0])
+	if err != nil || requests <= 0 {
+		return Limit{}, fmt.Errorf("ratelimit: invalid requests of limit %q", s)
+	}
+
+	period, err := time.ParseDuration(parts[1])
+	if err != nil || period <= 0 {
+		return Limit{}, fmt.Errorf("ratelimit: invalid period of limit %q", s)
+	}
+
+	return Limit{Requests: requests, Period: period}, nil
+}
+
+func (l Limit) String() string {
+	return strconv.Itoa(l.Requests) + "/" + l.Period.String()
+}
+
+// rate is the tokens refilled per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result is the outcome of taking a token of a bucket.
+type Result struct {
+	Allowed bool
+	// Remaining are the tokens left in the bucket.
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until a token is available, it is 0 when the request is allowed.
+	RetryAfter time.Duration
+}
+
+// Store keeps the token buckets of the clients, the MemoryStore keeps them in the process,
+// a shared store may be implemented when the API is scaled out.
+type Store interface {
+	// Take takes a token of the bucket of the key, which is refilled by the limit.
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// MemoryStore is the Store keeping the buckets in memory. The buckets which are full again
+// are evicted, so that the store does not grow by the clients which are gone.
+type MemoryStore struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	maxPeriod time.Duration
+	evictedAt time.Time
+}
+
+// NewMemoryStore builds the in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{now: time.Now, buckets: map[string]*bucket{}}
+}
+
+// Take takes a token of the bucket of the key, it never fails.
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.evict(now, limit.Period)
+
+	capacity := float64(limit.Requests)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updatedAt: now}
+		s.buckets[key] = b
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updatedAt).Seconds()*limit.rate())
+	b.updatedAt = now
+
+	result := Result{}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = seconds((1 - b.tokens) / limit.rate())
+	}
+
+	result.Remaining = int(b.tokens)
+	result.Reset = seconds((capacity - b.tokens) / limit.rate())
+
+	return result, nil
+}
+
+// evict evicts the buckets which are full again, at most once per the longest period.
+func (s *MemoryStore) evict(now time.Time, period time.Duration) {
+	if period > s.maxPeriod {
+		s.maxPeriod = period
+	}
+
+	if now.Sub(s.evictedAt) < s.maxPeriod {
+		return
+	}
+
+	for key, b := range s.buckets {
+		if now.Sub(b.updatedAt) >= s.maxPeriod {
+			delete(s.buckets, key)
+		}
+	}
+
+	s.evictedAt = now
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
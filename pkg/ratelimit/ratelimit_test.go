@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		s       string
+		want    Limit
+		wantErr bool
+	}{
+		{s: "100/1m", want: Limit{Requests: 100, Period: time.Minute}},
+		{s: "5/1s", want: Limit{Requests: 5, Period: time.Second}},
+		{s: "100", wantErr: true},
+		{s: "0/1m", wantErr: true},
+		{s: "100/0s", wantErr: true},
+		{s: "many/1m", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			got, err := ParseLimit(tt.s)
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMemoryStore_Take(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s := NewMemoryStore()
+	s.now = func() time.Time { return now }
+
+	limit := Limit{Requests: 2, Period: 10 * time.Second}
+	ctx := context.Background()
+
+	got, _ := s.Take(ctx, "shop", limit)
+	assert.Equal(t, Result{Allowed: true, Remaining: 1, Reset: 5 * time.Second}, got)
+
+	got, _ = s.Take(ctx, "shop", limit)
+	assert.Equal(t, Result{Allowed: true, Remaining: 0, Reset: 10 * time.Second}, got)
+
+	got, _ = s.Take(ctx, "shop", limit)
+	assert.Equal(t, Result{Allowed: false, Remaining: 0, Reset: 10 * time.Second, RetryAfter: 5 * time.Second}, got)
+
+	got, _ = s.Take(ctx, "other", limit)
+	assert.True(t, got.Allowed, "the buckets of the clients are separated")
+
+	now = now.Add(5 * time.Second)
+
+	got, _ = s.Take(ctx, "shop", limit)
+	assert.Equal(t, Result{Allowed: true, Remaining: 0, Reset: 10 * time.Second}, got, "a token is refilled")
+
+	now = now.Add(time.Hour)
+
+	got, _ = s.Take(ctx, "shop", limit)
+	assert.Equal(t, Result{Allowed: true, Remaining: 1, Reset: 5 * time.Second}, got, "the bucket is not refilled over its capacity")
+	assert.Len(t, s.buckets, 1, "the buckets which are full again are evicted")
+}
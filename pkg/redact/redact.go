@@ -0,0 +1,176 @@
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Mode is the way the personal data is masked.
+type Mode string
+
+const (
+	// ModeFull replaces the whole value.
+	ModeFull Mode = "full"
+	// ModePartial keeps the first letter and the domain of the emails and the last 4 digits of the phones.
+	ModePartial Mode = "partial"
+	// ModeHashed replaces the value by its HMAC-SHA256, so that the same values can still be correlated.
+	ModeHashed Mode = "hashed"
+
+	redacted   = "[REDACTED]"
+	hashPrefix = "sha256:"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// phonePattern matches the US phone numbers, e.g. 202-555-0143, and the international ones of 8 to 15
+	// digits led by +, e.g. +4915112345678 (E.164), +49 151 12345678 or +44 (0)20 7946 0958.
+	phonePattern = regexp.MustCompile(`(\+\d{1,3}[\s\-])?\b\(?\d{3}\)?[\s.\-]\d{3}[\s.\-]\d{4}\b|\+[1-9](?:[\s.\-]?\(?\d\)?){7,14}`)
+	// phoneField matches the phone fields of JSON log lines, which may not follow phonePattern.
+	phoneField = regexp.MustCompile(`"phone":"((?:[^"\\]|\\.)*)"`)
+	digits     = regexp.MustCompile(`\d`)
+
+	// maskedEmail, maskedPhone and hashed match the outputs of Email and Phone.
+	maskedEmail = regexp.MustCompile(`^[^@]\*\*\*@[^@]+$`)
+	maskedPhone = regexp.MustCompile(`^\*\*\*\d{4}$`)
+	hashed      = regexp.MustCompile(`^` + hashPrefix + `[0-9a-f]{16}$`)
+)
+
+// Redactor masks the emails and phone numbers, so that the personal data is not written
+// in clear text to logs.
+type Redactor struct {
+	mode     Mode
+	hashKey  []byte
+	disabled bool
+}
+
+// New builds the Redactor masking with the given mode.
+//
+// The hashKey is used by ModeHashed only, which requires it. When debug is true the values are not masked at all,
+// it must be never enabled on production.
+func New(mode Mode, hashKey string, debug bool) (*Redactor, error) {
+	switch mode {
+	case ModeFull, ModePartial, ModeHashed:
+	default:
+		return nil, fmt.Errorf("redact.New: unknown mode %q", mode)
+	}
+
+	if mode == ModeHashed && hashKey == "" {
+		return nil, fmt.Errorf("redact.New: mode %q requires a hash key", mode)
+	}
+
+	return &Redactor{mode: mode, hashKey: []byte(hashKey), disabled: debug}, nil
+}
+
+// Email masks the email address. An already masked email is returned as it is.
+func (r *Redactor) Email(email string) string {
+	if r.disabled || email == "" || r.masked(email, maskedEmail) {
+		return email
+	}
+
+	switch r.mode {
+	case ModePartial:
+		at := strings.LastIndex(email, "@")
+		if at < 1 {
+			return redacted
+		}
+
+		return email[:1] + "***" + email[at:]
+	case ModeHashed:
+		return r.hash(email)
+	}
+
+	return redacted
+}
+
+// Phone masks the phone number. An already masked phone number is returned as it is.
+func (r *Redactor) Phone(phone string) string {
+	if r.disabled || phone == "" || r.masked(phone, maskedPhone) {
+		return phone
+	}
+
+	switch r.mode {
+	case ModePartial:
+		d := digits.FindAllString(phone, -1)
+		if len(d) <= 4 {
+			return redacted
+		}
+
+		return "***" + strings.Join(d[len(d)-4:], "")
+	case ModeHashed:
+		return r.hash(phone)
+	}
+
+	return redacted
+}
+
+// String masks all the emails and phone numbers found in s.
+func (r *Redactor) String(s string) string {
+	if r.disabled {
+		return s
+	}
+
+	s = emailPattern.ReplaceAllStringFunc(s, r.Email)
+
+	return phonePattern.ReplaceAllStringFunc(s, r.Phone)
+}
+
+// hash returns the keyed hash of the value, short enough to be readable in logs.
+func (r *Redactor) hash(value string) string {
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write([]byte(value))
+
+	return hashPrefix + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// masked reports whether the value is exactly the output of Email or Phone in the mode of the Redactor,
+// e.g. the fields masked by their logger are not masked again by the Writer. The partial output is
+// matched by partial. The values which only look masked, e.g. ***+4915112345678, are masked again.
+func (r *Redactor) masked(value string, partial *regexp.Regexp) bool {
+	switch r.mode {
+	case ModePartial:
+		return value == redacted || partial.MatchString(value)
+	case ModeHashed:
+		return hashed.MatchString(value)
+	}
+
+	return value == redacted
+}
+
+// Writer wraps w to mask the personal data of every written log line, it is meant
+// to be the output of zerolog loggers.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	if r.disabled {
+		return w
+	}
+
+	return &writer{w: w, r: r}
+}
+
+// writer is the io.Writer masking the personal data before writing to underlying writer.
+type writer struct {
+	w io.Writer
+	r *Redactor
+}
+
+// Write masks p and writes it to the underlying writer.
+//
+// The length of p is returned on success, as the callers are not aware of masking.
+func (w *writer) Write(p []byte) (int, error) {
+	line := phoneField.ReplaceAllFunc(p, func(field []byte) []byte {
+		value := phoneField.FindSubmatch(field)[1]
+
+		return []byte(`"phone":"` + w.r.Phone(string(value)) + `"`)
+	})
+	line = []byte(w.r.String(string(line)))
+
+	if _, err := w.w.Write(line); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,135 @@
+package redact
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedactor_Email(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  Mode
+		debug bool
+		email string
+		want  string
+	}{
+		{name: "full", mode: ModeFull, email: "christene.maggio@seller.com", want: "[REDACTED]"},
+		{name: "partial", mode: ModePartial, email: "christene.maggio@seller.com", want: "c***@seller.com"},
+		{name: "partial, invalid email", mode: ModePartial, email: "@seller.com", want: "[REDACTED]"},
+		{name: "hashed", mode: ModeHashed, email: "christene.maggio@seller.com", want: "sha256:a8eaf3670063a987"},
+		{name: "debug", mode: ModeFull, debug: true, email: "christene.maggio@seller.com", want: "christene.maggio@seller.com"},
+		{name: "empty", mode: ModeFull, email: "", want: ""},
+		{name: "partial, already masked", mode: ModePartial, email: "c***@seller.com", want: "c***@seller.com"},
+		{name: "partial, looks masked", mode: ModePartial, email: "jo***hn@seller.com", want: "j***@seller.com"},
+		{name: "full, masked partially", mode: ModeFull, email: "c***@seller.com", want: "[REDACTED]"},
+		{name: "hashed, looks hashed", mode: ModeHashed, email: "sha256:secret", want: "sha256:1f0209f8f31fff4c"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(tt.mode, "key", tt.debug)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, r.Email(tt.email))
+		})
+	}
+}
+
+func TestRedactor_Phone(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  Mode
+		phone string
+		want  string
+	}{
+		{name: "full", mode: ModeFull, phone: "202-555-0143", want: "[REDACTED]"},
+		{name: "partial", mode: ModePartial, phone: "202-555-0143", want: "***0143"},
+		{name: "partial, too short", mode: ModePartial, phone: "0143", want: "[REDACTED]"},
+		{name: "hashed is stable", mode: ModeHashed, phone: "202-555-0143", want: "sha256:21f50c4ae993b1e3"},
+		{name: "partial, already masked", mode: ModePartial, phone: "***0143", want: "***0143"},
+		{name: "hashed, already masked", mode: ModeHashed, phone: "sha256:21f50c4ae993b1e3", want: "sha256:21f50c4ae993b1e3"},
+		{name: "partial, E.164", mode: ModePartial, phone: "+4915112345678", want: "***5678"},
+		{name: "partial, looks masked", mode: ModePartial, phone: "***+4915112345678", want: "***5678"},
+		{name: "full, masked partially", mode: ModeFull, phone: "***0143", want: "[REDACTED]"},
+		{name: "hashed, looks masked", mode: ModeHashed, phone: "***+4915112345678", want: "sha256:2e5e2eaa410de947"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(tt.mode, "key", false)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, r.Phone(tt.phone))
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("none", "", false)
+	assert.Error(t, err)
+
+	_, err = New(ModeHashed, "", false)
+	assert.Error(t, err)
+}
+
+func TestRedactor_Writer(t *testing.T) {
+	tests := []struct {
+		name  string
+		mode  Mode
+		debug bool
+		log   func(l zerolog.Logger)
+		want  string
+	}{
+		{
+			name: "masks the phone and email fields",
+			mode: ModePartial,
+			log: func(l zerolog.Logger) {
+				l.Info().Str("email", "owen.ringgold@seller.com").Str("phone", "324-3243-32").Msg("sent")
+			},
+			want: `{"level":"info","email":"o***@seller.com","phone":"***4332","message":"sent"}` + "\n",
+		},
+		{
+			name: "masks the emails and phones in errors",
+			mode: ModeFull,
+			log: func(l zerolog.Logger) {
+				l.Error().Str("error", "duplicate entry owen.ringgold@seller.com, 202-555-0188").Msg("failed")
+			},
+			want: `{"level":"error","error":"duplicate entry [REDACTED], [REDACTED]","message":"failed"}` + "\n",
+		},
+		{
+			name: "masks the international phones",
+			mode: ModePartial,
+			log: func(l zerolog.Logger) {
+				l.Error().Str("error", "call +4915112345678, +49 151 12345678 or +44 (0)20 7946 0958").Msg("failed")
+			},
+			want: `{"level":"error","error":"call ***5678, ***5678 or ***0958","message":"failed"}` + "\n",
+		},
+		{
+			name: "does not mask UUIDs",
+			mode: ModeFull,
+			log: func(l zerolog.Logger) {
+				l.Info().Str("seller_uuid", "bdbba8f8-1234-5678-1234-024212130002").Msg("sent")
+			},
+			want: `{"level":"info","seller_uuid":"bdbba8f8-1234-5678-1234-024212130002","message":"sent"}` + "\n",
+		},
+		{
+			name:  "debug does not mask",
+			mode:  ModeFull,
+			debug: true,
+			log: func(l zerolog.Logger) {
+				l.Info().Str("phone", "202-555-0188").Msg("sent")
+			},
+			want: `{"level":"info","phone":"202-555-0188","message":"sent"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := New(tt.mode, "key", tt.debug)
+			assert.NoError(t, err)
+
+			buf := &bytes.Buffer{}
+			tt.log(zerolog.New(r.Writer(buf)))
+
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"modernc.org/sqlite"
+)
+
+// Dialect is the SQL dialect of a DB. The repositories write their queries with the ? placeholders
+// in the SQL common to the dialects, which the dialect adapts to its driver.
+type Dialect struct {
+	// Name is the name of the dialect, as configured by DB_BACKEND.
+	Name string
+	// Driver is the name of the database/sql driver of the dialect.
+	Driver string
+	// System is the DB system of the dialect, the attribute of the spans of the queries.
+	System attribute.KeyValue
+	// Returning reports whether the INSERT and the UPDATE statements can return the written values
+	// by RETURNING, otherwise only the generated ID is returned by LastInsertId.
+	Returning bool
+	// numbered reports whether the placeholders are numbered, like $1.
+	numbered bool
+}
+
+var (
+	// MySQL is the dialect of MySQL, the reference of the queries.
+	MySQL = &Dialect{Name: "mysql", Driver: "mysql", System: semconv.DBSystemNameMySQL}
+	// Postgres is the dialect of PostgreSQL, its queries have numbered placeholders.
+	Postgres = &Dialect{Name: "postgres", Driver: "pgx", System: semconv.DBSystemNamePostgreSQL, Returning: true, numbered: true}
+	// SQLite is the dialect of SQLite, of the pure Go driver, e.g. for the local development and
+	// the tests.
+	SQLite = &Dialect{Name: "sqlite", Driver: "sqlite", System: semconv.DBSystemNameSQLite, Returning: true}
+)
+
+// DialectByName returns the dialect of the name.
+func DialectByName(name string) (*Dialect, error) {
+	for _, d := range []*Dialect{MySQL, Postgres, SQLite} {
+		if d.Name == name {
+			return d, nil
+		}
+	}
+
+	return nil, fmt.Errorf("storage: unknown dialect %q", name)
+}
+
+// DialectOf returns the dialect of the driver of the DB. The DBs of other drivers, e.g. the mocks of
+// the tests, are of MySQL.
+func DialectOf(db *sql.DB) *Dialect {
+	if db == nil {
+		return MySQL
+	}
+
+	switch db.Driver().(type) {
+	case *stdlib.Driver:
+		return Postgres
+	case *sqlite.Driver:
+		return SQLite
+	}
+
+	return MySQL
+}
+
+// sqliteParams are the params of the SQLite DSNs, unless the DSN sets them: the foreign keys are
+// enforced unlike the default of SQLite, a write waits for the lock of the DB taken by another one,
+// and the times are written in the format of the date functions of SQLite, so that they are ordered.
+var sqliteParams = []struct{ name, param string }{
+	{name: "foreign_keys", param: "_pragma=foreign_keys(1)"},
+	{name: "busy_timeout", param: "_pragma=busy_timeout(5000)"},
+	{name: "_time_format", param: "_time_format=sqlite"},
+}
+
+// Open opens the DB of the dialect.
+func Open(d *Dialect, dsn string) (*sql.DB, error) {
+	if d == SQLite {
+		for _, p := range sqliteParams {
+			if strings.Contains(dsn, p.name) {
+				continue
+			}
+
+			separator := "?"
+			if strings.Contains(dsn, "?") {
+				separator = "&"
+			}
+
+			dsn += separator + p.param
+		}
+	}
+
+	return sql.Open(d.Driver, dsn)
+}
+
+// Bind returns the DB, or the transaction, running the queries adapted to the dialect.
+func (d *Dialect) Bind(db DBTX) DBTX {
+	if !d.numbered {
+		return db
+	}
+
+	return &boundDB{db: db, dialect: d}
+}
+
+// Rebind replaces the ? placeholders of the query by the placeholders of the dialect. The question
+// marks of the string literals are left as they are.
+func (d *Dialect) Rebind(query string) string {
+	if !d.numbered {
+		return query
+	}
+
+	var (
+		b       strings.Builder
+		n       int
+		literal bool
+	)
+
+	b.Grow(len(query) + 8)
+
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			literal = !literal
+		case r == '?' && !literal:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+
+			continue
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+// InsertID runs the INSERT and returns the ID generated for the column.
+func (d *Dialect) InsertID(ctx context.Context, db DBTX, query, column string, args ...interface{}) (int64, error) {
+	if !d.Returning {
+		result, err := db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+
+		return result.LastInsertId()
+	}
+
+	// NOTE - the driver of PostgreSQL does not support LastInsertId.
+	rows, err := db.QueryContext(ctx, query+" RETURNING "+column, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	defer rows.Close()
+
+	var id int64
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+
+		return 0, fmt.Errorf("storage: %s is not returned", column)
+	}
+
+	if err := rows.Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, rows.Close()
+}
+
+// boundDB runs the queries on db with the placeholders of the dialect.
+type boundDB struct {
+	db      DBTX
+	dialect *Dialect
+}
+
+func (b *boundDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return b.db.ExecContext(ctx, b.dialect.Rebind(query), args...)
+}
+
+func (b *boundDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return b.db.QueryContext(ctx, b.dialect.Rebind(query), args...)
+}
@@ -0,0 +1,95 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDialect_Rebind(t *testing.T) {
+	query := "SELECT * FROM product WHERE uuid = ? AND name <> '?' LIMIT ? OFFSET ?"
+
+	assert.Equal(t, query, MySQL.Rebind(query))
+	assert.Equal(t, query, SQLite.Rebind(query))
+	assert.Equal(t, "SELECT * FROM product WHERE uuid = $1 AND name <> '?' LIMIT $2 OFFSET $3", Postgres.Rebind(query))
+}
+
+func TestDialectByName(t *testing.T) {
+	for _, want := range []*Dialect{MySQL, Postgres, SQLite} {
+		got, err := DialectByName(want.Name)
+		require.NoError(t, err)
+		assert.Same(t, want, got)
+	}
+
+	_, err := DialectByName("oracle")
+	assert.Error(t, err)
+}
+
+func TestDialectOf(t *testing.T) {
+	db, err := Open(SQLite, "file:"+filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	assert.Same(t, SQLite, DialectOf(db))
+	assert.Same(t, MySQL, DialectOf(nil))
+}
+
+// TestFromDB_sqlite translates the errors of a SQLite DB, as the errors of its driver can not be built.
+func TestFromDB_sqlite(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := Open(SQLite, "file:"+filepath.Join(t.TempDir(), "test.db"))
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	for _, statement := range []string{
+		"CREATE TABLE seller (id_seller INTEGER PRIMARY KEY, name VARCHAR(200) NOT NULL, uuid VARCHAR(36) NOT NULL, CONSTRAINT seller_uuid UNIQUE (uuid))",
+		"CREATE TABLE product (id_product INTEGER PRIMARY KEY, fk_seller INTEGER NOT NULL REFERENCES seller (id_seller), name VARCHAR(200) NOT NULL UNIQUE)",
+		"INSERT INTO seller (id_seller, name, uuid) VALUES(1, 'james', 'e943dc0a')",
+		"INSERT INTO product (fk_seller, name) VALUES(1, 'shoes')",
+	} {
+		_, err := db.ExecContext(ctx, statement)
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name      string
+		statement string
+		want      error
+	}{
+		{
+			name:      "duplicate uuid",
+			statement: "INSERT INTO seller (name, uuid) VALUES('mark', 'e943dc0a')",
+			want:      ErrDuplicateUUID,
+		},
+		{
+			name:      "duplicate other key",
+			statement: "INSERT INTO product (fk_seller, name) VALUES(1, 'shoes')",
+			want:      ErrConflict,
+		},
+		{
+			name:      "null foreign key selected by subquery",
+			statement: "INSERT INTO product (fk_seller, name) VALUES((SELECT id_seller FROM seller WHERE uuid = 'a223850e'), 'socks')",
+			want:      ErrForeignKey,
+		},
+		{
+			name:      "referenced row",
+			statement: "DELETE FROM seller WHERE id_seller = 1",
+			want:      ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := db.ExecContext(ctx, tt.statement)
+			require.Error(t, err)
+
+			assert.ErrorIs(t, FromDB(err), tt.want)
+			assert.False(t, IsDeadlock(err))
+		})
+	}
+}
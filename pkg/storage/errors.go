@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+var (
+	// ErrNotFound is returned when the written entity does not exist (anymore).
+	ErrNotFound = errors.New("storage: not found")
+	// ErrConflict is returned when the write conflicts with the current state of the entity.
+	ErrConflict = errors.New("storage: conflict")
+	// ErrForeignKey is returned when the written entity references a missing entity.
+	ErrForeignKey = errors.New("storage: foreign key violation")
+	// ErrDuplicateUUID is returned when an entity with the same UUID already exists.
+	ErrDuplicateUUID = errors.New("storage: duplicate uuid")
+)
+
+// MySQL error numbers, see https://dev.mysql.com/doc/mysql-errors/5.7/en/server-error-reference.html
+const (
+	mysqlErrNoReferencedRow2 = 1216
+	mysqlErrRowIsReferenced2 = 1217
+	mysqlErrBadNull          = 1048
+	mysqlErrDupEntry         = 1062
+	mysqlErrRowIsReferenced  = 1451
+	mysqlErrNoReferencedRow  = 1452
+	mysqlErrDupEntryWithKey  = 1586
+)
+
+// PostgreSQL error codes, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	pgErrNotNullViolation    = "23502"
+	pgErrForeignKeyViolation = "23503"
+	pgErrUniqueViolation     = "23505"
+)
+
+// FromDB translates the error of a write, of any dialect, into one of the sentinel errors of storage,
+// wrapping the original error. Other errors are returned as they are.
+//
+// An INSERT which selects the foreign key by a subquery, which does not find any row,
+// fails on the NOT NULL constraint, so it is considered a foreign key violation too.
+func FromDB(err error) error {
+	var (
+		mysqlErr  *mysql.MySQLError
+		pgErr     *pgconn.PgError
+		sqliteErr *sqlite.Error
+	)
+
+	switch {
+	case errors.As(err, &mysqlErr):
+		return fromMySQL(err, mysqlErr)
+	case errors.As(err, &pgErr):
+		return fromPostgres(err, pgErr)
+	case errors.As(err, &sqliteErr):
+		return fromSQLite(err, sqliteErr)
+	}
+
+	return err
+}
+
+func fromMySQL(err error, mysqlErr *mysql.MySQLError) error {
+	switch mysqlErr.Number {
+	case mysqlErrDupEntry, mysqlErrDupEntryWithKey:
+		// the message looks like: Duplicate entry '...' for key 'uuid', the key is qualified by the
+		// table since MySQL 8, e.g. 'product.uuid'.
+		if strings.HasSuffix(mysqlErr.Message, " 'uuid'") || strings.HasSuffix(mysqlErr.Message, ".uuid'") {
+			return fmt.Errorf("%w: %v", ErrDuplicateUUID, err)
+		}
+
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	case mysqlErrBadNull, mysqlErrNoReferencedRow, mysqlErrNoReferencedRow2:
+		return fmt.Errorf("%w: %v", ErrForeignKey, err)
+	case mysqlErrRowIsReferenced, mysqlErrRowIsReferenced2:
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	}
+
+	return err
+}
+
+func fromPostgres(err error, pgErr *pgconn.PgError) error {
+	switch pgErr.Code {
+	case pgErrUniqueViolation:
+		// the unique constraints of the UUIDs are named like seller_uuid.
+		if strings.HasSuffix(pgErr.ConstraintName, "_uuid") {
+			return fmt.Errorf("%w: %v", ErrDuplicateUUID, err)
+		}
+
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	case pgErrNotNullViolation:
+		return fmt.Errorf("%w: %v", ErrForeignKey, err)
+	case pgErrForeignKeyViolation:
+		// the message looks like: update or delete on table "seller" violates foreign key constraint ...
+		if strings.HasPrefix(pgErr.Message, "update or delete") {
+			return fmt.Errorf("%w: %v", ErrConflict, err)
+		}
+
+		return fmt.Errorf("%w: %v", ErrForeignKey, err)
+	}
+
+	return err
+}
+
+// fromSQLite translates the error of SQLite.
+//
+// NOTE - SQLite does not tell whether the row references a missing row or is referenced, on a foreign
+// key violation. The repositories select the foreign keys by subqueries, which fail on the NOT NULL
+// constraint instead, so the violation is of a referenced row.
+func fromSQLite(err error, sqliteErr *sqlite.Error) error {
+	switch sqliteErr.Code() {
+	case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+		// the message looks like: constraint failed: UNIQUE constraint failed: seller.uuid (2067)
+		if strings.Contains(sqliteErr.Error(), ".uuid (") {
+			return fmt.Errorf("%w: %v", ErrDuplicateUUID, err)
+		}
+
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	case sqlite3.SQLITE_CONSTRAINT_NOTNULL:
+		return fmt.Errorf("%w: %v", ErrForeignKey, err)
+	case sqlite3.SQLITE_CONSTRAINT_FOREIGNKEY:
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	}
+
+	return err
+}
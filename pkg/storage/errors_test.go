@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromDB(t *testing.T) {
+	anyErr := errors.New("any error")
+
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{
+			name: "duplicate uuid",
+			err:  &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'e943dc0a' for key 'uuid'"},
+			want: ErrDuplicateUUID,
+		},
+		{
+			name: "duplicate uuid of MySQL 8",
+			err:  &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'e943dc0a' for key 'product.uuid'"},
+			want: ErrDuplicateUUID,
+		},
+		{
+			name: "duplicate other key",
+			err:  &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'name'"},
+			want: ErrConflict,
+		},
+		{
+			name: "duplicate other key of MySQL 8",
+			err:  &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'api_key.name'"},
+			want: ErrConflict,
+		},
+		{
+			name: "null foreign key selected by subquery",
+			err:  &mysql.MySQLError{Number: 1048, Message: "Column 'fk_seller' cannot be null"},
+			want: ErrForeignKey,
+		},
+		{
+			name: "missing referenced row",
+			err:  &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row"},
+			want: ErrForeignKey,
+		},
+		{
+			name: "referenced row",
+			err:  &mysql.MySQLError{Number: 1451, Message: "Cannot delete or update a parent row"},
+			want: ErrConflict,
+		},
+		{
+			name: "other MySQL error",
+			err:  &mysql.MySQLError{Number: 1064, Message: "syntax error"},
+			want: nil,
+		},
+		{
+			name: "duplicate uuid of Postgres",
+			err:  &pgconn.PgError{Code: "23505", ConstraintName: "seller_uuid"},
+			want: ErrDuplicateUUID,
+		},
+		{
+			name: "duplicate other key of Postgres",
+			err:  &pgconn.PgError{Code: "23505", ConstraintName: "product_seller_external_id"},
+			want: ErrConflict,
+		},
+		{
+			name: "null foreign key of Postgres",
+			err:  &pgconn.PgError{Code: "23502", Message: `null value in column "fk_seller" violates not-null constraint`},
+			want: ErrForeignKey,
+		},
+		{
+			name: "missing referenced row of Postgres",
+			err:  &pgconn.PgError{Code: "23503", Message: `insert or update on table "product" violates foreign key constraint "fk_seller"`},
+			want: ErrForeignKey,
+		},
+		{
+			name: "referenced row of Postgres",
+			err:  &pgconn.PgError{Code: "23503", Message: `update or delete on table "seller" violates foreign key constraint "fk_seller" on table "product"`},
+			want: ErrConflict,
+		},
+		{
+			name: "other Postgres error",
+			err:  &pgconn.PgError{Code: "42601", Message: "syntax error"},
+			want: nil,
+		},
+		{
+			name: "not DB error",
+			err:  anyErr,
+			want: anyErr,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FromDB(tt.err)
+
+			if tt.want == nil {
+				assert.Equal(t, tt.err, got)
+				return
+			}
+
+			assert.ErrorIs(t, got, tt.want)
+		})
+	}
+}
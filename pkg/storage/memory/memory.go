@@ -0,0 +1,262 @@
+// Package memory is an in-memory DB of the sellers and the products, for the tests and the local
+// development without MySQL. It keeps the constraints of the MySQL schema, its tables are lost when
+// the process exits.
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"coding-challenge-go/pkg/storage"
+)
+
+// Seller is a row of the seller table.
+type Seller struct {
+	ID    int
+	UUID  string
+	Name  string
+	Email string
+	Phone string
+}
+
+// Product is a row of the product table.
+type Product struct {
+	ID         int
+	UUID       string
+	Name       string
+	Brand      string
+	Stock      int
+	SellerID   int
+	ExternalID string
+}
+
+// Tables are the tables of the DB, their rows are indexed by UUID. The rows may be modified only by
+// DB.Write, the modifications of the rows read by DB.Read would not be isolated.
+type Tables struct {
+	sellers       map[string]*Seller
+	products      map[string]*Product
+	nextSellerID  int
+	nextProductID int
+}
+
+// SellerByUUID returns the seller, nil when there is none.
+func (t *Tables) SellerByUUID(uuid string) *Seller {
+	return t.sellers[uuid]
+}
+
+// SellerByID returns the seller, nil when there is none.
+func (t *Tables) SellerByID(id int) *Seller {
+	for _, seller := range t.sellers {
+		if seller.ID == id {
+			return seller
+		}
+	}
+
+	return nil
+}
+
+// Sellers returns all the sellers in the order of their IDs.
+func (t *Tables) Sellers() []*Seller {
+	sellers := make([]*Seller, 0, len(t.sellers))
+	for _, seller := range t.sellers {
+		sellers = append(sellers, seller)
+	}
+
+	sort.Slice(sellers, func(i, j int) bool { return sellers[i].ID < sellers[j].ID })
+
+	return sellers
+}
+
+// InsertSeller inserts the seller, its ID is generated.
+//
+// Returns storage.ErrDuplicateUUID, when a seller of the UUID exists.
+func (t *Tables) InsertSeller(seller *Seller) error {
+	if _, ok := t.sellers[seller.UUID]; ok {
+		return storage.ErrDuplicateUUID
+	}
+
+	t.nextSellerID++
+	seller.ID = t.nextSellerID
+	t.sellers[seller.UUID] = seller
+
+	return nil
+}
+
+// DeleteSeller deletes the seller.
+//
+// Returns storage.ErrNotFound, when the seller does not exist. Returns storage.ErrConflict, when the
+// seller still has products.
+func (t *Tables) DeleteSeller(uuid string) error {
+	seller, ok := t.sellers[uuid]
+	if !ok {
+		return storage.ErrNotFound
+	}
+
+	for _, product := range t.products {
+		if product.SellerID == seller.ID {
+			return storage.ErrConflict
+		}
+	}
+
+	delete(t.sellers, uuid)
+
+	return nil
+}
+
+// ProductByUUID returns the product, nil when there is none.
+func (t *Tables) ProductByUUID(uuid string) *Product {
+	return t.products[uuid]
+}
+
+// Products returns all the products in the order of their IDs.
+func (t *Tables) Products() []*Product {
+	products := make([]*Product, 0, len(t.products))
+	for _, product := range t.products {
+		products = append(products, product)
+	}
+
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+
+	return products
+}
+
+// InsertProduct inserts the product, its ID is generated unless it is set.
+//
+// Returns storage.ErrForeignKey, when its seller does not exist. Returns storage.ErrDuplicateUUID,
+// when a product of the UUID exists. Returns storage.ErrConflict, when a product of the ID or of the
+// external ID of the seller exists.
+func (t *Tables) InsertProduct(product *Product) error {
+	if t.SellerByID(product.SellerID) == nil {
+		return storage.ErrForeignKey
+	}
+
+	if _, ok := t.products[product.UUID]; ok {
+		return storage.ErrDuplicateUUID
+	}
+
+	for _, other := range t.products {
+		if (product.ID != 0 && other.ID == product.ID) ||
+			(product.ExternalID != "" && other.SellerID == product.SellerID && other.ExternalID == product.ExternalID) {
+			return storage.ErrConflict
+		}
+	}
+
+	if product.ID == 0 {
+		t.nextProductID++
+		product.ID = t.nextProductID
+	} else if product.ID > t.nextProductID {
+		t.nextProductID = product.ID
+	}
+
+	t.products[product.UUID] = product
+
+	return nil
+}
+
+// DeleteProduct deletes the product.
+//
+// Returns storage.ErrNotFound, when the product does not exist.
+func (t *Tables) DeleteProduct(uuid string) error {
+	if _, ok := t.products[uuid]; !ok {
+		return storage.ErrNotFound
+	}
+
+	delete(t.products, uuid)
+
+	return nil
+}
+
+// clone returns a deep copy of the tables.
+func (t *Tables) clone() *Tables {
+	c := &Tables{
+		sellers:       make(map[string]*Seller, len(t.sellers)),
+		products:      make(map[string]*Product, len(t.products)),
+		nextSellerID:  t.nextSellerID,
+		nextProductID: t.nextProductID,
+	}
+
+	for uuid, seller := range t.sellers {
+		copied := *seller
+		c.sellers[uuid] = &copied
+	}
+
+	for uuid, product := range t.products {
+		copied := *product
+		c.products[uuid] = &copied
+	}
+
+	return c
+}
+
+// DB is the in-memory DB, it is safe for concurrent use.
+type DB struct {
+	mu     *sync.RWMutex
+	tables *Tables
+	// tx reports whether the DB is the view of a transaction, which holds the lock of the DB.
+	tx bool
+}
+
+// New builds an empty DB.
+func New() *DB {
+	return &DB{
+		mu:     &sync.RWMutex{},
+		tables: &Tables{sellers: map[string]*Seller{}, products: map[string]*Product{}},
+	}
+}
+
+// Read runs fn reading the tables, concurrently with the other reads.
+func (db *DB) Read(ctx context.Context, fn func(t *Tables) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if !db.tx {
+		db.mu.RLock()
+		defer db.mu.RUnlock()
+	}
+
+	return fn(db.tables)
+}
+
+// Write runs fn writing the tables, exclusively. fn must check the constraints before it modifies
+// the rows, the modifications are not rolled back when it fails.
+func (db *DB) Write(ctx context.Context, fn func(t *Tables) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if !db.tx {
+		db.mu.Lock()
+		defer db.mu.Unlock()
+	}
+
+	return fn(db.tables)
+}
+
+// Transact runs fn with the DB bound to a transaction, whose writes are committed when fn returns nil
+// and rolled back otherwise. The transactions are serialized, they are isolated from each other and
+// from the reads and the writes out of them.
+func (db *DB) Transact(ctx context.Context, fn func(tx *DB) error) error {
+	if db.tx {
+		return errors.New("memory: the DB is bound to a transaction already")
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	tx := &DB{tables: db.tables.clone(), tx: true}
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	db.tables = tx.tables
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"coding-challenge-go/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func insertSeller(t *testing.T, db *DB, uuid string) *Seller {
+	seller := &Seller{UUID: uuid, Name: "james"}
+
+	assert.NoError(t, db.Write(context.Background(), func(tables *Tables) error {
+		return tables.InsertSeller(seller)
+	}))
+
+	return seller
+}
+
+func TestTables_constraints(t *testing.T) {
+	db := New()
+	seller := insertSeller(t, db, "s1")
+
+	assert.NoError(t, db.Write(context.Background(), func(tables *Tables) error {
+		assert.ErrorIs(t, tables.InsertSeller(&Seller{UUID: "s1"}), storage.ErrDuplicateUUID)
+
+		assert.NoError(t, tables.InsertProduct(&Product{UUID: "p1", SellerID: seller.ID, ExternalID: "sku-1"}))
+		assert.ErrorIs(t, tables.InsertProduct(&Product{UUID: "p2", SellerID: 42}), storage.ErrForeignKey)
+		assert.ErrorIs(t, tables.InsertProduct(&Product{UUID: "p1", SellerID: seller.ID}), storage.ErrDuplicateUUID)
+		assert.ErrorIs(t, tables.InsertProduct(&Product{UUID: "p2", SellerID: seller.ID, ExternalID: "sku-1"}), storage.ErrConflict)
+		assert.ErrorIs(t, tables.InsertProduct(&Product{ID: 1, UUID: "p2", SellerID: seller.ID}), storage.ErrConflict)
+
+		// the IDs are generated after the inserted ones.
+		product := &Product{ID: 10, UUID: "p10", SellerID: seller.ID}
+		assert.NoError(t, tables.InsertProduct(product))
+
+		product = &Product{UUID: "p11", SellerID: seller.ID}
+		assert.NoError(t, tables.InsertProduct(product))
+		assert.Equal(t, 11, product.ID)
+
+		assert.ErrorIs(t, tables.DeleteSeller("s1"), storage.ErrConflict)
+		assert.ErrorIs(t, tables.DeleteSeller("s2"), storage.ErrNotFound)
+		assert.ErrorIs(t, tables.DeleteProduct("p2"), storage.ErrNotFound)
+
+		return nil
+	}))
+}
+
+func TestDB_Transact(t *testing.T) {
+	ctx := context.Background()
+	errRollback := errors.New("rollback")
+
+	db := New()
+	seller := insertSeller(t, db, "s1")
+
+	err := db.Transact(ctx, func(tx *DB) error {
+		return tx.Write(ctx, func(tables *Tables) error {
+			tables.SellerByUUID("s1").Name = "mark"
+			return tables.InsertProduct(&Product{UUID: "p1", SellerID: seller.ID})
+		})
+	})
+	assert.NoError(t, err)
+
+	err = db.Transact(ctx, func(tx *DB) error {
+		assert.NoError(t, tx.Write(ctx, func(tables *Tables) error {
+			tables.SellerByUUID("s1").Name = "anna"
+			return tables.DeleteProduct("p1")
+		}))
+
+		assert.Error(t, tx.Transact(ctx, func(*DB) error { return nil }))
+
+		return errRollback
+	})
+	assert.ErrorIs(t, err, errRollback)
+
+	assert.NoError(t, db.Read(ctx, func(tables *Tables) error {
+		assert.Equal(t, "mark", tables.SellerByUUID("s1").Name)
+		assert.NotNil(t, tables.ProductByUUID("p1"))
+
+		return nil
+	}))
+}
+
+func TestDB_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	db := New()
+	called := false
+
+	fn := func(*Tables) error {
+		called = true
+		return nil
+	}
+
+	assert.ErrorIs(t, db.Read(ctx, fn), context.Canceled)
+	assert.ErrorIs(t, db.Write(ctx, fn), context.Canceled)
+	assert.ErrorIs(t, db.Transact(ctx, func(*DB) error { return nil }), context.Canceled)
+	assert.False(t, called)
+}
+
+func TestSeed(t *testing.T) {
+	db := New()
+	assert.NoError(t, Seed(context.Background(), db))
+
+	assert.NoError(t, db.Read(context.Background(), func(tables *Tables) error {
+		assert.Len(t, tables.Sellers(), 14)
+		assert.Len(t, tables.Products(), 23)
+		assert.Equal(t, "Raja", tables.Products()[0].Name)
+		assert.Equal(t, "Christene Maggio", tables.SellerByID(tables.Products()[0].SellerID).Name)
+
+		return nil
+	}))
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Seed inserts the sample sellers and products of the seed of the MySQL schema, for the local
+// development only.
+func Seed(ctx context.Context, db *DB) error {
+	sellers := []Seller{
+		{Name: "Christene Maggio", Email: "christene.maggio@seller.com", Phone: "202-555-0143"},
+		{Name: "Owen Ringgold", Email: "owen.ringgold@seller.com", Phone: "202-555-0188"},
+		{Name: "Shani Marinello", Email: "shani.marinello@seller.com", Phone: "202-555-0138"},
+		{Name: "Lajuana Mooring", Email: "lajuana.mooring@seller.com", Phone: "202-555-0103"},
+		{Name: "Tatyana Moua", Email: "tatyana.moua@seller.com", Phone: "202-555-0178"},
+		{Name: "Chelsie Wurster", Email: "chelsie.wurster@seller.com", Phone: "202-555-0187"},
+		{Name: "Syble Coria", Email: "syble.coria@seller.com", Phone: "202-555-0132"},
+		{Name: "Cathleen Swick", Email: "cathleen.swick@seller.com", Phone: "202-555-0166"},
+		{Name: "Cathleen Wurster", Email: "cathleen.wurster@seller.com", Phone: "202-555-0166"},
+		{Name: "Tatyana Ringgold", Email: "tatyana.ringgold@seller.com", Phone: "202-555-0166"},
+		{Name: "Chelsie Maggio", Email: "chelsie.maggio@seller.com", Phone: "202-555-0166"},
+		{Name: "Lajuana Marinello", Email: "lajuana.marinellok@seller.com", Phone: "202-555-0166"},
+		{Name: "Owen Swick", Email: "owen.swick@seller.com", Phone: "202-555-0166"},
+		{Name: "Christene Ringgold", Email: "christene.ringgold@seller.com", Phone: "202-555-0166"},
+	}
+
+	// products are the name, the brand, the stock and the ID of the seller of the sample products.
+	products := []struct {
+		name, brand     string
+		stock, sellerID int
+	}{
+		{"Raja", "RJ", 100, 1},
+		{"Pure Linen Plain Shirt", "ShirtsCo", 44, 1},
+		{"Long Sleeve Nursing Tops", "ShirtsCo", 12, 1},
+		{"Christian Dior Pure Poison Eau De Parfum Spray", "Christian Dior", 1031, 2},
+		{"Speed TR Flexweave Shoes", "Flexweave", 1300, 2},
+		{"Berlin New Shirt", "ShirtsCo", 2100, 2},
+		{"2 Pack Bikini Brief", "ShirtsCo", 3200, 2},
+		{"Organic Moisturizing Lip Balm", "Organic", 10, 2},
+		{"Combi Leather Sandals", "ShoesCo", 1100, 3},
+		{"Sasha", "Sasha", 120, 3},
+		{"Womens Bella Surfing Maxi Tank Dress", "ShirtsCo", 2100, 4},
+		{"Plano Tee", "TeeCo", 10, 4},
+		{"Solid Cross Back Bikini Top", "ShirtsCo", 120, 3},
+		{"Ottana Sweater", "Ottana", 3200, 1},
+		{"Brushed Herringbone Pant With Tape Detail In Loose Tapered Fit", "Ottana", 100, 5},
+		{"Mina", "Mina", 321, 6},
+		{"Claire Top", "Claire", 1321, 7},
+		{"Calvin Klein Fully Delicious Sheer Plumping Lip Gloss", "Calvin Klein", 132, 8},
+		{"Shara Shara White Stem Sleeping Mask", "Shara Shara", 1021, 9},
+		{"Classy & Fabulous Coat", "ShirtsCo", 1332, 11},
+		{"Black Cherry Dress", "ShirtsCo", 11, 12},
+		{"Storm Jacket", "ShirtsCo", 145, 14},
+		{"Sweet Daisy Top", "ShirtsCo", 14400, 14},
+	}
+
+	return db.Write(ctx, func(t *Tables) error {
+		for i := range sellers {
+			seller := sellers[i]
+			seller.UUID = uuid.New().String()
+
+			if err := t.InsertSeller(&seller); err != nil {
+				return err
+			}
+		}
+
+		for _, p := range products {
+			product := &Product{UUID: uuid.New().String(), Name: p.name, Brand: p.brand, Stock: p.stock, SellerID: p.sellerID}
+
+			if err := t.InsertProduct(product); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// WithQueryTimeout returns a copy of ctx which is cancelled after the timeout,
+// so that a slow query does not block its caller forever.
+//
+// A timeout which is not positive leaves the deadline of ctx as it is.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// ExpectAffected returns ErrNotFound, when the write did not affect any row.
+//
+// NOTE - MySQL reports the changed rows instead of the matched ones by default, so the DSN
+// has to enable clientFoundRows, otherwise an UPDATE which does not change any value
+// would be reported as not found.
+func ExpectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"coding-challenge-go/pkg/tracing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/attribute"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// DBTX runs the queries of a repository, on the DB or on a transaction, so that the same
+// repository can be bound to either of them.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// mysqlErrDeadlock is the error of the transaction chosen as the victim of a deadlock, which is
+// rolled back by MySQL.
+const mysqlErrDeadlock = 1213
+
+// The errors of the PostgreSQL transactions which are rolled back and can be retried.
+const (
+	pgErrSerializationFailure = "40001"
+	pgErrDeadlockDetected     = "40P01"
+)
+
+// IsDeadlock reports whether the transaction failed on a deadlock, it can be retried then.
+//
+// NOTE - SQLite locks the whole DB, a transaction which can not take the lock before the busy timeout
+// fails as busy, which is its deadlock.
+func IsDeadlock(err error) bool {
+	var (
+		mysqlErr  *mysql.MySQLError
+		pgErr     *pgconn.PgError
+		sqliteErr *sqlite.Error
+	)
+
+	switch {
+	case errors.As(err, &mysqlErr):
+		return mysqlErr.Number == mysqlErrDeadlock
+	case errors.As(err, &pgErr):
+		return pgErr.Code == pgErrDeadlockDetected || pgErr.Code == pgErrSerializationFailure
+	case errors.As(err, &sqliteErr):
+		// the primary result code of the extended ones, like SQLITE_BUSY_SNAPSHOT.
+		code := sqliteErr.Code() & 0xff
+
+		return code == sqlite3.SQLITE_BUSY || code == sqlite3.SQLITE_LOCKED
+	}
+
+	return false
+}
+
+// txRetryBackoff is the base of the jittered backoff between the attempts of a transaction.
+const txRetryBackoff = 10 * time.Millisecond
+
+// TxManager runs units of work in transactions, a transaction failing on a deadlock is retried.
+type TxManager struct {
+	db      *sql.DB
+	dialect *Dialect
+	// maxRetries is the number of the retries after a deadlock, none when it is not positive.
+	maxRetries int
+	// backoff is the base of the backoff between the attempts, it is doubled on every retry.
+	backoff time.Duration
+}
+
+// NewTxManager builds the TxManager of the DB, retrying the deadlocked transactions at most
+// maxRetries times.
+func NewTxManager(db *sql.DB, maxRetries int) *TxManager {
+	return &TxManager{db: db, dialect: DialectOf(db), maxRetries: maxRetries, backoff: txRetryBackoff}
+}
+
+// Do runs fn in a transaction, which is committed when fn returns nil and rolled back otherwise.
+// The transaction is retried from the start when it deadlocks, so fn may run more than once and must
+// not keep the state of its previous attempts.
+func (m *TxManager) Do(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	ctx, span := tracing.Start(ctx, "storage.TxManager.Do", m.dialect.System)
+	defer func() { tracing.End(span, err) }()
+
+	for attempt := 0; ; attempt++ {
+		span.SetAttributes(attribute.Int("db.transaction.attempt", attempt+1))
+
+		err = m.attempt(ctx, fn)
+		if err == nil || !IsDeadlock(err) || attempt >= m.maxRetries {
+			return err
+		}
+
+		log.Ctx(ctx).Warn().Err(err).Int("attempt", attempt+1).Msg("Retry deadlocked transaction")
+
+		// NOTE - the backoff is jittered, so that the deadlocked transactions do not collide again.
+		backoff := m.backoff << attempt
+		if backoff > 0 {
+			backoff = backoff/2 + time.Duration(rand.Int63n(int64(backoff)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// attempt runs fn in a transaction once.
+func (m *TxManager) attempt(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			return fmt.Errorf("%w (rollback: %v)", err, rollbackErr)
+		}
+
+		return err
+	}
+
+	return FromDB(tx.Commit())
+}
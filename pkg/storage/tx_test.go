@@ -0,0 +1,171 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxManager_Do(t *testing.T) {
+	anyErr := errors.New("any error")
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+
+	tests := []struct {
+		name       string
+		maxRetries int
+		expect     func(m sqlmock.Sqlmock)
+		fnErrs     []error
+		wantErr    error
+		wantCalls  int
+	}{
+		{
+			name: "commits the writes",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			wantCalls: 1,
+		},
+		{
+			name: "rolls back the writes, when fn fails",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectRollback()
+			},
+			fnErrs:    []error{anyErr},
+			wantErr:   anyErr,
+			wantCalls: 1,
+		},
+		{
+			name: "returns the error of fn along with the error of the rollback",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectRollback().WillReturnError(errors.New("rollback error"))
+			},
+			fnErrs:    []error{anyErr},
+			wantErr:   anyErr,
+			wantCalls: 1,
+		},
+		{
+			name: "returns the error of the commit",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit().WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'x' for key 'uuid'"})
+			},
+			wantErr:   ErrDuplicateUUID,
+			wantCalls: 1,
+		},
+		{
+			name: "returns the error of the begin",
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin().WillReturnError(anyErr)
+			},
+			wantErr: anyErr,
+		},
+		{
+			name:       "retries the deadlocked transaction",
+			maxRetries: 2,
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectRollback()
+				m.ExpectBegin()
+				m.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			fnErrs:    []error{deadlock},
+			wantCalls: 2,
+		},
+		{
+			name:       "retries the transaction deadlocked on commit",
+			maxRetries: 1,
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit().WillReturnError(deadlock)
+				m.ExpectBegin()
+				m.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectCommit()
+			},
+			wantCalls: 2,
+		},
+		{
+			name:       "returns the deadlock, when the retries are exhausted",
+			maxRetries: 1,
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectRollback()
+				m.ExpectBegin()
+				m.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectRollback()
+			},
+			fnErrs:    []error{deadlock, deadlock},
+			wantErr:   deadlock,
+			wantCalls: 2,
+		},
+		{
+			name:       "does not retry other errors",
+			maxRetries: 3,
+			expect: func(m sqlmock.Sqlmock) {
+				m.ExpectBegin()
+				m.ExpectExec("DELETE").WillReturnResult(sqlmock.NewResult(0, 1))
+				m.ExpectRollback()
+			},
+			fnErrs:    []error{anyErr},
+			wantErr:   anyErr,
+			wantCalls: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, m, _ := sqlmock.New()
+			defer db.Close()
+
+			tt.expect(m)
+
+			manager := NewTxManager(db, tt.maxRetries)
+			manager.backoff = 0
+
+			calls := 0
+
+			err := manager.Do(context.Background(), func(tx *sql.Tx) error {
+				calls++
+
+				if _, err := tx.ExecContext(context.Background(), "DELETE FROM product WHERE uuid = ?", "e943dc0a"); err != nil {
+					return err
+				}
+
+				if calls <= len(tt.fnErrs) {
+					return tt.fnErrs[calls-1]
+				}
+
+				return nil
+			})
+
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+
+			assert.Equal(t, tt.wantCalls, calls)
+			assert.NoError(t, m.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestIsDeadlock(t *testing.T) {
+	assert.True(t, IsDeadlock(&mysql.MySQLError{Number: 1213}))
+	assert.False(t, IsDeadlock(&mysql.MySQLError{Number: 1205}))
+	assert.False(t, IsDeadlock(errors.New("any error")))
+}
@@ -0,0 +1,104 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"coding-challenge-go/pkg/config"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	// instrumentationName is the name of the tracer used by the whole service.
+	instrumentationName = "coding-challenge-go"
+
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+)
+
+// NewProvider builds the TracerProvider with the exporter selected in the config
+// and registers it, along with the W3C trace context propagator, globally.
+//
+// The caller is responsible to shut the provider down, so that the buffered spans are flushed.
+func NewProvider(ctx context.Context, cfg config.ENVConfig) (*sdktrace.TracerProvider, error) {
+	var opts []sdktrace.TracerProviderOption
+
+	switch cfg.TracingExporter {
+	case ExporterNone, "":
+	case ExporterOTLP:
+		var clientOpts []otlptracehttp.Option
+		if cfg.TracingOTLPEndpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpointURL(cfg.TracingOTLPEndpoint))
+		}
+
+		exporter, err := otlptracehttp.New(ctx, clientOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("tracing.NewProvider: failed to create OTLP exporter: %w", err)
+		}
+
+		opts = append(opts, sdktrace.WithBatcher(exporter))
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, fmt.Errorf("tracing.NewProvider: failed to create stdout exporter: %w", err)
+		}
+
+		opts = append(opts, sdktrace.WithSyncer(exporter))
+	default:
+		return nil, fmt.Errorf("tracing.NewProvider: unknown exporter %q", cfg.TracingExporter)
+	}
+
+	res, err := resource.Merge(
+		resource.Default(),
+		resource.NewSchemaless(semconv.ServiceName(cfg.TracingServiceName)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("tracing.NewProvider: failed to build resource: %w", err)
+	}
+
+	tp := sdktrace.NewTracerProvider(append(opts, sdktrace.WithResource(res))...)
+	Register(tp)
+
+	return tp, nil
+}
+
+// Register sets the provider and the W3C propagators as global ones.
+func Register(tp trace.TracerProvider) {
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+}
+
+// Tracer returns the tracer of the service from the global TracerProvider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
+
+// Start starts a span as a child of the span in ctx, if any.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records the error, if any, on the span and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
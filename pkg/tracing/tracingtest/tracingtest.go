@@ -0,0 +1,21 @@
+// Package tracingtest provides the TracerProvider of the tests asserting spans, so that the test
+// exporter is not built into the binaries.
+package tracingtest
+
+import (
+	"coding-challenge-go/pkg/tracing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// NewInMemoryProvider builds and registers globally a TracerProvider which keeps
+// the finished spans in memory, it is meant for tests which need to assert spans
+// without any collector.
+func NewInMemoryProvider() (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracing.Register(tp)
+
+	return tp, exporter
+}